@@ -1,32 +1,41 @@
 package api
 
 import (
-	"database/sql"
-	"fmt"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	db "github.com/novalyezu/simplebank-backend/db/sqlc"
 	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
 )
 
 type createAccountRequest struct {
-	Currency string `json:"currency" binding:"required,currency"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	AccountType string `json:"account_type" binding:"omitempty,account_type"`
 }
 
 func (server *Server) createAccount(c *gin.Context) {
 	var body createAccountRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(c, http.StatusBadRequest, err)
 		return
 	}
 
+	accountType := body.AccountType
+	if accountType == "" {
+		accountType = util.CheckingAccount
+	}
+
 	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.CreateAccountParams{
-		Owner:    authPayload.Username,
-		Balance:  0,
-		Currency: body.Currency,
+		Owner:       authPayload.Username,
+		Balance:     0,
+		Currency:    body.Currency,
+		AccountType: accountType,
 	}
 
 	account, err := server.store.CreateAccount(c, arg)
@@ -34,14 +43,14 @@ func (server *Server) createAccount(c *gin.Context) {
 		if pgError, ok := err.(*pq.Error); ok {
 			switch pgError.Constraint {
 			case "accounts_owner_fkey":
-				c.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("cannot create account with owner %s doesn't exists", authPayload.Username)))
+				writeError(c, http.StatusForbidden, newAPIError(http.StatusNotFound, CodeUserNotFound, "cannot create account with owner %s doesn't exists", authPayload.Username))
 				return
-			case "owner_currency_key":
-				c.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("cannot create account with same currency")))
+			case "owner_currency_type_key":
+				writeError(c, http.StatusForbidden, newAPIError(http.StatusConflict, CodeAlreadyExists, "cannot create account with same currency and type"))
 				return
 			}
 		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -49,33 +58,102 @@ func (server *Server) createAccount(c *gin.Context) {
 }
 
 type getAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
+	// ID is the internal id or the account number
+	ID string `uri:"id" binding:"required"`
 }
 
 func (server *Server) getAccount(c *gin.Context) {
 	var uri getAccountRequest
 	if err := c.ShouldBindUri(&uri); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	account, err := server.store.GetAccount(c, uri.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, errorResponse(err))
+	var account db.Account
+	if util.IsValidAccountNumber(uri.ID) {
+		var ok bool
+		account, ok = server.getAccountByNumber(c, uri.ID)
+		if !ok || !server.authorizeAccount(c, account, readRoles) {
+			return
+		}
+	} else {
+		accountID, err := strconv.ParseInt(uri.ID, 10, 64)
+		if err != nil || accountID < 1 {
+			writeError(c, http.StatusBadRequest, errors.New("id must be an account id or account number"))
+			return
+		}
+
+		var ok bool
+		account, ok = server.getMemberAccount(c, accountID, readRoles)
+		if !ok {
 			return
 		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
 	}
 
-	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
-	if authPayload.Username != account.Owner {
-		c.JSON(http.StatusNotFound, errorResponse(err))
+	pockets, err := server.store.ListPockets(c, account.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, account)
+	c.JSON(http.StatusOK, toAccountResponse(account, pockets))
+}
+
+// accountResponse is addressed by the account number and leaves the internal ids
+// out. The consolidated balance is what the owner has in the account and all of its
+// pockets together.
+type accountResponse struct {
+	AccountNumber       string           `json:"account_number"`
+	Owner               string           `json:"owner"`
+	Balance             int64            `json:"balance"`
+	AvailableBalance    int64            `json:"available_balance"`
+	Currency            string           `json:"currency"`
+	AccountType         string           `json:"account_type"`
+	OverdraftLimit      int64            `json:"overdraft_limit"`
+	OverdraftRateBps    int64            `json:"overdraft_rate_bps"`
+	OrganizationID      *int64           `json:"organization_id"`
+	CreatedAt           time.Time        `json:"created_at"`
+	Pockets             []pocketResponse `json:"pockets"`
+	PocketsBalance      int64            `json:"pockets_balance"`
+	ConsolidatedBalance int64            `json:"consolidated_balance"`
+}
+
+// pocketResponse is a pocket inside accountResponse, the account it belongs to is the enclosing one
+type pocketResponse struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Balance    int64     `json:"balance"`
+	GoalAmount *int64    `json:"goal_amount"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func toAccountResponse(account db.Account, pockets []db.Pocket) accountResponse {
+	resp := accountResponse{
+		AccountNumber:       account.AccountNumber,
+		Owner:               account.Owner,
+		Balance:             account.Balance,
+		AvailableBalance:    account.AvailableBalance,
+		Currency:            account.Currency,
+		AccountType:         account.AccountType,
+		OverdraftLimit:      account.OverdraftLimit,
+		OverdraftRateBps:    account.OverdraftRateBps,
+		OrganizationID:      account.OrganizationID,
+		CreatedAt:           account.CreatedAt,
+		Pockets:             make([]pocketResponse, 0, len(pockets)),
+		ConsolidatedBalance: account.Balance,
+	}
+	for _, pocket := range pockets {
+		resp.Pockets = append(resp.Pockets, pocketResponse{
+			ID:         pocket.ID,
+			Name:       pocket.Name,
+			Balance:    pocket.Balance,
+			GoalAmount: pocket.GoalAmount,
+			CreatedAt:  pocket.CreatedAt,
+		})
+		resp.PocketsBalance += pocket.Balance
+	}
+	resp.ConsolidatedBalance += resp.PocketsBalance
+	return resp
 }
 
 type listAccountRequest struct {
@@ -86,7 +164,7 @@ type listAccountRequest struct {
 func (server *Server) listAccount(c *gin.Context) {
 	var query listAccountRequest
 	if err := c.ShouldBindQuery(&query); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -99,7 +177,7 @@ func (server *Server) listAccount(c *gin.Context) {
 
 	accounts, err := server.store.ListAccounts(c, arg)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+)
+
+var ErrAccountEventsUnavailable = errors.New("account events are not available")
+
+// accountEventsMaxAccounts is how many accounts a single stream may follow
+const accountEventsMaxAccounts = 20
+
+// accountEventsHeartbeat keeps idle streams from being closed by proxies
+const accountEventsHeartbeat = 30 * time.Second
+
+// AccountEventSubscriber streams the events of accounts. The channel is closed when the
+// subscriber may have missed events, the client reconnects and loads the accounts again then.
+type AccountEventSubscriber interface {
+	Subscribe(accountIDs []int64) (<-chan db.AccountEvent, func())
+}
+
+type streamAccountEventsRequest struct {
+	AccountIDs []int64 `form:"account_id" binding:"omitempty,max=20,dive,min=1"`
+}
+
+// streamAccountEvents streams new entries and balance changes of the accounts as server-sent
+// events, named after the type of the event. Without account_id it follows the personal
+// accounts of the caller, organization accounts have to be asked for by id. Accounts shared
+// with the caller after the stream started are only followed once it reconnects.
+func (server *Server) streamAccountEvents(c *gin.Context) {
+	var query streamAccountEventsRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if server.accountEvents == nil {
+		writeError(c, http.StatusServiceUnavailable, ErrAccountEventsUnavailable)
+		return
+	}
+
+	accountIDs := make([]int64, 0, accountEventsMaxAccounts)
+	if len(query.AccountIDs) == 0 {
+		authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+		accounts, err := server.store.ListAccounts(c, db.ListAccountsParams{
+			Owner: authPayload.Username,
+			Limit: accountEventsMaxAccounts,
+		})
+		if err != nil {
+			writeError(c, http.StatusInternalServerError, err)
+			return
+		}
+		for _, account := range accounts {
+			accountIDs = append(accountIDs, account.ID)
+		}
+	} else {
+		for _, id := range query.AccountIDs {
+			account, ok := server.getMemberAccount(c, id, readRoles)
+			if !ok {
+				return
+			}
+			accountIDs = append(accountIDs, account.ID)
+		}
+	}
+
+	events, unsubscribe := server.accountEvents.Subscribe(accountIDs)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(accountEventsHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("ready", gin.H{"account_ids": accountIDs})
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(event.Type, event)
+		case <-heartbeat.C:
+			c.Writer.WriteString(": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeAccountEvents hands out the events and closes the channel, which ends the stream
+type fakeAccountEvents struct {
+	events       []db.AccountEvent
+	accountIDs   []int64
+	unsubscribed bool
+}
+
+func (fake *fakeAccountEvents) Subscribe(accountIDs []int64) (<-chan db.AccountEvent, func()) {
+	fake.accountIDs = accountIDs
+
+	events := make(chan db.AccountEvent, len(fake.events))
+	for _, event := range fake.events {
+		events <- event
+	}
+	close(events)
+
+	return events, func() { fake.unsubscribed = true }
+}
+
+func TestStreamAccountEventsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	otherAccount := randomAccount(util.RandomString(6))
+	otherAccount.ID = account.ID + 1
+
+	event := db.AccountEvent{
+		Type:      db.AccountEventBalance,
+		AccountID: account.ID,
+		Balance: &db.AccountBalance{
+			Balance:          account.Balance,
+			AvailableBalance: account.AvailableBalance,
+			Currency:         account.Currency,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents)
+	}{
+		{
+			name:  "OwnAccounts",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(db.ListAccountsParams{
+						Owner: user.Username,
+						Limit: accountEventsMaxAccounts,
+					})).
+					Times(1).
+					Return([]db.Account{account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+				assert.Equal(t, []int64{account.ID}, events.accountIDs)
+				assert.True(t, events.unsubscribed)
+
+				body := recorder.Body.String()
+				assert.Contains(t, body, "event:ready\n")
+				assert.Contains(t, body, "event:balance\n")
+				assert.Contains(t, body, fmt.Sprintf(`"account_id":%d`, account.ID))
+				assert.Contains(t, body, fmt.Sprintf(`"available_balance":%d`, account.AvailableBalance))
+			},
+		},
+		{
+			name:  "ChosenAccount",
+			query: fmt.Sprintf("?account_id=%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, []int64{account.ID}, events.accountIDs)
+			},
+		},
+		{
+			name:  "NotMember",
+			query: fmt.Sprintf("?account_id=%d&account_id=%d", account.ID, otherAccount.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+				assert.Nil(t, events.accountIDs)
+			},
+		},
+		{
+			name:  "InvalidAccountID",
+			query: "?account_id=0",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			events := &fakeAccountEvents{events: []db.AccountEvent{event}}
+			server := newServerTest(t, store)
+			server.accountEvents = events
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts/events"+tc.query, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder, events)
+		})
+	}
+}
+
+func TestStreamAccountEventsUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	server := newServerTest(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/accounts/events", nil)
+	assert.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, util.RandomString(6), util.DepositorRole)
+	server.router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+// the roles allowed to see an account, to move money out of it, to approve pending transfers
+// and to manage its members. Personal accounts use the account roles, organization accounts
+// the role of the member in the organization.
+var (
+	readRoles = []string{
+		util.AccountOwnerRole, util.AccountCoOwnerRole, util.AccountViewerRole,
+		util.OrganizationAdminRole, util.OrganizationInitiatorRole, util.OrganizationApproverRole,
+	}
+	transactRoles = []string{
+		util.AccountOwnerRole, util.AccountCoOwnerRole,
+		util.OrganizationAdminRole, util.OrganizationInitiatorRole,
+	}
+	approveRoles = []string{
+		util.AccountOwnerRole, util.AccountCoOwnerRole,
+		util.OrganizationAdminRole, util.OrganizationApproverRole,
+	}
+	manageRoles = []string{util.AccountOwnerRole}
+)
+
+type accountMemberResponse struct {
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	InvitedBy string    `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toAccountMemberResponse(member db.AccountMember) accountMemberResponse {
+	return accountMemberResponse{
+		Username:  member.Username,
+		Role:      member.Role,
+		InvitedBy: member.InvitedBy,
+		CreatedAt: member.CreatedAt,
+	}
+}
+
+type accountURIRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) listAccountMembers(c *gin.Context) {
+	var uri accountURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getMemberAccount(c, uri.ID, readRoles)
+	if !ok {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(c, account.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the account owner isn't stored as a member
+	resp := []accountMemberResponse{{
+		Username:  account.Owner,
+		Role:      util.AccountOwnerRole,
+		CreatedAt: account.CreatedAt,
+	}}
+	for _, member := range members {
+		resp = append(resp, toAccountMemberResponse(member))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+type inviteAccountMemberRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Role     string `json:"role" binding:"required,account_role"`
+}
+
+func (server *Server) inviteAccountMember(c *gin.Context) {
+	var uri accountURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body inviteAccountMemberRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getMemberAccount(c, uri.ID, manageRoles)
+	if !ok {
+		return
+	}
+	if body.Username == account.Owner {
+		writeError(c, http.StatusBadRequest, errors.New("the account owner is already a member"))
+		return
+	}
+
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.AddAccountMember(c, db.AddAccountMemberParams{
+		AccountID: account.ID,
+		Username:  body.Username,
+		Role:      body.Role,
+		InvitedBy: authPayload.Username,
+	})
+	if err != nil {
+		if pgError, ok := err.(*pq.Error); ok {
+			switch pgError.Constraint {
+			case "account_members_username_fkey":
+				writeError(c, http.StatusForbidden, newAPIError(http.StatusNotFound, CodeUserNotFound, "cannot invite user %s doesn't exists", body.Username))
+				return
+			}
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toAccountMemberResponse(member))
+}
+
+type removeAccountMemberURIRequest struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required"`
+}
+
+// removeAccountMember lets owners remove anyone but the account owner, every member may leave
+func (server *Server) removeAccountMember(c *gin.Context) {
+	var uri removeAccountMemberURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	roles := manageRoles
+	if uri.Username == authPayload.Username {
+		roles = readRoles
+	}
+
+	account, ok := server.getMemberAccount(c, uri.ID, roles)
+	if !ok {
+		return
+	}
+	if uri.Username == account.Owner {
+		writeError(c, http.StatusBadRequest, errors.New("cannot remove the account owner"))
+		return
+	}
+
+	member, err := server.store.RemoveAccountMember(c, db.RemoveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  uri.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toAccountMemberResponse(member))
+}
+
+// accountRole returns the role of the token holder on the account, empty when they aren't a member.
+// Organization accounts are only reachable with a token acting for the organization.
+func (server *Server) accountRole(ctx context.Context, account db.Account, authPayload *token.Payload) (string, error) {
+	if account.OrganizationID != nil {
+		if *account.OrganizationID != authPayload.OrganizationID {
+			return "", nil
+		}
+		return server.organizationRole(ctx, *account.OrganizationID, authPayload.Username)
+	}
+
+	if account.Owner == authPayload.Username {
+		return util.AccountOwnerRole, nil
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// getMemberAccount loads the account and makes sure the caller has one of the roles on it,
+// the error response is already written when it returns false
+func (server *Server) getMemberAccount(c *gin.Context, accountID int64, roles []string) (db.Account, bool) {
+	account, err := server.store.GetAccount(c, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return db.Account{}, false
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return db.Account{}, false
+	}
+
+	if !server.authorizeAccount(c, account, roles) {
+		return db.Account{}, false
+	}
+	return account, true
+}
+
+// authorizeAccount makes sure the caller has one of the roles on the account.
+// Accounts the caller isn't a member of look missing, members without the role are denied.
+// The error response is already written when it returns false.
+func (server *Server) authorizeAccount(c *gin.Context, account db.Account, roles []string) bool {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	role, err := server.accountRole(c, account, authPayload)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return false
+	}
+	if role == "" {
+		writeError(c, http.StatusNotFound, sql.ErrNoRows)
+		return false
+	}
+	if !slices.Contains(roles, role) {
+		writeError(c, http.StatusForbidden, ErrPermissionDenied)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lib/pq"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func randomAccountMember(account db.Account, role string) db.AccountMember {
+	return db.AccountMember{
+		AccountID: account.ID,
+		Username:  util.RandomString(6),
+		Role:      role,
+		InvitedBy: account.Owner,
+	}
+}
+
+func TestListAccountMembers(t *testing.T) {
+	owner, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	viewer := randomAccountMember(account, util.AccountViewerRole)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: viewer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{
+						AccountID: account.ID,
+						Username:  viewer.Username,
+					})).
+					Times(1).
+					Return(viewer, nil)
+				store.EXPECT().
+					ListAccountMembers(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return([]db.AccountMember{viewer}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp []accountMemberResponse
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, []accountMemberResponse{
+					{Username: owner.Username, Role: util.AccountOwnerRole},
+					toAccountMemberResponse(viewer),
+				}, resp)
+			},
+		},
+		{
+			name:     "NotMember",
+			username: "stranger",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListAccountMembers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/members", account.ID), nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestInviteAccountMember(t *testing.T) {
+	owner, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	coOwner := randomAccountMember(account, util.AccountCoOwnerRole)
+	invitee, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          inviteAccountMemberRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: owner.Username,
+			body:     inviteAccountMemberRequest{Username: invitee.Username, Role: util.AccountCoOwnerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					AddAccountMember(gomock.Any(), gomock.Eq(db.AddAccountMemberParams{
+						AccountID: account.ID,
+						Username:  invitee.Username,
+						Role:      util.AccountCoOwnerRole,
+						InvitedBy: owner.Username,
+					})).
+					Times(1).
+					Return(db.AccountMember{
+						AccountID: account.ID,
+						Username:  invitee.Username,
+						Role:      util.AccountCoOwnerRole,
+						InvitedBy: owner.Username,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp accountMemberResponse
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, invitee.Username, resp.Username)
+				assert.Equal(t, util.AccountCoOwnerRole, resp.Role)
+				assert.Equal(t, owner.Username, resp.InvitedBy)
+			},
+		},
+		{
+			name:     "CoOwnerCannotInvite",
+			username: coOwner.Username,
+			body:     inviteAccountMemberRequest{Username: invitee.Username, Role: util.AccountViewerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(coOwner, nil)
+				store.EXPECT().AddAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InviteAccountOwner",
+			username: owner.Username,
+			body:     inviteAccountMemberRequest{Username: owner.Username, Role: util.AccountViewerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AddAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotExists",
+			username: owner.Username,
+			body:     inviteAccountMemberRequest{Username: "ghost", Role: util.AccountViewerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					AddAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, &pq.Error{Constraint: "account_members_username_fkey"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidRole",
+			username: owner.Username,
+			body:     inviteAccountMemberRequest{Username: invitee.Username, Role: "admin"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/accounts/%d/members", account.ID), bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRemoveAccountMember(t *testing.T) {
+	owner, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	viewer := randomAccountMember(account, util.AccountViewerRole)
+	coOwner := randomAccountMember(account, util.AccountCoOwnerRole)
+
+	testCases := []struct {
+		name          string
+		username      string
+		member        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OwnerRemovesMember",
+			username: owner.Username,
+			member:   coOwner.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					RemoveAccountMember(gomock.Any(), gomock.Eq(db.RemoveAccountMemberParams{
+						AccountID: account.ID,
+						Username:  coOwner.Username,
+					})).
+					Times(1).
+					Return(coOwner, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "MemberLeaves",
+			username: viewer.Username,
+			member:   viewer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(viewer, nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(viewer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ViewerRemovesOther",
+			username: viewer.Username,
+			member:   coOwner.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(viewer, nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "RemoveAccountOwner",
+			username: owner.Username,
+			member:   owner.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: owner.Username,
+			member:   "nobody",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/members/%s", account.ID, tc.member)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
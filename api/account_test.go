@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,12 +20,20 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// lastAccountID makes the IDs of random accounts unique, two accounts of a test never
+// share an ID and no account has the ID 0, which fails the min=1 of the requests
+var lastAccountID atomic.Int64
+
 func randomAccount(owner string) db.Account {
+	balance := util.RandomInt(0, 100)
 	return db.Account{
-		ID:       util.RandomInt(0, 100),
-		Owner:    owner,
-		Balance:  util.RandomInt(0, 100),
-		Currency: util.RandomCurrency(),
+		ID:               lastAccountID.Add(util.RandomInt(1, 100)),
+		Owner:            owner,
+		Balance:          balance,
+		AvailableBalance: balance,
+		Currency:         util.RandomCurrency(),
+		AccountType:      util.CheckingAccount,
+		AccountNumber:    util.RandomAccountNumber(),
 	}
 }
 
@@ -42,6 +51,10 @@ func requiredAccountMatchBody(t *testing.T, body *bytes.Buffer, account db.Accou
 func TestGetAccountAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	account := randomAccount(user.Username)
+	pockets := []db.Pocket{
+		{ID: util.RandomInt(1, 100), AccountID: account.ID, Name: "holiday", Balance: 100},
+		{ID: util.RandomInt(101, 200), AccountID: account.ID, Name: "car", Balance: 200},
+	}
 
 	testCases := []struct {
 		name          string
@@ -54,7 +67,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "OK",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -64,17 +77,42 @@ func TestGetAccountAPI(t *testing.T) {
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
 					Return(account, nil)
+
+				store.
+					EXPECT().
+					ListPockets(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(pockets, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
-				requiredAccountMatchBody(t, recorder.Body, account)
+
+				var resp accountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, toAccountResponse(account, pockets), resp)
+				assert.Equal(t, account.AccountNumber, resp.AccountNumber)
+				assert.Len(t, resp.Pockets, 2)
+				assert.Equal(t, int64(300), resp.PocketsBalance)
+				assert.Equal(t, account.Balance+300, resp.ConsolidatedBalance)
+
+				var raw struct {
+					ID      *int64           `json:"id"`
+					Pockets []map[string]any `json:"pockets"`
+				}
+				err = json.Unmarshal(recorder.Body.Bytes(), &raw)
+				assert.NoError(t, err)
+				assert.Nil(t, raw.ID)
+				for _, pocket := range raw.Pockets {
+					assert.NotContains(t, pocket, "account_id")
+				}
 			},
 		},
 		{
 			name:      "NotFound",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -93,7 +131,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "InvalidID",
 			accountID: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -111,7 +149,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "InternalServerError",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -153,6 +191,94 @@ func TestGetAccountAPI(t *testing.T) {
 	}
 }
 
+func TestGetAccountByNumberAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		id            string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			id:       account.AccountNumber,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListPockets(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return([]db.Pocket{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp accountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, toAccountResponse(account, []db.Pocket{}), resp)
+			},
+		},
+		{
+			name:     "NotFound",
+			id:       account.AccountNumber,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "NotMember",
+			id:       account.AccountNumber,
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListPockets(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "WrongCheckDigits",
+			id:       "SB00123456789012",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts/"+tc.id, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestListAccount(t *testing.T) {
 	var accounts []db.Account
 	user, _ := randomUser(t)
@@ -173,7 +299,7 @@ func TestListAccount(t *testing.T) {
 			name:        "OK",
 			queryParams: listAccountRequest{Page: 1, Limit: 5},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -204,7 +330,7 @@ func TestListAccount(t *testing.T) {
 			name:        "BadRequest",
 			queryParams: listAccountRequest{Page: 0, Limit: 0},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -223,7 +349,7 @@ func TestListAccount(t *testing.T) {
 			name:        "InternalServerError",
 			queryParams: listAccountRequest{Page: 1, Limit: 5},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -282,7 +408,7 @@ func TestCreateAccount(t *testing.T) {
 			name: "OK",
 			body: createAccountRequest{Currency: account.Currency},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -290,9 +416,10 @@ func TestCreateAccount(t *testing.T) {
 				store.
 					EXPECT().
 					CreateAccount(gomock.Any(), db.CreateAccountParams{
-						Owner:    account.Owner,
-						Currency: account.Currency,
-						Balance:  0,
+						Owner:       account.Owner,
+						Currency:    account.Currency,
+						Balance:     0,
+						AccountType: util.CheckingAccount,
 					}).
 					Times(1).
 					Return(account, nil)
@@ -302,11 +429,56 @@ func TestCreateAccount(t *testing.T) {
 				requiredAccountMatchBody(t, recorder.Body, account)
 			},
 		},
+		{
+			name: "SavingsAccount",
+			body: createAccountRequest{Currency: account.Currency, AccountType: util.SavingsAccount},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
+				assert.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				savings := account
+				savings.AccountType = util.SavingsAccount
+
+				store.
+					EXPECT().
+					CreateAccount(gomock.Any(), db.CreateAccountParams{
+						Owner:       account.Owner,
+						Currency:    account.Currency,
+						Balance:     0,
+						AccountType: util.SavingsAccount,
+					}).
+					Times(1).
+					Return(savings, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAccountType",
+			body: createAccountRequest{Currency: account.Currency, AccountType: "brokerage"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
+				assert.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "BadRequest",
 			body: createAccountRequest{Currency: ""},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -325,7 +497,7 @@ func TestCreateAccount(t *testing.T) {
 			name: "InternalServerError",
 			body: createAccountRequest{Currency: account.Currency},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				token, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				token, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -333,9 +505,10 @@ func TestCreateAccount(t *testing.T) {
 				store.
 					EXPECT().
 					CreateAccount(gomock.Any(), db.CreateAccountParams{
-						Owner:    account.Owner,
-						Currency: account.Currency,
-						Balance:  0,
+						Owner:       account.Owner,
+						Currency:    account.Currency,
+						Balance:     0,
+						AccountType: util.CheckingAccount,
 					}).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
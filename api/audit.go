@@ -0,0 +1,56 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+type listAuditEventsRequest struct {
+	Actor    string `form:"actor"`
+	Action   string `form:"action"`
+	Entity   string `form:"entity"`
+	EntityID string `form:"entity_id"`
+	Page     int32  `form:"page" binding:"required,min=1"`
+	Limit    int32  `form:"limit" binding:"required,min=1,max=100"`
+}
+
+// listAuditEvents returns the newest events first, the filters left out match everything
+func (server *Server) listAuditEvents(c *gin.Context) {
+	var query listAuditEventsRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	events, err := server.store.ListAuditEvents(c, db.ListAuditEventsParams{
+		Actor:    optionalString(query.Actor),
+		Action:   optionalString(query.Action),
+		Entity:   optionalString(query.Entity),
+		EntityID: optionalString(query.EntityID),
+		Limit:    query.Limit,
+		Offset:   (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+func (server *Server) verifyAuditChain(c *gin.Context) {
+	result, err := server.store.VerifyAuditChain(c)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func optionalString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
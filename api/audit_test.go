@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func randomAuditEvent(actor string) db.AuditEvent {
+	return db.AuditEvent{
+		ID:       util.RandomInt(1, 1000),
+		Actor:    actor,
+		Action:   db.AuditActionCreate,
+		Entity:   "account",
+		EntityID: "1",
+		Before:   json.RawMessage("null"),
+		After:    json.RawMessage(`{"id":1}`),
+		Hash:     util.RandomString(64),
+	}
+}
+
+func TestListAuditEventsAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+	user, _ := randomUser(t)
+	event := randomAuditEvent(user.Username)
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  util.BankerRole,
+			query: "page=2&limit=10&actor=" + user.Username + "&entity=account",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Eq(db.ListAuditEventsParams{
+						Actor:  sql.NullString{String: user.Username, Valid: true},
+						Entity: sql.NullString{String: "account", Valid: true},
+						Limit:  10,
+						Offset: 10,
+					})).
+					Times(1).
+					Return([]db.AuditEvent{event}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp []db.AuditEvent
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Len(t, resp, 1)
+				assert.Equal(t, event.ID, resp[0].ID)
+				assert.Equal(t, event.Hash, resp[0].Hash)
+				assert.JSONEq(t, string(event.After), string(resp[0].After))
+			},
+		},
+		{
+			name:  "Forbidden",
+			role:  util.DepositorRole,
+			query: "page=1&limit=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "BadRequest",
+			role:  util.BankerRole,
+			query: "page=1&limit=1000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			role:  util.BankerRole,
+			query: "page=1&limit=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.AuditEvent{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/banker/audit-events?"+tc.query, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, banker.Username, tc.role)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyAuditChainAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+	brokenAt := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Valid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyAuditChain(gomock.Any()).
+					Times(1).
+					Return(db.AuditChainResult{Valid: true, Events: 3, Heads: map[int32]string{0: "abc", 5: "def"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `{"valid":true,"events":3,"heads":{"0":"abc","5":"def"}}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "Broken",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyAuditChain(gomock.Any()).
+					Times(1).
+					Return(db.AuditChainResult{Valid: false, Events: 1, BrokenAt: &brokenAt, Heads: map[int32]string{0: "abc"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp db.AuditChainResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.False(t, resp.Valid)
+				assert.Equal(t, brokenAt, *resp.BrokenAt)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyAuditChain(gomock.Any()).
+					Times(1).
+					Return(db.AuditChainResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/banker/audit-events/verify", nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, banker.Username, util.BankerRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuditMiddlewareKeepsRequestID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	banker, _ := randomUser(t)
+	requestID := util.RandomString(20)
+
+	store.EXPECT().
+		VerifyAuditChain(gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context) (db.AuditChainResult, error) {
+			info := db.AuditInfoFrom(ctx)
+			assert.Equal(t, banker.Username, info.Actor)
+			assert.Equal(t, requestID, info.RequestID)
+			assert.NotEmpty(t, info.ClientIP)
+			return db.AuditChainResult{Valid: true}, nil
+		})
+
+	server := newServerTest(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/banker/audit-events/verify", nil)
+	assert.NoError(t, err)
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set(requestIDHeaderKey, requestID)
+
+	addAuthorization(t, request, server.tokenMaker, banker.Username, util.BankerRole)
+	server.router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, requestID, recorder.Header().Get(requestIDHeaderKey))
+}
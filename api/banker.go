@@ -0,0 +1,89 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+type listOverdraftsRequest struct {
+	Page  int32 `form:"page" binding:"required,min=1"`
+	Limit int32 `form:"limit" binding:"required,min=1,max=100"`
+}
+
+type overdraftResponse struct {
+	db.Account
+	// Overdrawn is the amount the balance is below zero
+	Overdrawn int64 `json:"overdrawn"`
+	// Headroom is how much more the account may still be debited
+	Headroom int64 `json:"headroom"`
+}
+
+func (server *Server) listOverdrafts(c *gin.Context) {
+	var query listOverdraftsRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	accounts, err := server.store.ListOverdrawnAccounts(c, db.ListOverdrawnAccountsParams{
+		Limit:  query.Limit,
+		Offset: (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]overdraftResponse, 0, len(accounts))
+	for _, account := range accounts {
+		resp = append(resp, overdraftResponse{
+			Account:   account,
+			Overdrawn: -account.Balance,
+			Headroom:  account.AvailableBalance + account.OverdraftLimit,
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+type updateOverdraftURIRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateOverdraftRequest struct {
+	Limit   *int64 `json:"limit" binding:"required,min=0"`
+	RateBps *int64 `json:"rate_bps" binding:"required,min=0,max=10000"`
+}
+
+func (server *Server) updateOverdraft(c *gin.Context) {
+	var uri updateOverdraftURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body updateOverdraftRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, err := server.store.UpdateAccountOverdraft(c, db.UpdateAccountOverdraftParams{
+		ID:               uri.ID,
+		OverdraftLimit:   *body.Limit,
+		OverdraftRateBps: *body.RateBps,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListOverdraftsAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+	banker.Role = util.BankerRole
+
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Balance = -150
+	account.AvailableBalance = -200
+	account.OverdraftLimit = 500
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  util.BankerRole,
+			query: "page=1&limit=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOverdrawnAccounts(gomock.Any(), gomock.Eq(db.ListOverdrawnAccountsParams{
+						Limit:  10,
+						Offset: 0,
+					})).
+					Times(1).
+					Return([]db.Account{account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp []overdraftResponse
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+
+				assert.Len(t, resp, 1)
+				assert.Equal(t, account, resp[0].Account)
+				assert.Equal(t, int64(150), resp[0].Overdrawn)
+				assert.Equal(t, int64(300), resp[0].Headroom)
+			},
+		},
+		{
+			name:  "Forbidden",
+			role:  util.DepositorRole,
+			query: "page=1&limit=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOverdrawnAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "BadRequest",
+			role:  util.BankerRole,
+			query: "page=0&limit=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOverdrawnAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/banker/overdrafts?"+tc.query, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, banker.Username, tc.role)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateOverdraftAPI(t *testing.T) {
+	banker, _ := randomUser(t)
+
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"limit": 1000, "rate_bps": 1800},
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := account
+				updated.OverdraftLimit = 1000
+				updated.OverdraftRateBps = 1800
+
+				store.EXPECT().
+					UpdateAccountOverdraft(gomock.Any(), gomock.Eq(db.UpdateAccountOverdraftParams{
+						ID:               account.ID,
+						OverdraftLimit:   1000,
+						OverdraftRateBps: 1800,
+					})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RemoveOverdraft",
+			body: gin.H{"limit": 0, "rate_bps": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountOverdraft(gomock.Any(), gomock.Eq(db.UpdateAccountOverdraftParams{
+						ID: account.ID,
+					})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest",
+			body: gin.H{"limit": -1, "rate_bps": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountOverdraft(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			body: gin.H{"limit": 1000, "rate_bps": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateAccountOverdraft(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/banker/accounts/%d/overdraft", account.ID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, banker.Username, util.BankerRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/novalyezu/simplebank-backend/token"
+)
+
+const (
+	graphQLMaxDepth = 8
+	// graphQLMaxParallelism lets the resolvers of a whole page wait on the same batch of a loader
+	graphQLMaxParallelism = 2 * graphQLMaxPageSize
+)
+
+// graphQLSchema is read only, the accounts follow the ownership rules of getAccount:
+// accounts the caller may not read resolve to null
+const graphQLSchema = `
+schema {
+  query: Query
+}
+
+scalar Time
+
+# Int64 holds amounts and balances, they don't fit in a GraphQL Int
+scalar Int64
+
+type Query {
+  # the caller
+  me: User!
+  account(accountNumber: String!): Account
+  # the personal accounts of the caller, owned or shared with them
+  accounts(first: Int = 20, after: String): AccountConnection!
+  transfer(id: ID!): Transfer
+}
+
+type User {
+  username: String!
+  fullName: String!
+  # only visible to the user themself
+  email: String
+  createdAt: Time!
+  # only visible to the user themself
+  accounts(first: Int = 20, after: String): AccountConnection
+}
+
+# accounts are addressed by their account number, the internal id is never sent
+type Account {
+  accountNumber: String!
+  owner: User!
+  organizationId: ID
+  balance: Int64!
+  availableBalance: Int64!
+  currency: String!
+  accountType: String!
+  createdAt: Time!
+  entries(first: Int = 20, after: String): EntryConnection!
+  transfers(first: Int = 20, after: String): TransferConnection!
+}
+
+type Entry {
+  id: ID!
+  account: Account
+  amount: Int64!
+  description: String!
+  transfer: Transfer
+  createdAt: Time!
+}
+
+type Transfer {
+  id: ID!
+  fromAccountNumber: String!
+  toAccountNumber: String!
+  # null when the caller may not read the account
+  fromAccount: Account
+  # null when the caller may not read the account
+  toAccount: Account
+  amount: Int64!
+  fee: Int64!
+  description: String!
+  reference: String!
+  # a JSON object
+  metadata: String!
+  status: String!
+  requiredApprovals: Int!
+  initiatedBy: String
+  decidedBy: String
+  decidedAt: Time
+  expiresAt: Time
+  createdAt: Time!
+}
+
+type PageInfo {
+  hasNextPage: Boolean!
+  endCursor: String
+}
+
+type AccountConnection {
+  edges: [AccountEdge!]!
+  pageInfo: PageInfo!
+}
+
+type AccountEdge {
+  cursor: String!
+  node: Account!
+}
+
+type EntryConnection {
+  edges: [EntryEdge!]!
+  pageInfo: PageInfo!
+}
+
+type EntryEdge {
+  cursor: String!
+  node: Entry!
+}
+
+type TransferConnection {
+  edges: [TransferEdge!]!
+  pageInfo: PageInfo!
+}
+
+type TransferEdge {
+  cursor: String!
+  node: Transfer!
+}
+`
+
+func newGraphQLSchema(server *Server) *graphql.Schema {
+	return graphql.MustParseSchema(
+		graphQLSchema,
+		&graphQLResolver{server: server},
+		graphql.MaxDepth(graphQLMaxDepth),
+		graphql.MaxParallelism(graphQLMaxParallelism),
+	)
+}
+
+type graphQLRequest struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage         `json:"data,omitempty"`
+	Errors []*gqlerrors.QueryError `json:"errors,omitempty"`
+}
+
+// serveGraphQL runs a query with loaders of its own, so the store is asked once per
+// kind of record no matter how many the response holds
+func (server *Server) serveGraphQL(c *gin.Context) {
+	var body graphQLRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	ctx := context.WithValue(c.Request.Context(), authPayloadContextKey{}, authPayload)
+	ctx = context.WithValue(ctx, graphQLLoadersContextKey{}, server.newGraphQLLoaders(authPayload))
+
+	resp := server.graphQL.Exec(ctx, body.Query, body.OperationName, body.Variables)
+	errs := make([]*gqlerrors.QueryError, 0, len(resp.Errors))
+	for _, queryErr := range resp.Errors {
+		errs = append(errs, graphQLError(c, queryErr))
+	}
+	c.JSON(http.StatusOK, graphQLResponse{
+		Data:   resp.Data,
+		Errors: errs,
+	})
+}
+
+// graphQLError maps the error of a resolver like writeError does for /v1, the code of
+// the problem goes to the extensions. An internal error is only sent as "internal error",
+// the error itself goes to the log of the request. The errors of the query itself are
+// sent as they are.
+func graphQLError(c *gin.Context, queryErr *gqlerrors.QueryError) *gqlerrors.QueryError {
+	if queryErr.ResolverError == nil {
+		return queryErr
+	}
+
+	p := resolveProblem(http.StatusInternalServerError, queryErr.ResolverError)
+	message := p.Detail
+	if p.Code == CodeInternal {
+		c.Error(queryErr.ResolverError)
+		message = "internal error"
+	}
+	return &gqlerrors.QueryError{
+		Message:    message,
+		Locations:  queryErr.Locations,
+		Path:       queryErr.Path,
+		Extensions: map[string]any{"code": p.Code},
+	}
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/graph-gophers/dataloader/v7"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+type graphQLLoadersContextKey struct{}
+
+// graphQLLoaders batch the lookups the resolvers of one query make and cache them for the query
+type graphQLLoaders struct {
+	authPayload *token.Payload
+
+	accounts  *dataloader.Loader[int64, db.Account]
+	users     *dataloader.Loader[string, db.User]
+	transfers *dataloader.Loader[int64, db.Transfer]
+	// memberRoles are the roles of the caller on shared personal accounts
+	memberRoles *dataloader.Loader[int64, string]
+	// organizationRoles are the roles of the caller in organizations
+	organizationRoles *dataloader.Loader[int64, string]
+	// entryPages and transferPages are the connections of the accounts
+	entryPages    *dataloader.Loader[accountPageKey, []db.Entry]
+	transferPages *dataloader.Loader[accountPageKey, []db.Transfer]
+}
+
+// accountPageKey is a page of a connection of an account, with the id to start
+// after and the limit of connectionArgs.page
+type accountPageKey struct {
+	accountID int64
+	afterID   int64
+	limit     int32
+}
+
+func graphQLLoadersFrom(ctx context.Context) *graphQLLoaders {
+	return ctx.Value(graphQLLoadersContextKey{}).(*graphQLLoaders)
+}
+
+func (server *Server) newGraphQLLoaders(authPayload *token.Payload) *graphQLLoaders {
+	return &graphQLLoaders{
+		authPayload: authPayload,
+		accounts: dataloader.NewBatchedLoader(loadByKeys(server.store.GetAccountsByIDs, func(account db.Account) int64 {
+			return account.ID
+		})),
+		users: dataloader.NewBatchedLoader(loadByKeys(server.store.GetUsersByUsernames, func(user db.User) string {
+			return user.Username
+		})),
+		transfers: dataloader.NewBatchedLoader(loadByKeys(server.store.GetTransfersByIDs, func(transfer db.Transfer) int64 {
+			return transfer.ID
+		})),
+		memberRoles: dataloader.NewBatchedLoader(func(ctx context.Context, accountIDs []int64) []*dataloader.Result[string] {
+			members, err := server.store.ListAccountMembershipsOfUser(ctx, db.ListAccountMembershipsOfUserParams{
+				Username:   authPayload.Username,
+				AccountIds: accountIDs,
+			})
+
+			roles := make(map[int64]string, len(members))
+			for _, member := range members {
+				roles[member.AccountID] = member.Role
+			}
+
+			// accounts without a membership have no role
+			results := make([]*dataloader.Result[string], len(accountIDs))
+			for i, accountID := range accountIDs {
+				results[i] = &dataloader.Result[string]{Data: roles[accountID], Error: err}
+			}
+			return results
+		}),
+		organizationRoles: dataloader.NewBatchedLoader(func(ctx context.Context, organizationIDs []int64) []*dataloader.Result[string] {
+			// a token acts for a single organization, so there is one key at most
+			results := make([]*dataloader.Result[string], len(organizationIDs))
+			for i, organizationID := range organizationIDs {
+				role, err := server.organizationRole(ctx, organizationID, authPayload.Username)
+				results[i] = &dataloader.Result[string]{Data: role, Error: err}
+			}
+			return results
+		}),
+		entryPages: dataloader.NewBatchedLoader(loadPages(func(ctx context.Context, accountIDs []int64, afterID int64, limit int32) (map[int64][]db.Entry, error) {
+			entries, err := server.store.ListEntriesAfterOfAccounts(ctx, db.ListEntriesAfterOfAccountsParams{
+				AccountIds: accountIDs,
+				AfterID:    afterID,
+				PageLimit:  limit,
+			})
+			pages := make(map[int64][]db.Entry)
+			for _, entry := range entries {
+				pages[entry.AccountID] = append(pages[entry.AccountID], entry)
+			}
+			return pages, err
+		})),
+		transferPages: dataloader.NewBatchedLoader(loadPages(func(ctx context.Context, accountIDs []int64, afterID int64, limit int32) (map[int64][]db.Transfer, error) {
+			rows, err := server.store.ListTransfersAfterOfAccounts(ctx, db.ListTransfersAfterOfAccountsParams{
+				AccountIds: accountIDs,
+				AfterID:    afterID,
+				PageLimit:  limit,
+			})
+			pages := make(map[int64][]db.Transfer)
+			for _, row := range rows {
+				pages[row.AccountID] = append(pages[row.AccountID], row.Transfer)
+			}
+			return pages, err
+		})),
+	}
+}
+
+// loadPages makes a batch function of a query returning the pages of several accounts
+// by account id. The accounts of a query share the page, so the keys are grouped by it,
+// the connections of a list of accounts usually take the same arguments and need one query.
+func loadPages[V any](query func(ctx context.Context, accountIDs []int64, afterID int64, limit int32) (map[int64][]V, error)) dataloader.BatchFunc[accountPageKey, []V] {
+	type page struct {
+		afterID int64
+		limit   int32
+	}
+
+	return func(ctx context.Context, keys []accountPageKey) []*dataloader.Result[[]V] {
+		accountIDs := make(map[page][]int64)
+		var order []page
+		for _, key := range keys {
+			p := page{afterID: key.afterID, limit: key.limit}
+			if _, ok := accountIDs[p]; !ok {
+				order = append(order, p)
+			}
+			accountIDs[p] = append(accountIDs[p], key.accountID)
+		}
+
+		results := make(map[accountPageKey]*dataloader.Result[[]V], len(keys))
+		for _, p := range order {
+			pages, err := query(ctx, accountIDs[p], p.afterID, p.limit)
+			for _, accountID := range accountIDs[p] {
+				// an account without rows has an empty page
+				rows := pages[accountID]
+				if rows == nil {
+					rows = []V{}
+				}
+				results[accountPageKey{accountID: accountID, afterID: p.afterID, limit: p.limit}] = &dataloader.Result[[]V]{Data: rows, Error: err}
+			}
+		}
+
+		ordered := make([]*dataloader.Result[[]V], len(keys))
+		for i, key := range keys {
+			ordered[i] = results[key]
+		}
+		return ordered
+	}
+}
+
+// loadByKeys makes a batch function of a query returning the rows of the keys,
+// keys without a row fail with sql.ErrNoRows
+func loadByKeys[K comparable, V any](query func(context.Context, []K) ([]V, error), key func(V) K) dataloader.BatchFunc[K, V] {
+	return func(ctx context.Context, keys []K) []*dataloader.Result[V] {
+		results := make([]*dataloader.Result[V], len(keys))
+
+		rows, err := query(ctx, keys)
+		if err != nil {
+			for i := range keys {
+				results[i] = &dataloader.Result[V]{Error: err}
+			}
+			return results
+		}
+
+		byKey := make(map[K]V, len(rows))
+		for _, row := range rows {
+			byKey[key(row)] = row
+		}
+		for i, k := range keys {
+			row, ok := byKey[k]
+			if !ok {
+				results[i] = &dataloader.Result[V]{Error: sql.ErrNoRows}
+				continue
+			}
+			results[i] = &dataloader.Result[V]{Data: row}
+		}
+		return results
+	}
+}
+
+// accountRole is accountRole of the server with the lookups batched
+func (loaders *graphQLLoaders) accountRole(ctx context.Context, account db.Account) (string, error) {
+	if account.OrganizationID != nil {
+		if *account.OrganizationID != loaders.authPayload.OrganizationID {
+			return "", nil
+		}
+		return loaders.organizationRoles.Load(ctx, *account.OrganizationID)()
+	}
+
+	if account.Owner == loaders.authPayload.Username {
+		return util.AccountOwnerRole, nil
+	}
+	return loaders.memberRoles.Load(ctx, account.ID)()
+}
+
+// readableAccount loads the account if the caller may read it like getAccount does,
+// it returns nil for accounts which don't exist or the caller may not read
+func (loaders *graphQLLoaders) readableAccount(ctx context.Context, account db.Account) (*db.Account, error) {
+	role, err := loaders.accountRole(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(readRoles, role) {
+		return nil, nil
+	}
+	return &account, nil
+}
+
+func (loaders *graphQLLoaders) loadReadableAccount(ctx context.Context, id int64) (*db.Account, error) {
+	account, err := loaders.accounts.Load(ctx, id)()
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return loaders.readableAccount(ctx, account)
+}
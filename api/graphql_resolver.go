@@ -0,0 +1,506 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+const graphQLMaxPageSize = 100
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidID     = errors.New("invalid id")
+)
+
+// int64Scalar is the Int64 scalar of the schema
+type int64Scalar int64
+
+func (int64Scalar) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+func (n *int64Scalar) UnmarshalGraphQL(input any) error {
+	switch input := input.(type) {
+	case int32:
+		*n = int64Scalar(input)
+	case string:
+		value, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return err
+		}
+		*n = int64Scalar(value)
+	default:
+		return fmt.Errorf("wrong type for Int64: %T", input)
+	}
+	return nil
+}
+
+func parseGraphQLID(id graphql.ID) (int64, error) {
+	value, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil || value < 1 {
+		return 0, ErrInvalidID
+	}
+	return value, nil
+}
+
+func graphQLID(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
+
+func optionalGraphQLID(id *int64) *graphql.ID {
+	if id == nil {
+		return nil
+	}
+	value := graphQLID(*id)
+	return &value
+}
+
+// connectionArgs paginate a connection by the id of its rows, the cursor of a row is its encoded id
+type connectionArgs struct {
+	First int32
+	After *string
+}
+
+// page returns the id to start after and the limit to query, one more than
+// the page size to know if there is a next page
+func (args connectionArgs) page() (int64, int32, error) {
+	if args.First < 1 || args.First > graphQLMaxPageSize {
+		return 0, 0, newAPIError(http.StatusBadRequest, CodeBadRequest, "first must be between 1 and %d", graphQLMaxPageSize)
+	}
+
+	var afterID int64
+	if args.After != nil {
+		data, err := base64.RawURLEncoding.DecodeString(*args.After)
+		if err != nil {
+			return 0, 0, ErrInvalidCursor
+		}
+		afterID, err = strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return 0, 0, ErrInvalidCursor
+		}
+	}
+	return afterID, args.First + 1, nil
+}
+
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (r *pageInfoResolver) HasNextPage() bool {
+	return r.hasNextPage
+}
+
+func (r *pageInfoResolver) EndCursor() *string {
+	return r.endCursor
+}
+
+type edgeResolver[N any] struct {
+	cursor string
+	node   N
+}
+
+func (r *edgeResolver[N]) Cursor() string {
+	return r.cursor
+}
+
+func (r *edgeResolver[N]) Node() N {
+	return r.node
+}
+
+type connectionResolver[N any] struct {
+	edges    []*edgeResolver[N]
+	pageInfo *pageInfoResolver
+}
+
+func (r *connectionResolver[N]) Edges() []*edgeResolver[N] {
+	return r.edges
+}
+
+func (r *connectionResolver[N]) PageInfo() *pageInfoResolver {
+	return r.pageInfo
+}
+
+// newConnection makes a page of the rows queried with the limit of connectionArgs.page
+func newConnection[T any, N any](rows []T, first int32, id func(T) int64, node func(T) N) *connectionResolver[N] {
+	connection := &connectionResolver[N]{
+		edges:    []*edgeResolver[N]{},
+		pageInfo: &pageInfoResolver{hasNextPage: len(rows) > int(first)},
+	}
+	if connection.pageInfo.hasNextPage {
+		rows = rows[:first]
+	}
+
+	for _, row := range rows {
+		connection.edges = append(connection.edges, &edgeResolver[N]{
+			cursor: encodeCursor(id(row)),
+			node:   node(row),
+		})
+	}
+	if len(connection.edges) > 0 {
+		endCursor := connection.edges[len(connection.edges)-1].cursor
+		connection.pageInfo.endCursor = &endCursor
+	}
+	return connection
+}
+
+type graphQLResolver struct {
+	server *Server
+}
+
+func (r *graphQLResolver) Me(ctx context.Context) (*userResolver, error) {
+	loaders := graphQLLoadersFrom(ctx)
+	user, err := loaders.users.Load(ctx, loaders.authPayload.Username)()
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{server: r.server, user: user}, nil
+}
+
+func (r *graphQLResolver) Account(ctx context.Context, args struct{ AccountNumber string }) (*accountResolver, error) {
+	account, err := r.server.store.GetAccountByNumber(ctx, args.AccountNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	loaders := graphQLLoadersFrom(ctx)
+	loaders.accounts.Prime(ctx, account.ID, account)
+
+	readable, err := loaders.readableAccount(ctx, account)
+	if err != nil || readable == nil {
+		return nil, err
+	}
+	return &accountResolver{server: r.server, account: *readable}, nil
+}
+
+func (r *graphQLResolver) Accounts(ctx context.Context, args connectionArgs) (*connectionResolver[*accountResolver], error) {
+	loaders := graphQLLoadersFrom(ctx)
+	return r.server.graphQLAccounts(ctx, loaders.authPayload.Username, args)
+}
+
+func (r *graphQLResolver) Transfer(ctx context.Context, args struct{ ID graphql.ID }) (*transferResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	loaders := graphQLLoadersFrom(ctx)
+	transfer, err := loaders.transfers.Load(ctx, id)()
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// a transfer is visible to the readers of either account, like in listTransfers
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := loaders.loadReadableAccount(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if account != nil {
+			return &transferResolver{server: r.server, transfer: transfer}, nil
+		}
+	}
+	return nil, nil
+}
+
+// graphQLAccounts lists the personal accounts of the user like listAccount
+func (server *Server) graphQLAccounts(ctx context.Context, username string, args connectionArgs) (*connectionResolver[*accountResolver], error) {
+	afterID, limit, err := args.page()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := server.store.ListAccountsAfter(ctx, db.ListAccountsAfterParams{
+		Owner:     username,
+		AfterID:   afterID,
+		PageLimit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	loaders := graphQLLoadersFrom(ctx)
+	for _, account := range accounts {
+		loaders.accounts.Prime(ctx, account.ID, account)
+	}
+
+	return newConnection(accounts, args.First, func(account db.Account) int64 {
+		return account.ID
+	}, func(account db.Account) *accountResolver {
+		return &accountResolver{server: server, account: account}
+	}), nil
+}
+
+type userResolver struct {
+	server *Server
+	user   db.User
+}
+
+func (r *userResolver) isCaller(ctx context.Context) bool {
+	return graphQLLoadersFrom(ctx).authPayload.Username == r.user.Username
+}
+
+func (r *userResolver) Username() string {
+	return r.user.Username
+}
+
+func (r *userResolver) FullName() string {
+	return r.user.FullName
+}
+
+func (r *userResolver) Email(ctx context.Context) *string {
+	if !r.isCaller(ctx) {
+		return nil
+	}
+	return &r.user.Email
+}
+
+func (r *userResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.user.CreatedAt}
+}
+
+func (r *userResolver) Accounts(ctx context.Context, args connectionArgs) (*connectionResolver[*accountResolver], error) {
+	if !r.isCaller(ctx) {
+		return nil, nil
+	}
+	return r.server.graphQLAccounts(ctx, r.user.Username, args)
+}
+
+// accountResolver resolves an account the caller may read
+type accountResolver struct {
+	server  *Server
+	account db.Account
+}
+
+func (r *accountResolver) AccountNumber() string {
+	return r.account.AccountNumber
+}
+
+func (r *accountResolver) Owner(ctx context.Context) (*userResolver, error) {
+	user, err := graphQLLoadersFrom(ctx).users.Load(ctx, r.account.Owner)()
+	if err != nil {
+		return nil, err
+	}
+	return &userResolver{server: r.server, user: user}, nil
+}
+
+func (r *accountResolver) OrganizationID() *graphql.ID {
+	return optionalGraphQLID(r.account.OrganizationID)
+}
+
+func (r *accountResolver) Balance() int64Scalar {
+	return int64Scalar(r.account.Balance)
+}
+
+func (r *accountResolver) AvailableBalance() int64Scalar {
+	return int64Scalar(r.account.AvailableBalance)
+}
+
+func (r *accountResolver) Currency() string {
+	return r.account.Currency
+}
+
+func (r *accountResolver) AccountType() string {
+	return r.account.AccountType
+}
+
+func (r *accountResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.account.CreatedAt}
+}
+
+func (r *accountResolver) Entries(ctx context.Context, args connectionArgs) (*connectionResolver[*entryResolver], error) {
+	afterID, limit, err := args.page()
+	if err != nil {
+		return nil, err
+	}
+
+	key := accountPageKey{accountID: r.account.ID, afterID: afterID, limit: limit}
+	entries, err := graphQLLoadersFrom(ctx).entryPages.Load(ctx, key)()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConnection(entries, args.First, func(entry db.Entry) int64 {
+		return entry.ID
+	}, func(entry db.Entry) *entryResolver {
+		return &entryResolver{server: r.server, entry: entry}
+	}), nil
+}
+
+func (r *accountResolver) Transfers(ctx context.Context, args connectionArgs) (*connectionResolver[*transferResolver], error) {
+	afterID, limit, err := args.page()
+	if err != nil {
+		return nil, err
+	}
+
+	loaders := graphQLLoadersFrom(ctx)
+	key := accountPageKey{accountID: r.account.ID, afterID: afterID, limit: limit}
+	transfers, err := loaders.transferPages.Load(ctx, key)()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transfer := range transfers {
+		loaders.transfers.Prime(ctx, transfer.ID, transfer)
+	}
+
+	return newConnection(transfers, args.First, func(transfer db.Transfer) int64 {
+		return transfer.ID
+	}, func(transfer db.Transfer) *transferResolver {
+		return &transferResolver{server: r.server, transfer: transfer}
+	}), nil
+}
+
+// entryResolver resolves an entry of an account the caller may read
+type entryResolver struct {
+	server *Server
+	entry  db.Entry
+}
+
+func (r *entryResolver) ID() graphql.ID {
+	return graphQLID(r.entry.ID)
+}
+
+func (r *entryResolver) Account(ctx context.Context) (*accountResolver, error) {
+	return r.server.graphQLReadableAccount(ctx, r.entry.AccountID)
+}
+
+func (r *entryResolver) Amount() int64Scalar {
+	return int64Scalar(r.entry.Amount)
+}
+
+func (r *entryResolver) Description() string {
+	return r.entry.Description
+}
+
+func (r *entryResolver) Transfer(ctx context.Context) (*transferResolver, error) {
+	if r.entry.TransferID == nil {
+		return nil, nil
+	}
+
+	transfer, err := graphQLLoadersFrom(ctx).transfers.Load(ctx, *r.entry.TransferID)()
+	if err != nil {
+		return nil, err
+	}
+	return &transferResolver{server: r.server, transfer: transfer}, nil
+}
+
+func (r *entryResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.entry.CreatedAt}
+}
+
+// transferResolver resolves a transfer from or to an account the caller may read
+type transferResolver struct {
+	server   *Server
+	transfer db.Transfer
+}
+
+func (r *transferResolver) ID() graphql.ID {
+	return graphQLID(r.transfer.ID)
+}
+
+// FromAccountNumber is known even when the caller may not read the account
+func (r *transferResolver) FromAccountNumber(ctx context.Context) (string, error) {
+	return graphQLAccountNumber(ctx, r.transfer.FromAccountID)
+}
+
+// ToAccountNumber is known even when the caller may not read the account
+func (r *transferResolver) ToAccountNumber(ctx context.Context) (string, error) {
+	return graphQLAccountNumber(ctx, r.transfer.ToAccountID)
+}
+
+func (r *transferResolver) FromAccount(ctx context.Context) (*accountResolver, error) {
+	return r.server.graphQLReadableAccount(ctx, r.transfer.FromAccountID)
+}
+
+func (r *transferResolver) ToAccount(ctx context.Context) (*accountResolver, error) {
+	return r.server.graphQLReadableAccount(ctx, r.transfer.ToAccountID)
+}
+
+func (r *transferResolver) Amount() int64Scalar {
+	return int64Scalar(r.transfer.Amount)
+}
+
+func (r *transferResolver) Fee() int64Scalar {
+	return int64Scalar(r.transfer.Fee)
+}
+
+func (r *transferResolver) Description() string {
+	return r.transfer.Description
+}
+
+func (r *transferResolver) Reference() string {
+	return r.transfer.Reference
+}
+
+func (r *transferResolver) Metadata() string {
+	return string(r.transfer.Metadata)
+}
+
+func (r *transferResolver) Status() string {
+	return r.transfer.Status
+}
+
+func (r *transferResolver) RequiredApprovals() int32 {
+	return r.transfer.RequiredApprovals
+}
+
+func (r *transferResolver) InitiatedBy() *string {
+	return r.transfer.InitiatedBy
+}
+
+func (r *transferResolver) DecidedBy() *string {
+	return r.transfer.DecidedBy
+}
+
+func (r *transferResolver) DecidedAt() *graphql.Time {
+	if r.transfer.DecidedAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.transfer.DecidedAt}
+}
+
+func (r *transferResolver) ExpiresAt() *graphql.Time {
+	if r.transfer.ExpiresAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.transfer.ExpiresAt}
+}
+
+func (r *transferResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.transfer.CreatedAt}
+}
+
+func graphQLAccountNumber(ctx context.Context, id int64) (string, error) {
+	account, err := graphQLLoadersFrom(ctx).accounts.Load(ctx, id)()
+	if err != nil {
+		return "", err
+	}
+	return account.AccountNumber, nil
+}
+
+func (server *Server) graphQLReadableAccount(ctx context.Context, id int64) (*accountResolver, error) {
+	account, err := graphQLLoadersFrom(ctx).loadReadableAccount(ctx, id)
+	if err != nil || account == nil {
+		return nil, err
+	}
+	return &accountResolver{server: server, account: *account}, nil
+}
@@ -0,0 +1,526 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func execGraphQL(t *testing.T, server *Server, username string, body any) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, username, util.DepositorRole)
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+type graphQLTestResponse[T any] struct {
+	Data   T
+	Errors []struct {
+		Message    string
+		Extensions struct{ Code string }
+	}
+}
+
+func decodeGraphQL[T any](t *testing.T, recorder *httptest.ResponseRecorder) graphQLTestResponse[T] {
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var resp graphQLTestResponse[T]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestGraphQLAccounts(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	// the caller owns two accounts and is a member of one of other
+	accounts := []db.Account{randomAccount(user.Username), randomAccount(user.Username), randomAccount(other.Username)}
+	for i := range accounts {
+		accounts[i].ID = int64(i + 1)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccountsAfter(gomock.Any(), gomock.Eq(db.ListAccountsAfterParams{Owner: user.Username, AfterID: 0, PageLimit: 4})).
+		Times(1).
+		Return(accounts, nil)
+	store.EXPECT().
+		GetUsersByUsernames(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, usernames []string) ([]db.User, error) {
+			assert.ElementsMatch(t, []string{user.Username, other.Username}, usernames)
+			return []db.User{user, other}, nil
+		})
+
+	server := newServerTest(t, store)
+	recorder := execGraphQL(t, server, user.Username, gin.H{
+		"query": `query($first: Int) {
+			accounts(first: $first) {
+				edges { cursor node { accountNumber balance owner { username email } } }
+				pageInfo { hasNextPage endCursor }
+			}
+		}`,
+		"variables": gin.H{"first": 3},
+	})
+
+	resp := decodeGraphQL[struct {
+		Accounts struct {
+			Edges []struct {
+				Cursor string
+				Node   struct {
+					AccountNumber string
+					Balance       int64
+					Owner         struct {
+						Username string
+						Email    *string
+					}
+				}
+			}
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   string
+			}
+		}
+	}](t, recorder)
+
+	require.Empty(t, resp.Errors)
+	edges := resp.Data.Accounts.Edges
+	require.Len(t, edges, 3)
+	for i, edge := range edges {
+		assert.Equal(t, accounts[i].AccountNumber, edge.Node.AccountNumber)
+		assert.Equal(t, accounts[i].Balance, edge.Node.Balance)
+		assert.Equal(t, accounts[i].Owner, edge.Node.Owner.Username)
+	}
+	assert.Equal(t, user.Email, *edges[0].Node.Owner.Email)
+	assert.Nil(t, edges[2].Node.Owner.Email)
+
+	// no row came back after the three asked for
+	assert.False(t, resp.Data.Accounts.PageInfo.HasNextPage)
+	assert.Equal(t, edges[2].Cursor, resp.Data.Accounts.PageInfo.EndCursor)
+}
+
+func TestGraphQLAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	stranger, _ := randomUser(t)
+
+	query := `query($number: String!) {
+		account(accountNumber: $number) { accountNumber currency }
+	}`
+
+	testCases := []struct {
+		name          string
+		username      string
+		variables     gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			username:  user.Username,
+			variables: gin.H{"number": account.AccountNumber},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountsByIDs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQL[struct {
+					Account *struct{ AccountNumber, Currency string }
+				}](t, recorder)
+				require.Empty(t, resp.Errors)
+				require.NotNil(t, resp.Data.Account)
+				assert.Equal(t, account.AccountNumber, resp.Data.Account.AccountNumber)
+				assert.Equal(t, account.Currency, resp.Data.Account.Currency)
+			},
+		},
+		{
+			name:      "NotMember",
+			username:  stranger.Username,
+			variables: gin.H{"number": account.AccountNumber},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListAccountMembershipsOfUser(gomock.Any(), gomock.Eq(db.ListAccountMembershipsOfUserParams{
+						Username:   stranger.Username,
+						AccountIds: []int64{account.ID},
+					})).
+					Times(1).
+					Return([]db.AccountMember{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQL[struct {
+					Account *struct{ AccountNumber string }
+				}](t, recorder)
+				require.Empty(t, resp.Errors)
+				assert.Nil(t, resp.Data.Account)
+			},
+		},
+		{
+			name:      "NotFound",
+			username:  user.Username,
+			variables: gin.H{"number": account.AccountNumber},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQL[struct {
+					Account *struct{ AccountNumber string }
+				}](t, recorder)
+				require.Empty(t, resp.Errors)
+				assert.Nil(t, resp.Data.Account)
+			},
+		},
+		{
+			name:      "InternalError",
+			username:  user.Username,
+			variables: gin.H{"number": account.AccountNumber},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQL[struct {
+					Account *struct{ AccountNumber string }
+				}](t, recorder)
+				require.Len(t, resp.Errors, 1)
+				assert.Equal(t, "internal error", resp.Errors[0].Message)
+				assert.Equal(t, CodeInternal, resp.Errors[0].Extensions.Code)
+			},
+		},
+		{
+			name:      "NoNumber",
+			username:  user.Username,
+			variables: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQL[struct {
+					Account *struct{ AccountNumber string }
+				}](t, recorder)
+				require.Len(t, resp.Errors, 1)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := execGraphQL(t, server, tc.username, gin.H{"query": query, "variables": tc.variables})
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestGraphQLBatchesRelations makes sure the counterparties of a page of transfers
+// are loaded and authorized with one query each instead of one per transfer
+func TestGraphQLBatchesRelations(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.ID = 1
+
+	var counterparties []db.Account
+	var transfers []db.Transfer
+	for i := 0; i < 3; i++ {
+		other, _ := randomUser(t)
+		counterparty := randomAccount(other.Username)
+		counterparty.ID = int64(10 + i)
+		counterparties = append(counterparties, counterparty)
+
+		transfers = append(transfers, db.Transfer{
+			ID:            int64(100 + i),
+			FromAccountID: account.ID,
+			ToAccountID:   counterparty.ID,
+			Amount:        util.RandomInt(1, 1000),
+			Metadata:      json.RawMessage("{}"),
+			Status:        db.TransferStatusCompleted,
+		})
+	}
+	// the caller may read the first counterparty because it is shared with them
+	shared := randomAccountMember(counterparties[0], util.AccountViewerRole)
+	shared.Username = user.Username
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).Times(1).Return(account, nil)
+	store.EXPECT().
+		ListTransfersAfterOfAccounts(gomock.Any(), gomock.Eq(db.ListTransfersAfterOfAccountsParams{AccountIds: []int64{account.ID}, AfterID: 99, PageLimit: 21})).
+		Times(1).
+		DoAndReturn(func(_ context.Context, _ db.ListTransfersAfterOfAccountsParams) ([]db.ListTransfersAfterOfAccountsRow, error) {
+			rows := make([]db.ListTransfersAfterOfAccountsRow, len(transfers))
+			for i, transfer := range transfers {
+				rows[i] = db.ListTransfersAfterOfAccountsRow{AccountID: account.ID, Transfer: transfer}
+			}
+			return rows, nil
+		})
+	store.EXPECT().
+		GetAccountsByIDs(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, ids []int64) ([]db.Account, error) {
+			assert.ElementsMatch(t, []int64{10, 11, 12}, ids)
+			return counterparties, nil
+		})
+	store.EXPECT().
+		ListAccountMembershipsOfUser(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ListAccountMembershipsOfUserParams) ([]db.AccountMember, error) {
+			assert.Equal(t, user.Username, arg.Username)
+			assert.ElementsMatch(t, []int64{10, 11, 12}, arg.AccountIds)
+			return []db.AccountMember{shared}, nil
+		})
+	owner := db.User{Username: counterparties[0].Owner}
+	store.EXPECT().GetUsersByUsernames(gomock.Any(), gomock.Eq([]string{owner.Username})).Times(1).Return([]db.User{owner}, nil)
+
+	server := newServerTest(t, store)
+	recorder := execGraphQL(t, server, user.Username, gin.H{
+		"query": fmt.Sprintf(`{
+			account(accountNumber: %q) {
+				transfers(after: %q) {
+					edges { node { id fromAccountNumber toAccountNumber fromAccount { accountNumber } toAccount { accountNumber owner { username } } } }
+				}
+			}
+		}`, account.AccountNumber, encodeCursor(99)),
+	})
+
+	resp := decodeGraphQL[struct {
+		Account struct {
+			Transfers struct {
+				Edges []struct {
+					Node struct {
+						ID                string
+						FromAccountNumber string
+						ToAccountNumber   string
+						FromAccount       *struct{ AccountNumber string }
+						ToAccount         *struct {
+							AccountNumber string
+							Owner         struct{ Username string }
+						}
+					}
+				}
+			}
+		}
+	}](t, recorder)
+	require.Empty(t, resp.Errors)
+
+	edges := resp.Data.Account.Transfers.Edges
+	require.Len(t, edges, 3)
+	for i, edge := range edges {
+		require.NotNil(t, edge.Node.FromAccount)
+		assert.Equal(t, account.AccountNumber, edge.Node.FromAccount.AccountNumber)
+		assert.Equal(t, account.AccountNumber, edge.Node.FromAccountNumber)
+		// the number of a counterparty is there even when the account is not readable
+		assert.Equal(t, counterparties[i].AccountNumber, edge.Node.ToAccountNumber)
+	}
+	require.NotNil(t, edges[0].Node.ToAccount)
+	assert.Equal(t, counterparties[0].Owner, edges[0].Node.ToAccount.Owner.Username)
+	assert.Nil(t, edges[1].Node.ToAccount)
+	assert.Nil(t, edges[2].Node.ToAccount)
+}
+
+// TestGraphQLBatchesConnections makes sure the connections of a list of accounts
+// are queried once for all the accounts instead of once per account
+func TestGraphQLBatchesConnections(t *testing.T) {
+	user, _ := randomUser(t)
+	accounts := []db.Account{randomAccount(user.Username), randomAccount(user.Username), randomAccount(user.Username)}
+	for i := range accounts {
+		accounts[i].ID = int64(i + 1)
+	}
+
+	entries := []db.Entry{
+		{ID: 10, AccountID: accounts[0].ID, Amount: 100},
+		{ID: 11, AccountID: accounts[2].ID, Amount: -50},
+		{ID: 12, AccountID: accounts[0].ID, Amount: 25},
+	}
+	// the transfer between the first two accounts is in both of their pages
+	transfer := db.Transfer{ID: 20, FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 10, Metadata: json.RawMessage("{}")}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccountsAfter(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(accounts, nil)
+	store.EXPECT().
+		ListEntriesAfterOfAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ListEntriesAfterOfAccountsParams) ([]db.Entry, error) {
+			assert.ElementsMatch(t, []int64{1, 2, 3}, arg.AccountIds)
+			assert.Equal(t, int32(6), arg.PageLimit)
+			return entries, nil
+		})
+	store.EXPECT().
+		ListTransfersAfterOfAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ListTransfersAfterOfAccountsParams) ([]db.ListTransfersAfterOfAccountsRow, error) {
+			assert.ElementsMatch(t, []int64{1, 2, 3}, arg.AccountIds)
+			return []db.ListTransfersAfterOfAccountsRow{
+				{AccountID: accounts[0].ID, Transfer: transfer},
+				{AccountID: accounts[1].ID, Transfer: transfer},
+			}, nil
+		})
+
+	server := newServerTest(t, store)
+	recorder := execGraphQL(t, server, user.Username, gin.H{
+		"query": `{
+			accounts(first: 10) {
+				edges { node { accountNumber entries(first: 5) { edges { node { id } } } transfers(first: 5) { edges { node { id } } } } }
+			}
+		}`,
+	})
+
+	type connection struct {
+		Edges []struct {
+			Node struct{ ID string }
+		}
+	}
+	resp := decodeGraphQL[struct {
+		Accounts struct {
+			Edges []struct {
+				Node struct {
+					AccountNumber string
+					Entries       connection
+					Transfers     connection
+				}
+			}
+		}
+	}](t, recorder)
+	require.Empty(t, resp.Errors)
+
+	ids := func(c connection) []string {
+		result := []string{}
+		for _, edge := range c.Edges {
+			result = append(result, edge.Node.ID)
+		}
+		return result
+	}
+	edges := resp.Data.Accounts.Edges
+	require.Len(t, edges, 3)
+	assert.Equal(t, []string{"10", "12"}, ids(edges[0].Node.Entries))
+	assert.Equal(t, []string{}, ids(edges[1].Node.Entries))
+	assert.Equal(t, []string{"11"}, ids(edges[2].Node.Entries))
+	assert.Equal(t, []string{"20"}, ids(edges[0].Node.Transfers))
+	assert.Equal(t, []string{"20"}, ids(edges[1].Node.Transfers))
+	assert.Equal(t, []string{}, ids(edges[2].Node.Transfers))
+}
+
+func TestGraphQLInvalidRequest(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NoQuery",
+			body: gin.H{},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCursor",
+			body: gin.H{"query": `{ accounts(after: "not a cursor") { edges { cursor } } }`},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQL[any](t, recorder)
+				require.Len(t, resp.Errors, 1)
+				assert.Equal(t, ErrInvalidCursor.Error(), resp.Errors[0].Message)
+				assert.Equal(t, CodeBadRequest, resp.Errors[0].Extensions.Code)
+			},
+		},
+		{
+			name: "PageTooLarge",
+			body: gin.H{"query": `{ accounts(first: 1000) { edges { cursor } } }`},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQL[any](t, recorder)
+				require.Len(t, resp.Errors, 1)
+				assert.Equal(t, fmt.Sprintf("first must be between 1 and %d", graphQLMaxPageSize), resp.Errors[0].Message)
+			},
+		},
+		{
+			name: "TooDeep",
+			body: gin.H{"query": `{ me { accounts { edges { node { transfers { edges { node { toAccount { accountNumber } } } } } } } } }`},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				resp := decodeGraphQL[any](t, recorder)
+				require.NotEmpty(t, resp.Errors)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// none of the requests gets to the store
+			server := newServerTest(t, mockdb.NewMockStore(ctrl))
+			recorder := execGraphQL(t, server, user.Username, tc.body)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestGraphQLInternalErrorLogged makes sure the error of the database only goes to the log
+func TestGraphQLInternalErrorLogged(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	dbErr := errors.New(`pq: relation "accounts" does not exist`)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, dbErr)
+
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+	var logs bytes.Buffer
+	server := NewServer(testConfig, store, tokenMaker, nil, slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	recorder := execGraphQL(t, server, user.Username, gin.H{
+		"query": fmt.Sprintf(`{ account(accountNumber: %q) { accountNumber } }`, account.AccountNumber),
+	})
+
+	resp := decodeGraphQL[any](t, recorder)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "internal error", resp.Errors[0].Message)
+	assert.NotContains(t, recorder.Body.String(), "relation")
+	assert.Contains(t, logs.String(), `"level":"ERROR"`)
+	assert.Contains(t, logs.String(), "does not exist")
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/metrics"
+	"github.com/novalyezu/simplebank-backend/pb"
+	"github.com/novalyezu/simplebank-backend/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcPublicMethods don't need a token, like the routes outside of authMiddleware
+var grpcPublicMethods = map[string]bool{
+	pb.UserService_CreateUser_FullMethodName: true,
+	pb.UserService_LoginUser_FullMethodName:  true,
+}
+
+type authPayloadContextKey struct{}
+
+// grpcService implements the gRPC services on the store, token maker
+// and authorization rules of the HTTP server
+type grpcService struct {
+	pb.UnimplementedUserServiceServer
+	pb.UnimplementedAccountServiceServer
+	pb.UnimplementedTransferServiceServer
+
+	server *Server
+}
+
+// NewGRPCServer serves users, accounts and transfers over gRPC for internal services
+func (server *Server) NewGRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(server.grpcAuthInterceptor))
+
+	service := &grpcService{server: server}
+	pb.RegisterUserServiceServer(grpcServer, service)
+	pb.RegisterAccountServiceServer(grpcServer, service)
+	pb.RegisterTransferServiceServer(grpcServer, service)
+
+	return grpcServer
+}
+
+// StartGRPC serves gRPC on address until ctx is done, the calls in flight
+// then have drainTimeout to finish before they are cut
+func (server *Server) StartGRPC(ctx context.Context, address string, drainTimeout time.Duration) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := server.NewGRPCServer()
+	served := make(chan error, 1)
+	go func() {
+		served <- grpcServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(drainTimeout):
+		grpcServer.Stop()
+	}
+	return <-served
+}
+
+// grpcAuthInterceptor does for gRPC what the request ID, audit and auth middlewares do for HTTP,
+// the token is read from the authorization metadata and the request ID from x-request-id
+func (server *Server) grpcAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := newRequestID(firstMetadata(md, requestIDHeaderKey))
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeaderKey, requestID))
+
+	var clientIP string
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			clientIP = host
+		}
+	}
+	ctx = db.WithAuditInfo(ctx, db.AuditInfo{ClientIP: clientIP, RequestID: requestID})
+
+	var username string
+	start := time.Now()
+	defer func() {
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", clientIP),
+		}
+		if username != "" {
+			attrs = append(attrs, slog.String("username", username))
+		}
+		level := slog.LevelInfo
+		if status.Code(err) == codes.Internal || status.Code(err) == codes.Unknown {
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		db.LoggerFrom(ctx, server.logger).LogAttrs(ctx, level, "grpc request", attrs...)
+	}()
+
+	if grpcPublicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	payload, err := verifyAuthorization(server.tokenMaker, firstMetadata(md, authorizationHeaderKey))
+	if err != nil {
+		metrics.TokenVerificationFailures.WithLabelValues(tokenFailureReason(err)).Inc()
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	username = payload.Username
+
+	ctx = context.WithValue(ctx, authPayloadContextKey{}, payload)
+	ctx = withAuditActor(ctx, payload.Username)
+	return handler(ctx, req)
+}
+
+// firstMetadata returns the first value of the key, metadata keys are lower case
+func firstMetadata(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// authPayloadFrom returns the payload grpcAuthInterceptor verified, it must not be
+// called from the public methods
+func authPayloadFrom(ctx context.Context) *token.Payload {
+	return ctx.Value(authPayloadContextKey{}).(*token.Payload)
+}
+
+// validateGRPCRequest checks req with the binding rules of the HTTP requests
+func validateGRPCRequest(req any) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// grpcStoreError maps a missing row to NotFound and anything else to Internal
+func grpcStoreError(err error) error {
+	if err == sql.ErrNoRows {
+		return status.Error(codes.NotFound, "the resource was not found")
+	}
+	return grpcInternalError(err)
+}
+
+// grpcProblemCodes are the codes of the statuses a shared handler function returns
+var grpcProblemCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.FailedPrecondition,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+}
+
+// grpcProblemError maps the error the way writeError does for /v1 and turns the
+// status of the problem into a gRPC code, the message is the detail of the problem
+func grpcProblemError(httpStatus int, err error) error {
+	p := resolveProblem(httpStatus, err)
+	if p.Code == CodeInternal {
+		return grpcInternalError(err)
+	}
+	if p.Code == CodeAlreadyExists {
+		return status.Error(codes.AlreadyExists, p.Detail)
+	}
+	code, ok := grpcProblemCodes[p.Status]
+	if !ok {
+		code = codes.InvalidArgument
+	}
+	return status.Error(code, p.Detail)
+}
+
+// internalError is sent as an Internal status without the error, which may be one of the
+// database or the driver, like writeError does for a 500. grpcAuthInterceptor logs the error.
+type internalError struct {
+	err error
+}
+
+func grpcInternalError(err error) error {
+	return internalError{err: err}
+}
+
+func (err internalError) Error() string {
+	return err.err.Error()
+}
+
+func (err internalError) Unwrap() error {
+	return err.err
+}
+
+func (err internalError) GRPCStatus() *status.Status {
+	return status.New(codes.Internal, "internal error")
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
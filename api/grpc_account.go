@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/lib/pq"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/pb"
+	"github.com/novalyezu/simplebank-backend/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toPBAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance,
+		Currency:         account.Currency,
+		AccountType:      account.AccountType,
+		OverdraftLimit:   account.OverdraftLimit,
+		OverdraftRateBps: account.OverdraftRateBps,
+		OrganizationId:   account.OrganizationID,
+		AccountNumber:    account.AccountNumber,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+	}
+}
+
+func toPBPocket(pocket db.Pocket) *pb.Pocket {
+	return &pb.Pocket{
+		Id:         pocket.ID,
+		Name:       pocket.Name,
+		Balance:    pocket.Balance,
+		GoalAmount: pocket.GoalAmount,
+		CreatedAt:  timestamppb.New(pocket.CreatedAt),
+	}
+}
+
+func (service *grpcService) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	body := createAccountRequest{
+		Currency:    req.GetCurrency(),
+		AccountType: req.GetAccountType(),
+	}
+	if err := validateGRPCRequest(&body); err != nil {
+		return nil, err
+	}
+
+	accountType := body.AccountType
+	if accountType == "" {
+		accountType = util.CheckingAccount
+	}
+
+	authPayload := authPayloadFrom(ctx)
+	account, err := service.server.store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner:       authPayload.Username,
+		Balance:     0,
+		Currency:    body.Currency,
+		AccountType: accountType,
+	})
+	if err != nil {
+		if pgError, ok := err.(*pq.Error); ok {
+			switch pgError.Constraint {
+			case "accounts_owner_fkey":
+				return nil, status.Errorf(codes.PermissionDenied, "cannot create account with owner %s doesn't exists", authPayload.Username)
+			case "owner_currency_type_key":
+				return nil, status.Error(codes.AlreadyExists, "cannot create account with same currency and type")
+			}
+		}
+		return nil, grpcInternalError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: toPBAccount(account)}, nil
+}
+
+func (service *grpcService) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	var account db.Account
+	switch req.GetAccount().(type) {
+	case *pb.GetAccountRequest_AccountNumber:
+		if !util.IsValidAccountNumber(req.GetAccountNumber()) {
+			return nil, status.Error(codes.InvalidArgument, "account_number is not a valid account number")
+		}
+
+		var err error
+		account, err = service.server.store.GetAccountByNumber(ctx, req.GetAccountNumber())
+		if err != nil {
+			return nil, grpcStoreError(err)
+		}
+		if err := service.authorizeAccount(ctx, account, readRoles); err != nil {
+			return nil, err
+		}
+	case *pb.GetAccountRequest_Id:
+		if req.GetId() < 1 {
+			return nil, status.Error(codes.InvalidArgument, "id must be positive")
+		}
+
+		var err error
+		account, err = service.memberAccount(ctx, req.GetId(), readRoles)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or account_number is required")
+	}
+
+	pockets, err := service.server.store.ListPockets(ctx, account.ID)
+	if err != nil {
+		return nil, grpcInternalError(err)
+	}
+
+	accountResp := toAccountResponse(account, pockets)
+	resp := &pb.GetAccountResponse{
+		Account:             toPBAccount(account),
+		Pockets:             make([]*pb.Pocket, 0, len(pockets)),
+		PocketsBalance:      accountResp.PocketsBalance,
+		ConsolidatedBalance: accountResp.ConsolidatedBalance,
+	}
+	for _, pocket := range pockets {
+		resp.Pockets = append(resp.Pockets, toPBPocket(pocket))
+	}
+	return resp, nil
+}
+
+func (service *grpcService) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	query := listAccountRequest{
+		Page:  req.GetPage(),
+		Limit: req.GetLimit(),
+	}
+	if err := validateGRPCRequest(&query); err != nil {
+		return nil, err
+	}
+
+	accounts, err := service.server.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  authPayloadFrom(ctx).Username,
+		Limit:  query.Limit,
+		Offset: (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		return nil, grpcInternalError(err)
+	}
+
+	resp := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		resp.Accounts = append(resp.Accounts, toPBAccount(account))
+	}
+	return resp, nil
+}
+
+// memberAccount is getMemberAccount for gRPC
+func (service *grpcService) memberAccount(ctx context.Context, accountID int64, roles []string) (db.Account, error) {
+	account, err := service.server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return db.Account{}, grpcStoreError(err)
+	}
+	if err := service.authorizeAccount(ctx, account, roles); err != nil {
+		return db.Account{}, err
+	}
+	return account, nil
+}
+
+// accountByIDOrNumber is memberAccount for the requests which name the account
+// either by id or by account number
+func (service *grpcService) accountByIDOrNumber(ctx context.Context, accountID int64, accountNumber string, roles []string) (db.Account, error) {
+	if accountNumber == "" {
+		if accountID < 1 {
+			return db.Account{}, status.Error(codes.InvalidArgument, "account_id or account_number is required")
+		}
+		return service.memberAccount(ctx, accountID, roles)
+	}
+
+	if !util.IsValidAccountNumber(accountNumber) {
+		return db.Account{}, status.Error(codes.InvalidArgument, "account_number is not a valid account number")
+	}
+	account, err := service.server.store.GetAccountByNumber(ctx, accountNumber)
+	if err != nil {
+		return db.Account{}, grpcStoreError(err)
+	}
+	if err := service.authorizeAccount(ctx, account, roles); err != nil {
+		return db.Account{}, err
+	}
+	return account, nil
+}
+
+// authorizeAccount is authorizeAccount of the HTTP server for gRPC, accounts
+// the caller isn't a member of look missing
+func (service *grpcService) authorizeAccount(ctx context.Context, account db.Account, roles []string) error {
+	role, err := service.server.accountRole(ctx, account, authPayloadFrom(ctx))
+	if err != nil {
+		return grpcInternalError(err)
+	}
+	if role == "" {
+		return status.Error(codes.NotFound, sql.ErrNoRows.Error())
+	}
+	if !slices.Contains(roles, role) {
+		return status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+	return nil
+}
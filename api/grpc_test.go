@@ -0,0 +1,658 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/pb"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClientTest serves the gRPC API of a test server in memory
+func newGRPCClientTest(t *testing.T, store db.Store) (*Server, *grpc.ClientConn) {
+	server := newServerTest(t, store)
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.NewGRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return server, conn
+}
+
+func grpcAuthContext(t *testing.T, server *Server, username string) context.Context {
+	accessToken, err := server.tokenMaker.CreateToken(username, util.DepositorRole, 0, time.Minute)
+	assert.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
+}
+
+func TestGRPCAuthInterceptor(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name       string
+		context    func(t *testing.T, server *Server) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			context: func(t *testing.T, server *Server) context.Context {
+				return grpcAuthContext(t, server, user.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(db.ListAccountsParams{Owner: user.Username, Limit: 5})).
+					Times(1).
+					Return([]db.Account{account}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NoToken",
+			context: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UnsupportedAuthType",
+			context: func(t *testing.T, server *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "basic abc")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server, conn := newGRPCClientTest(t, store)
+			client := pb.NewAccountServiceClient(conn)
+
+			var header metadata.MD
+			resp, err := client.ListAccounts(tc.context(t, server), &pb.ListAccountsRequest{Page: 1, Limit: 5}, grpc.Header(&header))
+			assert.Equal(t, tc.code, status.Code(err))
+			assert.NotEmpty(t, header.Get(requestIDHeaderKey))
+			if tc.code == codes.OK {
+				assert.Len(t, resp.Accounts, 1)
+				assert.Equal(t, account.AccountNumber, resp.Accounts[0].AccountNumber)
+			}
+		})
+	}
+}
+
+func TestGRPCLoginUser(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name:     "OK",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "WrongPassword",
+			password: password + "x",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().RecordAuditEvent(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "InvalidPassword",
+			password: "short",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server, conn := newGRPCClientTest(t, store)
+			client := pb.NewUserServiceClient(conn)
+
+			resp, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: tc.password,
+			})
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.Equal(t, user.Username, resp.User.Username)
+
+				payload, err := server.tokenMaker.VerifyToken(resp.AccessToken)
+				assert.NoError(t, err)
+				assert.Equal(t, user.Username, payload.Username)
+			}
+		})
+	}
+}
+
+func TestGRPCGetAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	otherAccount := randomAccount(util.RandomString(6))
+	pocket := db.Pocket{ID: 1, AccountID: account.ID, Name: "Holiday", Balance: 250}
+
+	testCases := []struct {
+		name          string
+		req           *pb.GetAccountRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, resp *pb.GetAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.GetAccountRequest{Account: &pb.GetAccountRequest_Id{Id: account.ID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListPockets(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return([]db.Pocket{pocket}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, account.AccountNumber, resp.Account.AccountNumber)
+				assert.Len(t, resp.Pockets, 1)
+				assert.Equal(t, pocket.Balance, resp.PocketsBalance)
+				assert.Equal(t, account.Balance+pocket.Balance, resp.ConsolidatedBalance)
+			},
+		},
+		{
+			name: "NotMember",
+			req:  &pb.GetAccountRequest{Account: &pb.GetAccountRequest_AccountNumber{AccountNumber: otherAccount.AccountNumber}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(otherAccount.AccountNumber)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListPockets(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.GetAccountRequest{Account: &pb.GetAccountRequest_Id{Id: account.ID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "57P01", Message: "terminating connection due to administrator command"})
+			},
+			checkResponse: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.Internal, status.Code(err))
+				// the error of the driver stays in the log
+				assert.Equal(t, "internal error", status.Convert(err).Message())
+			},
+		},
+		{
+			name: "WrongCheckDigits",
+			req:  &pb.GetAccountRequest{Account: &pb.GetAccountRequest_AccountNumber{AccountNumber: "SB00123456789012"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NoAccount",
+			req:  &pb.GetAccountRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server, conn := newGRPCClientTest(t, store)
+			client := pb.NewAccountServiceClient(conn)
+
+			resp, err := client.GetAccount(grpcAuthContext(t, server, user.Username), tc.req)
+			tc.checkResponse(t, resp, err)
+		})
+	}
+}
+
+func TestGRPCCreateTransfer(t *testing.T) {
+	user1, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account1.ID = 1
+	account1.AvailableBalance = 1000
+	account1.Currency = util.IDR
+
+	user2, _ := randomUser(t)
+	account2 := randomAccount(user2.Username)
+	account2.ID = 2
+	account2.Currency = util.IDR
+
+	amount := int64(100)
+	result := db.TransferTxResult{
+		Transfer: db.Transfer{
+			ID:            util.RandomInt(1, 99),
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Metadata:      []byte(`{"invoice":"42"}`),
+			Status:        "completed",
+		},
+		FromAccount: account1,
+		ToAccount:   account2,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		req           *pb.CreateTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, resp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:     "OK",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Destination: &pb.CreateTransferRequest_ToAccountNumber{ToAccountNumber: account2.AccountNumber},
+				Amount:      amount,
+				Currency:    util.IDR,
+				Metadata:    `{"invoice":"42"}`,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.AccountNumber)).Times(1).Return(account2, nil)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).Times(1).Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+						Metadata:      []byte(`{"invoice":"42"}`),
+					})).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, result.Transfer.ID, resp.Transfer.Id)
+				assert.Equal(t, `{"invoice":"42"}`, resp.Transfer.Metadata)
+				assert.Equal(t, account1.AccountNumber, resp.Transfer.FromAccountNumber)
+				assert.Equal(t, account2.AccountNumber, resp.Transfer.ToAccountNumber)
+				assert.Equal(t, account1.AccountNumber, resp.FromAccount.AccountNumber)
+				assert.Equal(t, account2.AccountNumber, resp.ToAccount.AccountNumber)
+			},
+		},
+		{
+			name:     "FromAccountNumber",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountNumber{FromAccountNumber: account1.AccountNumber},
+				Destination: &pb.CreateTransferRequest_ToAccountNumber{ToAccountNumber: account2.AccountNumber},
+				Amount:      amount,
+				Currency:    util.IDR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.AccountNumber)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.AccountNumber)).Times(1).Return(account2, nil)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).Times(1).Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, account1.AccountNumber, resp.Transfer.FromAccountNumber)
+				assert.Equal(t, account2.AccountNumber, resp.Transfer.ToAccountNumber)
+			},
+		},
+		{
+			name:     "RequiresApproval",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Destination: &pb.CreateTransferRequest_ToAccountId{ToAccountId: account2.ID},
+				Amount:      amount,
+				Currency:    util.IDR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).Times(1).Return(db.ApprovalThreshold{MinAmount: amount}, nil)
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Transfer{ID: 7, Status: "pending", RequiredApprovals: 1}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "pending", resp.Transfer.Status)
+				assert.Nil(t, resp.FromAccount)
+			},
+		},
+		{
+			name:     "FromOtherAccount",
+			username: user2.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Destination: &pb.CreateTransferRequest_ToAccountId{ToAccountId: account2.ID},
+				Amount:      amount,
+				Currency:    util.IDR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:     "InsufficientBalance",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Destination: &pb.CreateTransferRequest_ToAccountId{ToAccountId: account2.ID},
+				Amount:      account1.AvailableBalance + 1,
+				Currency:    util.IDR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name:     "ToAccountNotFound",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Destination: &pb.CreateTransferRequest_ToAccountId{ToAccountId: account2.ID},
+				Amount:      amount,
+				Currency:    util.IDR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:     "CurrencyMismatch",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Destination: &pb.CreateTransferRequest_ToAccountId{ToAccountId: account2.ID},
+				Amount:      amount,
+				Currency:    util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name:     "AmountTooLarge",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Destination: &pb.CreateTransferRequest_ToAccountId{ToAccountId: account2.ID},
+				Amount:      math.MaxInt64,
+				Currency:    util.IDR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:     "NoDestination",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:   &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Amount:   amount,
+				Currency: util.IDR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name:     "InvalidMetadata",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				Source:      &pb.CreateTransferRequest_FromAccountId{FromAccountId: account1.ID},
+				Destination: &pb.CreateTransferRequest_ToAccountId{ToAccountId: account2.ID},
+				Amount:      amount,
+				Currency:    util.IDR,
+				Metadata:    `[1,2]`,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server, conn := newGRPCClientTest(t, store)
+			client := pb.NewTransferServiceClient(conn)
+
+			resp, err := client.CreateTransfer(grpcAuthContext(t, server, tc.username), tc.req)
+			tc.checkResponse(t, resp, err)
+		})
+	}
+}
+
+// the destination of CreateTransferRequest is a oneof, so several destinations can only
+// reach executeTransfer from the HTTP body, the gRPC code is checked on the shared error
+func TestGRPCTransferSeveralDestinations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newServerTest(t, store)
+	_, httpStatus, err := server.executeTransfer(context.Background(), &token.Payload{Username: util.RandomString(6)}, transferRequest{
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Recipient:     util.RandomString(6),
+		Amount:        100,
+		Currency:      util.IDR,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(grpcProblemError(httpStatus, err)))
+}
+
+func TestGRPCListTransfers(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	other := randomAccount(util.RandomString(6))
+
+	transfers := []db.Transfer{
+		{ID: util.RandomInt(1, 100), FromAccountID: account.ID, ToAccountID: other.ID, Amount: 100},
+		{ID: util.RandomInt(101, 200), FromAccountID: other.ID, ToAccountID: account.ID, Amount: 50},
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListTransfersRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, resp *pb.ListTransfersResponse, err error)
+	}{
+		{
+			name: "ByAccountNumber",
+			req: &pb.ListTransfersRequest{
+				Account: &pb.ListTransfersRequest_AccountNumber{AccountNumber: account.AccountNumber},
+				Page:    1,
+				Limit:   5,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).Times(1).Return(account, nil)
+				store.EXPECT().
+					SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+						AccountID: account.ID,
+						Metadata:  []byte("{}"),
+						PageLimit: 5,
+					})).
+					Times(1).
+					Return(transfers, nil)
+				store.EXPECT().
+					GetAccountsByIDs(gomock.Any(), gomock.Len(2)).
+					Times(1).
+					Return([]db.Account{account, other}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *pb.ListTransfersResponse, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, resp.Transfers, 2)
+				assert.Equal(t, account.AccountNumber, resp.Transfers[0].FromAccountNumber)
+				assert.Equal(t, other.AccountNumber, resp.Transfers[0].ToAccountNumber)
+				assert.Equal(t, other.AccountNumber, resp.Transfers[1].FromAccountNumber)
+				assert.Equal(t, account.AccountNumber, resp.Transfers[1].ToAccountNumber)
+			},
+		},
+		{
+			name: "ByAccountID",
+			req: &pb.ListTransfersRequest{
+				Account: &pb.ListTransfersRequest_AccountId{AccountId: account.ID},
+				Page:    1,
+				Limit:   5,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.Transfer{}, nil)
+				store.EXPECT().GetAccountsByIDs(gomock.Any(), gomock.Len(0)).Times(1).Return([]db.Account{}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *pb.ListTransfersResponse, err error) {
+				assert.NoError(t, err)
+				assert.Empty(t, resp.Transfers)
+			},
+		},
+		{
+			name: "InvalidAccountNumber",
+			req: &pb.ListTransfersRequest{
+				Account: &pb.ListTransfersRequest_AccountNumber{AccountNumber: "SB00123456789012"},
+				Page:    1,
+				Limit:   5,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.ListTransfersResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NoAccount",
+			req:  &pb.ListTransfersRequest{Page: 1, Limit: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, resp *pb.ListTransfersResponse, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server, conn := newGRPCClientTest(t, store)
+			client := pb.NewTransferServiceClient(conn)
+
+			resp, err := client.ListTransfers(grpcAuthContext(t, server, user.Username), tc.req)
+			tc.checkResponse(t, resp, err)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toPBTransfer takes the account numbers of both sides, the ids are not sent
+func toPBTransfer(transfer db.Transfer, fromAccountNumber, toAccountNumber string) *pb.Transfer {
+	return &pb.Transfer{
+		Id:                transfer.ID,
+		FromAccountNumber: fromAccountNumber,
+		ToAccountNumber:   toAccountNumber,
+		Amount:            transfer.Amount,
+		Fee:               transfer.Fee,
+		Description:       transfer.Description,
+		Reference:         transfer.Reference,
+		Metadata:          string(transfer.Metadata),
+		Status:            transfer.Status,
+		RequiredApprovals: transfer.RequiredApprovals,
+		InitiatedBy:       transfer.InitiatedBy,
+		DecidedBy:         transfer.DecidedBy,
+		DecidedAt:         optionalTimestamp(transfer.DecidedAt),
+		ExpiresAt:         optionalTimestamp(transfer.ExpiresAt),
+		CreatedAt:         timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+// grpcMetadata treats empty metadata as missing
+func grpcMetadata(metadata string) json.RawMessage {
+	if metadata == "" {
+		return nil
+	}
+	return json.RawMessage(metadata)
+}
+
+// CreateTransfer shares executeTransfer with createTransfer, a transfer which needs
+// approval is created as pending instead of moving the money
+func (service *grpcService) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	body := transferRequest{
+		FromAccountID:     req.GetFromAccountId(),
+		FromAccountNumber: req.GetFromAccountNumber(),
+		ToAccountID:       req.GetToAccountId(),
+		ToAccountNumber:   req.GetToAccountNumber(),
+		Recipient:         req.GetRecipient(),
+		Amount:            req.GetAmount(),
+		Currency:          req.GetCurrency(),
+		Description:       req.GetDescription(),
+		Reference:         req.GetReference(),
+		Metadata:          grpcMetadata(req.GetMetadata()),
+	}
+	if err := validateGRPCRequest(&body); err != nil {
+		return nil, err
+	}
+
+	outcome, status, err := service.server.executeTransfer(ctx, authPayloadFrom(ctx), body)
+	if err != nil {
+		return nil, grpcProblemError(status, err)
+	}
+	if outcome.Pending != nil {
+		return &pb.CreateTransferResponse{
+			Transfer: toPBTransfer(*outcome.Pending, outcome.FromAccountNumber, outcome.ToAccountNumber),
+		}, nil
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:    toPBTransfer(outcome.Result.Transfer, outcome.FromAccountNumber, outcome.ToAccountNumber),
+		FromAccount: toPBAccount(outcome.Result.FromAccount),
+		ToAccount:   toPBAccount(outcome.Result.ToAccount),
+	}, nil
+}
+
+func (service *grpcService) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	account, err := service.accountByIDOrNumber(ctx, req.GetAccountId(), req.GetAccountNumber(), readRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	query := listTransferRequest{
+		AccountID: account.ID,
+		Page:      req.GetPage(),
+		Limit:     req.GetLimit(),
+		Query:     req.GetQuery(),
+		Reference: req.GetReference(),
+		Metadata:  req.GetMetadata(),
+	}
+	if err := validateGRPCRequest(&query); err != nil {
+		return nil, err
+	}
+
+	metadata := grpcMetadata(query.Metadata)
+	if err := validateMetadata(metadata); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	transfers, err := service.server.store.SearchTransfers(ctx, db.SearchTransfersParams{
+		AccountID:  query.AccountID,
+		Query:      query.Query,
+		Reference:  query.Reference,
+		Metadata:   metadataOrEmpty(metadata),
+		PageLimit:  query.Limit,
+		PageOffset: (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		return nil, grpcInternalError(err)
+	}
+
+	// the account numbers of the other sides are looked up in one query
+	accountIDs := make([]int64, 0, 2*len(transfers))
+	for _, transfer := range transfers {
+		accountIDs = append(accountIDs, transfer.FromAccountID, transfer.ToAccountID)
+	}
+	slices.Sort(accountIDs)
+	accounts, err := service.server.store.GetAccountsByIDs(ctx, slices.Compact(accountIDs))
+	if err != nil {
+		return nil, grpcInternalError(err)
+	}
+	accountNumbers := make(map[int64]string, len(accounts))
+	for _, account := range accounts {
+		accountNumbers[account.ID] = account.AccountNumber
+	}
+
+	resp := &pb.ListTransfersResponse{Transfers: make([]*pb.Transfer, 0, len(transfers))}
+	for _, transfer := range transfers {
+		resp.Transfers = append(resp.Transfers, toPBTransfer(transfer,
+			accountNumbers[transfer.FromAccountID], accountNumbers[transfer.ToAccountID]))
+	}
+	return resp, nil
+}
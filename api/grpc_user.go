@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/pb"
+	"github.com/novalyezu/simplebank-backend/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toPBUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+func (service *grpcService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	body := createUserRequest{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+		FullName: req.GetFullName(),
+		Email:    req.GetEmail(),
+	}
+	if err := validateGRPCRequest(&body); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := util.HashPassword(body.Password)
+	if err != nil {
+		return nil, grpcInternalError(err)
+	}
+
+	// nobody is logged in yet, the new user signs up by themselves
+	ctx = withAuditActor(ctx, body.Username)
+	user, err := service.server.store.CreateUser(ctx, db.CreateUserParams{
+		Username:       body.Username,
+		HashedPassword: hashedPassword,
+		FullName:       body.FullName,
+		Email:          body.Email,
+	})
+	if err != nil {
+		if pgError, ok := err.(*pq.Error); ok {
+			switch pgError.Constraint {
+			case "users_pkey":
+				return nil, status.Error(codes.AlreadyExists, "username already exists")
+			case "users_email_key":
+				return nil, status.Error(codes.AlreadyExists, "email already exists")
+			}
+		}
+		return nil, grpcInternalError(err)
+	}
+
+	return &pb.CreateUserResponse{User: toPBUser(user)}, nil
+}
+
+func (service *grpcService) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	body := loginUserRequest{
+		Username:       req.GetUsername(),
+		Password:       req.GetPassword(),
+		OrganizationID: req.GetOrganizationId(),
+	}
+	if err := validateGRPCRequest(&body); err != nil {
+		return nil, err
+	}
+
+	errWrongCredentials := status.Error(codes.Unauthenticated, "username or password is wrong")
+
+	user, err := service.server.store.GetUser(ctx, body.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errWrongCredentials
+		}
+		return nil, grpcInternalError(err)
+	}
+
+	if err := util.CheckPassword(body.Password, user.HashedPassword); err != nil {
+		return nil, errWrongCredentials
+	}
+
+	// the token acts for the organization, only its members may ask for that
+	if body.OrganizationID != 0 {
+		_, err = service.server.store.GetOrganizationMember(ctx, db.GetOrganizationMemberParams{
+			OrganizationID: body.OrganizationID,
+			Username:       user.Username,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+			}
+			return nil, grpcInternalError(err)
+		}
+	}
+
+	accessToken, err := service.server.tokenMaker.CreateToken(user.Username, user.Role, body.OrganizationID, service.server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, grpcInternalError(err)
+	}
+
+	ctx = withAuditActor(ctx, user.Username)
+	err = service.server.store.RecordAuditEvent(ctx, db.AuditRecord{
+		Action:   db.AuditActionLogin,
+		Entity:   "user",
+		EntityID: user.Username,
+		After:    map[string]int64{"organization_id": body.OrganizationID},
+	})
+	if err != nil {
+		return nil, grpcInternalError(err)
+	}
+
+	return &pb.LoginUserResponse{
+		AccessToken: accessToken,
+		User:        toPBUser(user),
+	}, nil
+}
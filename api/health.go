@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/novalyezu/simplebank-backend/db/migration"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// The states of the checks of readyz
+const (
+	checkOK       = "ok"
+	checkFailed   = "failed"
+	statusReady   = "ready"
+	statusUnready = "not_ready"
+)
+
+var latestSchemaVersion = sync.OnceValues(migration.LatestVersion)
+
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+type readinessResponse struct {
+	Status string `json:"status"`
+	// Checks are checkOK or tell what is wrong, the errors go to the log of the request
+	Checks map[string]string `json:"checks"`
+}
+
+// healthz tells the process is up, it doesn't depend on the database so that
+// an outage of the database doesn't restart every instance
+func (server *Server) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, healthResponse{Status: checkOK})
+}
+
+// readyz tells the instance may take traffic: it isn't shutting down, the database
+// answers and the schema is migrated at least to the version the server is built for
+func (server *Server) readyz(c *gin.Context) {
+	resp := readinessResponse{Status: statusReady, Checks: map[string]string{}}
+
+	if server.shuttingDown.Load() {
+		resp.Checks["server"] = "shutting down"
+	} else {
+		resp.Checks["server"] = checkOK
+	}
+
+	ctx, cancel := context.WithTimeout(c, readinessTimeout)
+	defer cancel()
+
+	if err := server.store.Ping(ctx); err != nil {
+		c.Error(err)
+		resp.Checks["database"] = checkFailed
+		resp.Checks["migrations"] = checkFailed
+	} else {
+		resp.Checks["database"] = checkOK
+		resp.Checks["migrations"] = server.checkMigrations(c, ctx)
+	}
+
+	status := http.StatusOK
+	for _, check := range resp.Checks {
+		if check != checkOK {
+			resp.Status = statusUnready
+			status = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(status, resp)
+}
+
+func (server *Server) checkMigrations(c *gin.Context, ctx context.Context) string {
+	latest, err := latestSchemaVersion()
+	if err != nil {
+		c.Error(err)
+		return checkFailed
+	}
+
+	version, err := server.store.GetSchemaVersion(ctx)
+	if err != nil {
+		c.Error(err)
+		return checkFailed
+	}
+
+	switch {
+	case version.Dirty:
+		return fmt.Sprintf("version %d is dirty", version.Version)
+	case version.Version < int64(latest):
+		return fmt.Sprintf("version %d is behind %d", version.Version, latest)
+	}
+	// a newer schema is fine, the migrations run before the new servers replace the old ones
+	return checkOK
+}
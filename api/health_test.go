@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/novalyezu/simplebank-backend/db/migration"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHealthz(t *testing.T) {
+	server := newServerTest(t, nil)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	latest, err := migration.LatestVersion()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		shuttingDown bool
+		buildStubs   func(store *mockdb.MockStore)
+		status       int
+		checks       map[string]string
+	}{
+		{
+			name: "Ready",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: int64(latest)}, nil)
+			},
+			status: http.StatusOK,
+			checks: map[string]string{"server": checkOK, "database": checkOK, "migrations": checkOK},
+		},
+		{
+			name: "NewerSchema",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: int64(latest) + 1}, nil)
+			},
+			status: http.StatusOK,
+			checks: map[string]string{"server": checkOK, "database": checkOK, "migrations": checkOK},
+		},
+		{
+			name: "DatabaseUnreachable",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().GetSchemaVersion(gomock.Any()).Times(0)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"server": checkOK, "database": checkFailed, "migrations": checkFailed},
+		},
+		{
+			name: "SchemaBehind",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: int64(latest) - 1}, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"server": checkOK, "database": checkOK, "migrations": fmt.Sprintf("version %d is behind %d", latest-1, latest)},
+		},
+		{
+			name: "SchemaDirty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: int64(latest), Dirty: true}, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"server": checkOK, "database": checkOK, "migrations": fmt.Sprintf("version %d is dirty", latest)},
+		},
+		{
+			name:         "ShuttingDown",
+			shuttingDown: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: int64(latest)}, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"server": "shutting down", "database": checkOK, "migrations": checkOK},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			server.shuttingDown.Store(tc.shuttingDown)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, tc.status, recorder.Code)
+
+			var resp readinessResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+			assert.Equal(t, tc.checks, resp.Checks)
+			if tc.status == http.StatusOK {
+				assert.Equal(t, statusReady, resp.Status)
+			} else {
+				assert.Equal(t, statusUnready, resp.Status)
+			}
+		})
+	}
+}
+
+func TestServeDrainsRequests(t *testing.T) {
+	server := newServerTest(t, nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server.router.GET("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, listener, time.Minute)
+	}()
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		assert.NoError(t, err)
+		responses <- resp
+	}()
+
+	<-started
+	cancel()
+
+	// the server stops accepting connections but waits for the request in flight
+	require.Eventually(t, server.shuttingDown.Load, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("the server stopped with a request in flight: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	resp := <-responses
+	require.NotNil(t, resp)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, <-served)
+
+	_, err = net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+	assert.Error(t, err)
+}
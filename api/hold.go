@@ -0,0 +1,231 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+const defaultHoldExpiry = 7 * 24 * time.Hour
+
+type holdResponse struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	Amount     int64     `json:"amount"`
+	Status     string    `json:"status"`
+	TransferID *int64    `json:"transfer_id,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func toHoldResponse(hold db.Hold) holdResponse {
+	resp := holdResponse{
+		ID:        hold.ID,
+		AccountID: hold.AccountID,
+		Amount:    hold.Amount,
+		Status:    hold.Status,
+		ExpiresAt: hold.ExpiresAt,
+		CreatedAt: hold.CreatedAt,
+		UpdatedAt: hold.UpdatedAt,
+	}
+	if hold.TransferID.Valid {
+		resp.TransferID = &hold.TransferID.Int64
+	}
+	return resp
+}
+
+type placeHoldRequest struct {
+	AccountID        int64  `json:"account_id" binding:"required,min=1"`
+	Amount           int64  `json:"amount" binding:"required,gt=0,max=1000000000000000"`
+	Currency         string `json:"currency" binding:"required,currency"`
+	ExpiresInMinutes int64  `json:"expires_in_minutes" binding:"omitempty,min=1,max=43200"`
+}
+
+type placeHoldResponse struct {
+	Hold    holdResponse `json:"hold"`
+	Account db.Account   `json:"account"`
+}
+
+func (server *Server) placeHold(c *gin.Context) {
+	var body placeHoldRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getMemberAccount(c, body.AccountID, transactRoles)
+	if !ok {
+		return
+	}
+	if !server.isValidCurrency(c, account, body.Currency) {
+		return
+	}
+
+	expiry := defaultHoldExpiry
+	if body.ExpiresInMinutes > 0 {
+		expiry = time.Duration(body.ExpiresInMinutes) * time.Minute
+	}
+
+	result, err := server.store.PlaceHold(c, db.PlaceHoldParams{
+		AccountID: body.AccountID,
+		Amount:    body.Amount,
+		ExpiresAt: time.Now().Add(expiry),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			writeError(c, http.StatusBadRequest, err)
+			return
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, placeHoldResponse{
+		Hold:    toHoldResponse(result.Hold),
+		Account: result.Account,
+	})
+}
+
+type holdURIRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(c *gin.Context) {
+	var uri holdURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	hold, _, ok := server.getMemberHold(c, uri.ID, readRoles)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, toHoldResponse(hold))
+}
+
+type captureHoldRequest struct {
+	ToAccountID int64 `json:"to_account_id" binding:"required,min=1"`
+}
+
+type captureHoldResponse struct {
+	Hold holdResponse `json:"hold"`
+	db.TransferTxResult
+}
+
+func (server *Server) captureHold(c *gin.Context) {
+	var uri holdURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body captureHoldRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	hold, fromAccount, ok := server.getMemberHold(c, uri.ID, transactRoles)
+	if !ok {
+		return
+	}
+	if hold.AccountID == body.ToAccountID {
+		writeError(c, http.StatusBadRequest, newAPIError(http.StatusBadRequest, CodeSameAccount, "cannot capture hold to same account"))
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(c, body.ToAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if !server.isValidCurrency(c, toAccount, fromAccount.Currency) {
+		return
+	}
+
+	result, err := server.store.CaptureHold(c, db.CaptureHoldParams{
+		HoldID:      hold.ID,
+		ToAccountID: body.ToAccountID,
+	})
+	if err != nil {
+		holdErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, captureHoldResponse{
+		Hold:             toHoldResponse(result.Hold),
+		TransferTxResult: result.TransferTxResult,
+	})
+}
+
+type releaseHoldResponse struct {
+	Hold    holdResponse `json:"hold"`
+	Account db.Account   `json:"account"`
+}
+
+func (server *Server) releaseHold(c *gin.Context) {
+	var uri holdURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	hold, _, ok := server.getMemberHold(c, uri.ID, transactRoles)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.ReleaseHold(c, hold.ID)
+	if err != nil {
+		holdErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, releaseHoldResponse{
+		Hold:    toHoldResponse(result.Hold),
+		Account: result.Account,
+	})
+}
+
+func holdErrorResponse(c *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		writeError(c, http.StatusNotFound, err)
+	case errors.Is(err, db.ErrHoldNotPending), errors.Is(err, db.ErrHoldExpired):
+		writeError(c, http.StatusConflict, err)
+	default:
+		writeError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// getMemberHold loads the hold and makes sure the caller has one of the roles on its account,
+// the error response is already written when it returns false
+func (server *Server) getMemberHold(c *gin.Context, holdID int64, roles []string) (db.Hold, db.Account, bool) {
+	hold, err := server.store.GetHold(c, holdID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return db.Hold{}, db.Account{}, false
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return db.Hold{}, db.Account{}, false
+	}
+
+	account, ok := server.getMemberAccount(c, hold.AccountID, roles)
+	if !ok {
+		return db.Hold{}, db.Account{}, false
+	}
+
+	return hold, account, true
+}
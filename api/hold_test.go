@@ -0,0 +1,369 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func randomHold(account db.Account) db.Hold {
+	return db.Hold{
+		ID:        util.RandomInt(1, 100),
+		AccountID: account.ID,
+		Amount:    util.RandomInt(1, 100),
+		Status:    db.HoldStatusPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func addAuthorization(t *testing.T, request *http.Request, tokenMaker token.Maker, username string, role string) {
+	accessToken, err := tokenMaker.CreateToken(username, role, 0, time.Minute)
+	assert.NoError(t, err)
+	request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
+}
+
+func TestPlaceHoldAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+	account.AvailableBalance = 1000
+
+	otherUser, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          placeHoldRequest
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			body:     placeHoldRequest{AccountID: account.ID, Amount: 100, Currency: util.USD},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					PlaceHold(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.PlaceHoldParams) (db.PlaceHoldResult, error) {
+						assert.Equal(t, account.ID, arg.AccountID)
+						assert.Equal(t, int64(100), arg.Amount)
+						assert.WithinDuration(t, time.Now().Add(defaultHoldExpiry), arg.ExpiresAt, time.Second)
+
+						hold := randomHold(account)
+						hold.Amount = arg.Amount
+						return db.PlaceHoldResult{Hold: hold, Account: account}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp placeHoldResponse
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, int64(100), resp.Hold.Amount)
+				assert.Equal(t, db.HoldStatusPending, resp.Hold.Status)
+			},
+		},
+		{
+			name:     "BadRequest",
+			body:     placeHoldRequest{AccountID: account.ID, Amount: 0, Currency: util.USD},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherAccount",
+			body:     placeHoldRequest{AccountID: account.ID, Amount: 100, Currency: util.USD},
+			username: otherUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().PlaceHold(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			body:     placeHoldRequest{AccountID: account.ID, Amount: 5000, Currency: util.USD},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					PlaceHold(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PlaceHoldResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/holds", bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCaptureHoldAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account1.ID = 1
+	account1.Currency = util.USD
+
+	user2, _ := randomUser(t)
+	account2 := randomAccount(user2.Username)
+	account2.ID = 2
+	account2.Currency = util.USD
+
+	hold := randomHold(account1)
+
+	testCases := []struct {
+		name          string
+		toAccountID   int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "OK",
+			toAccountID: account2.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				captured := hold
+				captured.Status = db.HoldStatusCaptured
+				captured.TransferID = sql.NullInt64{Int64: 7, Valid: true}
+				store.EXPECT().
+					CaptureHold(gomock.Any(), gomock.Eq(db.CaptureHoldParams{
+						HoldID:      hold.ID,
+						ToAccountID: account2.ID,
+					})).
+					Times(1).
+					Return(db.CaptureHoldResult{Hold: captured}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp captureHoldResponse
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, db.HoldStatusCaptured, resp.Hold.Status)
+				assert.Equal(t, int64(7), *resp.Hold.TransferID)
+			},
+		},
+		{
+			name:        "SameAccount",
+			toAccountID: account1.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().CaptureHold(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "NotPending",
+			toAccountID: account2.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					CaptureHold(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CaptureHoldResult{}, db.ErrHoldNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:        "HoldNotFound",
+			toAccountID: account2.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, sql.ErrNoRows)
+				store.EXPECT().CaptureHold(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+			data, err := json.Marshal(captureHoldRequest{ToAccountID: tc.toAccountID})
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/holds/%d/capture", hold.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, user1.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReleaseHoldAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	hold := randomHold(account)
+
+	otherUser, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				released := hold
+				released.Status = db.HoldStatusReleased
+				store.EXPECT().
+					ReleaseHold(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(db.ReleaseHoldResult{Hold: released, Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp releaseHoldResponse
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, db.HoldStatusReleased, resp.Hold.Status)
+				assert.Nil(t, resp.Hold.TransferID)
+			},
+		},
+		{
+			name:     "OtherAccount",
+			username: otherUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ReleaseHold(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalServerError",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ReleaseHold(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(db.ReleaseHoldResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/holds/%d/release", hold.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,21 +1,27 @@
 package api
 
 import (
+	"io"
+	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/novalyezu/simplebank-backend/config"
 	db "github.com/novalyezu/simplebank-backend/db/sqlc"
 	"github.com/novalyezu/simplebank-backend/token"
 	"github.com/novalyezu/simplebank-backend/util"
 	"github.com/stretchr/testify/assert"
 )
 
+var testConfig = config.Config{AccessTokenDuration: time.Minute}
+
 func newServerTest(t *testing.T, store db.Store) *Server {
 	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
 	assert.NoError(t, err)
 
-	server := NewServer(store, tokenMaker)
+	server := NewServer(testConfig, store, tokenMaker, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
 	return server
 }
 
@@ -1,11 +1,18 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/metrics"
 	"github.com/novalyezu/simplebank-backend/token"
 )
 
@@ -13,41 +20,167 @@ const (
 	authorizationHeaderKey  = "authorization"
 	authorizationType       = "bearer"
 	authorizationPayloadKey = "auth_payload"
+	requestIDHeaderKey      = "X-Request-ID"
 )
 
 var (
 	ErrTokenIsNotProvided  = errors.New("token is not provided")
 	ErrTokenIsInvalid      = errors.New("token is invalid")
 	ErrUnsupportedAuthType = errors.New("unsupported authorization type")
+	ErrPermissionDenied    = errors.New("permission denied")
 )
 
 func authMiddleware(token token.Maker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
-		if len(authorizationHeader) == 0 {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrTokenIsNotProvided))
+		payload, err := verifyAuthorization(token, ctx.GetHeader(authorizationHeaderKey))
+		if err != nil {
+			metrics.TokenVerificationFailures.WithLabelValues(tokenFailureReason(err)).Inc()
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
-		fields := strings.Fields(authorizationHeader)
-		if len(fields) != 2 {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrTokenIsInvalid))
-			return
+		ctx.Set(authorizationPayloadKey, payload)
+		setAuditActor(ctx, payload.Username)
+		ctx.Next()
+	}
+}
+
+// verifyAuthorization checks a bearer token given as the value of the authorization header
+func verifyAuthorization(tokenMaker token.Maker, authorizationHeader string) (*token.Payload, error) {
+	if len(authorizationHeader) == 0 {
+		return nil, ErrTokenIsNotProvided
+	}
+
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) != 2 {
+		return nil, ErrTokenIsInvalid
+	}
+
+	if strings.ToLower(fields[0]) != authorizationType {
+		return nil, ErrUnsupportedAuthType
+	}
+
+	accessToken := fields[1]
+	return tokenMaker.VerifyToken(accessToken)
+}
+
+// tokenFailureReason labels the metric of a token rejected by verifyAuthorization
+func tokenFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenIsNotProvided):
+		return "not_provided"
+	case errors.Is(err, ErrTokenIsInvalid):
+		return "malformed_header"
+	case errors.Is(err, ErrUnsupportedAuthType):
+		return "unsupported_type"
+	case errors.Is(err, token.ErrExpiredToken):
+		return "expired"
+	}
+	return "invalid"
+}
+
+// metricsMiddleware counts and times the requests by the pattern of their route,
+// requests without a route are counted under "unmatched"
+func metricsMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
 		}
+		metrics.HTTPRequests.WithLabelValues(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(ctx.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
 
-		if strings.ToLower(fields[0]) != authorizationType {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrUnsupportedAuthType))
-			return
+// requestIDMiddleware puts the request ID into the context of the request and the response,
+// a request ID sent by the client is kept, otherwise a new one is made. The store sees it
+// because the router falls back to the context of the request.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := newRequestID(ctx.GetHeader(requestIDHeaderKey))
+		ctx.Header(requestIDHeaderKey, requestID)
+
+		ctx.Request = ctx.Request.WithContext(db.WithAuditInfo(ctx.Request.Context(), db.AuditInfo{
+			RequestID: requestID,
+		}))
+		ctx.Next()
+	}
+}
+
+// newRequestID keeps the request ID of the client unless it is missing or too long
+func newRequestID(requestID string) string {
+	if requestID == "" || len(requestID) > 128 {
+		return uuid.NewString()
+	}
+	return requestID
+}
+
+// auditMiddleware puts the client IP into the context of the request for the audit log,
+// it must run after requestIDMiddleware
+func auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		info := db.AuditInfoFrom(ctx.Request.Context())
+		info.ClientIP = ctx.ClientIP()
+		ctx.Request = ctx.Request.WithContext(db.WithAuditInfo(ctx.Request.Context(), info))
+		ctx.Next()
+	}
+}
+
+// loggerMiddleware logs every request once it is served, with the errors of the
+// handler which the client doesn't see, it must run after requestIDMiddleware
+func loggerMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", ctx.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", ctx.ClientIP()),
+		}
+		if route := ctx.FullPath(); route != "" {
+			attrs = append(attrs, slog.String("route", route))
+		}
+		if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+			attrs = append(attrs, slog.String("username", payload.(*token.Payload).Username))
 		}
 
-		accessToken := fields[1]
-		payload, err := token.VerifyToken(accessToken)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
+		level := slog.LevelInfo
+		if len(ctx.Errors) > 0 {
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", ctx.Errors.String()))
 		}
+		db.LoggerFrom(ctx.Request.Context(), logger).LogAttrs(ctx.Request.Context(), level, "request", attrs...)
+	}
+}
 
-		ctx.Set(authorizationPayloadKey, payload)
-		ctx.Next()
+// setAuditActor records the changes made for the rest of the request under actor
+func setAuditActor(ctx *gin.Context, actor string) {
+	ctx.Request = ctx.Request.WithContext(withAuditActor(ctx.Request.Context(), actor))
+}
+
+func withAuditActor(ctx context.Context, actor string) context.Context {
+	info := db.AuditInfoFrom(ctx)
+	info.Actor = actor
+	return db.WithAuditInfo(ctx, info)
+}
+
+// roleMiddleware must run after authMiddleware
+func roleMiddleware(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		for _, role := range roles {
+			if payload.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+
+		abortWithError(ctx, http.StatusForbidden, ErrPermissionDenied)
 	}
 }
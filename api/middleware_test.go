@@ -1,18 +1,34 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/metrics"
 	"github.com/novalyezu/simplebank-backend/token"
 	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/mock/gomock"
 )
 
 func TestAuthMiddleware(t *testing.T) {
@@ -25,7 +41,7 @@ func TestAuthMiddleware(t *testing.T) {
 			name: "OK",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				username := util.RandomString(6)
-				token, err := tokenMaker.CreateToken(username, time.Minute)
+				token, err := tokenMaker.CreateToken(username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -46,7 +62,7 @@ func TestAuthMiddleware(t *testing.T) {
 				resp := gin.H{}
 				json.Unmarshal(data, &resp)
 
-				assert.Contains(t, resp["error"], "token is not provided")
+				assert.Contains(t, resp["detail"], "token is not provided")
 			},
 		},
 		{
@@ -63,7 +79,7 @@ func TestAuthMiddleware(t *testing.T) {
 				resp := gin.H{}
 				json.Unmarshal(data, &resp)
 
-				assert.Contains(t, resp["error"], "token is invalid")
+				assert.Contains(t, resp["detail"], "token is invalid")
 			},
 		},
 		{
@@ -80,14 +96,14 @@ func TestAuthMiddleware(t *testing.T) {
 				resp := gin.H{}
 				json.Unmarshal(data, &resp)
 
-				assert.Contains(t, resp["error"], "unsupported authorization type")
+				assert.Contains(t, resp["detail"], "unsupported authorization type")
 			},
 		},
 		{
 			name: "TokenIsExpired",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				username := util.RandomString(6)
-				token, err := tokenMaker.CreateToken(username, -time.Minute)
+				token, err := tokenMaker.CreateToken(username, util.DepositorRole, 0, -time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
 			},
@@ -100,7 +116,8 @@ func TestAuthMiddleware(t *testing.T) {
 				resp := gin.H{}
 				json.Unmarshal(data, &resp)
 
-				assert.Contains(t, resp["error"], "token is expired")
+				assert.Contains(t, resp["detail"], "token is expired")
+				assert.Equal(t, CodeTokenExpired, resp["code"])
 			},
 		},
 	}
@@ -124,3 +141,248 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		role          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: util.BankerRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PermissionDenied",
+			role: util.DepositorRole,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				resp := gin.H{}
+				json.Unmarshal(data, &resp)
+
+				assert.Contains(t, resp["detail"], "permission denied")
+				assert.Equal(t, CodePermissionDenied, resp["code"])
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newServerTest(t, nil)
+
+			authPath := "/banker-only"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker),
+				roleMiddleware(util.BankerRole),
+				func(ctx *gin.Context) { ctx.JSON(http.StatusOK, gin.H{}) },
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			assert.NoError(t, err)
+
+			token, err := server.tokenMaker.CreateToken(util.RandomString(6), tc.role, 0, time.Minute)
+			assert.NoError(t, err)
+			request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, token))
+
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	requestID := util.RandomString(20)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		status     int
+		level      string
+		error      string
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Account{}, nil)
+			},
+			status: http.StatusOK,
+			level:  slog.LevelInfo.String(),
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			status: http.StatusInternalServerError,
+			level:  slog.LevelError.String(),
+			error:  sql.ErrConnDone.Error(),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+			require.NoError(t, err)
+
+			var logs bytes.Buffer
+			server := NewServer(testConfig, store, tokenMaker, nil, slog.New(slog.NewJSONHandler(&logs, nil)))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/accounts?page=1&limit=5", nil)
+			require.NoError(t, err)
+			request.Header.Set(requestIDHeaderKey, requestID)
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+			assert.Equal(t, tc.level, entry["level"])
+			assert.Equal(t, "request", entry["msg"])
+			assert.Equal(t, requestID, entry["request_id"])
+			assert.Equal(t, user.Username, entry["username"])
+			assert.Equal(t, http.MethodGet, entry["method"])
+			assert.Equal(t, "/v1/accounts", entry["route"])
+			assert.EqualValues(t, tc.status, entry["status"])
+			assert.Contains(t, entry, "latency")
+			if tc.error != "" {
+				assert.Contains(t, entry["error"], tc.error)
+			} else {
+				assert.NotContains(t, entry, "error")
+			}
+		})
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Account{}, nil)
+
+	server := newServerTest(t, store)
+
+	requests := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/v1/accounts", "200")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	expired := metrics.TokenVerificationFailures.WithLabelValues("expired")
+	before := []float64{testutil.ToFloat64(requests), testutil.ToFloat64(unmatched), testutil.ToFloat64(expired)}
+
+	request, err := http.NewRequest(http.MethodGet, "/v1/accounts?page=1&limit=5", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	request, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/v1/unknown/%d", util.RandomInt(1, 1000)), nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	expiredToken, err := server.tokenMaker.CreateToken(user.Username, util.DepositorRole, 0, -time.Minute)
+	require.NoError(t, err)
+	request, err = http.NewRequest(http.MethodGet, "/v1/accounts?page=1&limit=5", nil)
+	require.NoError(t, err)
+	request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, expiredToken))
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.Equal(t, before[0]+1, testutil.ToFloat64(requests))
+	assert.Equal(t, before[1]+1, testutil.ToFloat64(unmatched))
+	assert.Equal(t, before[2]+1, testutil.ToFloat64(expired))
+
+	// the metrics are not served on the address of the API
+	recorder := httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, metrics.Path, nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// but on their own in the text format of Prometheus
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- metrics.Serve(ctx, listener)
+	}()
+	defer func() {
+		cancel()
+		require.NoError(t, <-served)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + metrics.Path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `simplebank_http_requests_total{method="GET",route="/v1/accounts",status="200"}`)
+	assert.Contains(t, string(body), `simplebank_token_verification_failures_total{reason="expired"}`)
+	assert.Contains(t, string(body), "simplebank_http_request_duration_seconds_bucket")
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Account{}, nil)
+
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	server := NewServer(testConfig, store, tokenMaker, nil, slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	request, err := http.NewRequest(http.MethodGet, "/v1/accounts?page=1&limit=5", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "/v1/accounts", ended[0].Name())
+	assert.Equal(t, trace.SpanKindServer, ended[0].SpanKind())
+
+	// the log of the request leads to its trace
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, ended[0].SpanContext().TraceID().String(), entry["trace_id"])
+}
@@ -0,0 +1,433 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+// apiOperation documents a route of setupRouter. The schemas are generated from the structs
+// the handler binds and responds with, so the spec changes together with the handlers.
+type apiOperation struct {
+	method  string
+	path    string
+	tag     string
+	summary string
+	// public routes don't need a bearer token
+	public bool
+	// unversioned routes aren't served under /v1
+	unversioned bool
+	uri         any
+	query       any
+	body        any
+	// bodyTypes are the content types of a body which isn't JSON, it is sent as is
+	bodyTypes []string
+	responses []apiResponse
+}
+
+type apiResponse struct {
+	status      int
+	description string
+	// body is nil for responses without content
+	body any
+	// contentType is application/json when empty
+	contentType string
+}
+
+// openAPIEnums are the values accepted by the custom validators registered in NewServer
+var openAPIEnums = map[string][]string{
+	"currency":          {util.USD, util.EUR, util.IDR},
+	"account_type":      {util.CheckingAccount, util.SavingsAccount},
+	"account_role":      {util.AccountOwnerRole, util.AccountCoOwnerRole, util.AccountViewerRole},
+	"organization_role": {util.OrganizationAdminRole, util.OrganizationInitiatorRole, util.OrganizationApproverRole},
+	"webhook_event":     {util.WebhookEventTransferOutgoing, util.WebhookEventTransferIncoming},
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPISpec map[string]any
+)
+
+// serveOpenAPI responds with the OpenAPI document of all routes
+func (server *Server) serveOpenAPI(c *gin.Context) {
+	openAPIOnce.Do(func() {
+		openAPISpec = newOpenAPIGenerator().document(apiOperations)
+	})
+	c.JSON(http.StatusOK, openAPISpec)
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Simplebank API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// serveSwaggerUI renders /openapi.json with Swagger UI loaded from a CDN
+func (server *Server) serveSwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// openAPIGenerator collects the schemas of the named structs as components
+type openAPIGenerator struct {
+	schemas map[string]any
+	types   map[string]reflect.Type
+}
+
+func newOpenAPIGenerator() *openAPIGenerator {
+	return &openAPIGenerator{
+		schemas: map[string]any{},
+		types:   map[string]reflect.Type{},
+	}
+}
+
+func (g *openAPIGenerator) document(operations []apiOperation) map[string]any {
+	paths := map[string]any{}
+	for _, op := range operations {
+		path := op.path
+		if !op.unversioned {
+			path = apiVersionPrefix + path
+		}
+		path = openAPIPath(path)
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.method)] = g.operation(op)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Simplebank API",
+			"version": "1.0.0",
+			"description": "Requests name an account by its id or by its account number. " +
+				"The account details, the gRPC and the GraphQL APIs only send account numbers, " +
+				"the responses which are older than account numbers keep the id for the existing clients.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "PASETO",
+				},
+			},
+		},
+	}
+}
+
+// openAPIPath turns the parameters of a gin path into OpenAPI ones, e.g. :id into {id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (g *openAPIGenerator) operation(op apiOperation) map[string]any {
+	operation := map[string]any{
+		"summary": op.summary,
+		"tags":    []string{op.tag},
+	}
+	if !op.public {
+		operation["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+	}
+
+	var parameters []any
+	if op.uri != nil {
+		parameters = append(parameters, g.parameters(op.uri, "uri", "path")...)
+	}
+	if op.query != nil {
+		parameters = append(parameters, g.parameters(op.query, "form", "query")...)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	switch {
+	case op.body != nil:
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.body))},
+			},
+		}
+	case len(op.bodyTypes) > 0:
+		content := map[string]any{}
+		for _, contentType := range op.bodyTypes {
+			content[contentType] = map[string]any{"schema": map[string]any{"type": "string"}}
+		}
+		operation["requestBody"] = map[string]any{"required": true, "content": content}
+	}
+
+	responses := map[string]any{}
+	for _, resp := range op.responses {
+		description := resp.description
+		if description == "" {
+			description = http.StatusText(resp.status)
+		}
+		response := map[string]any{"description": description}
+		if resp.body != nil {
+			contentType := resp.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			response["content"] = map[string]any{
+				contentType: map[string]any{"schema": g.schema(reflect.TypeOf(resp.body))},
+			}
+		}
+		responses[strconv.Itoa(resp.status)] = response
+	}
+	responses["default"] = map[string]any{
+		"description": "Problem details of the error",
+		"content": map[string]any{
+			problemContentType: map[string]any{"schema": g.schema(reflect.TypeOf(problem{}))},
+		},
+	}
+	operation["responses"] = responses
+
+	return operation
+}
+
+// parameters documents the fields of a request struct bound with the tag, e.g. uri or form
+func (g *openAPIGenerator) parameters(request any, tag string, in string) []any {
+	var parameters []any
+	for _, field := range openAPIFields(reflect.TypeOf(request)) {
+		name := field.Tag.Get(tag)
+		if name == "" || name == "-" {
+			continue
+		}
+
+		binding := field.Tag.Get("binding")
+		parameter := map[string]any{
+			"name":     name,
+			"in":       in,
+			"required": in == "path" || hasBindingRule(binding, "required"),
+			"schema":   g.fieldSchema(field.Type, binding),
+		}
+		if field.Type.Kind() == reflect.Slice {
+			parameter["explode"] = true
+		}
+		parameters = append(parameters, parameter)
+	}
+	return parameters
+}
+
+// openAPIFields returns the fields of the struct with the ones of embedded structs
+// without a tag in their place, the way encoding/json sees them
+func openAPIFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, openAPIFields(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schema returns the schema of the type, named structs are referenced as components
+func (g *openAPIGenerator) schema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		// any JSON value
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schema(t.Elem())
+		if _, ok := schema["$ref"]; ok {
+			return map[string]any{"allOf": []any{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Int32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := openAPISchemaName(t)
+		if other, ok := g.types[name]; ok && other != t {
+			panic(fmt.Sprintf("openapi: %s and %s are both named %s", other, t, name))
+		}
+		if _, ok := g.types[name]; !ok {
+			g.types[name] = t
+			g.schemas[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+// openAPISchemaName names the component after the type, e.g. accountResponse is AccountResponse
+func openAPISchemaName(t reflect.Type) string {
+	return strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+}
+
+// structSchema documents the JSON fields. Request structs require the fields the binding
+// requires, response structs have no binding rules and always have the fields without omitempty.
+func (g *openAPIGenerator) structSchema(t reflect.Type) map[string]any {
+	fields := openAPIFields(t)
+
+	isRequest := false
+	for _, field := range fields {
+		if _, ok := field.Tag.Lookup("binding"); ok {
+			isRequest = true
+		}
+	}
+
+	properties := map[string]any{}
+	required := []string{}
+	for _, field := range fields {
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		binding := field.Tag.Get("binding")
+		properties[name] = g.fieldSchema(field.Type, binding)
+
+		if isRequest && hasBindingRule(binding, "required") || !isRequest && !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fieldSchema adds the binding rules of the field to the schema of its type,
+// the rules after dive apply to the items of a slice
+func (g *openAPIGenerator) fieldSchema(t reflect.Type, binding string) map[string]any {
+	schema := g.schema(t)
+	if binding == "" {
+		return schema
+	}
+
+	rules, itemRules, _ := strings.Cut(binding, ",dive")
+	applyBindingRules(schema, t, rules)
+	if t.Kind() == reflect.Slice && itemRules != "" {
+		applyBindingRules(schema["items"].(map[string]any), t.Elem(), strings.TrimPrefix(itemRules, ","))
+	}
+	return schema
+}
+
+func hasBindingRule(binding string, rule string) bool {
+	for _, r := range strings.Split(binding, ",") {
+		if r == "dive" {
+			return false
+		}
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func applyBindingRules(schema map[string]any, t reflect.Type, rules string) {
+	for _, rule := range strings.Split(rules, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max", "gt":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			var key string
+			switch t.Kind() {
+			case reflect.String:
+				key = map[string]string{"min": "minLength", "max": "maxLength"}[name]
+			case reflect.Slice:
+				key = map[string]string{"min": "minItems", "max": "maxItems"}[name]
+			default:
+				key = map[string]string{"min": "minimum", "max": "maximum", "gt": "minimum"}[name]
+				if name == "gt" {
+					schema["exclusiveMinimum"] = true
+				}
+			}
+			if key != "" {
+				schema[key] = n
+			}
+		case "oneof":
+			schema["enum"] = strings.Fields(value)
+		case "email":
+			schema["format"] = "email"
+		case "http_url":
+			schema["format"] = "uri"
+		case "alphanum":
+			schema["pattern"] = "^[a-zA-Z0-9]+$"
+		case "account_number":
+			schema["pattern"] = fmt.Sprintf("^SB[0-9]{%d}$", util.AccountNumberLength-2)
+		default:
+			if enum, ok := openAPIEnums[name]; ok {
+				schema["enum"] = enum
+			}
+		}
+	}
+}
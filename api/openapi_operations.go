@@ -0,0 +1,354 @@
+package api
+
+import (
+	"net/http"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+// apiOperations documents every route of setupRouter under /v1, a test makes sure none is missing.
+// The unversioned aliases of the routes are deprecated and left out.
+var apiOperations = []apiOperation{
+	{
+		method: http.MethodGet, path: "/openapi.json", tag: "docs", public: true, unversioned: true,
+		summary:   "OpenAPI document of the API",
+		responses: []apiResponse{{status: http.StatusOK, body: map[string]any{}}},
+	},
+	{
+		method: http.MethodGet, path: "/swagger", tag: "docs", public: true, unversioned: true,
+		summary:   "Swagger UI for the OpenAPI document",
+		responses: []apiResponse{{status: http.StatusOK, body: "", contentType: "text/html"}},
+	},
+	{
+		method: http.MethodGet, path: "/healthz", tag: "operations", public: true, unversioned: true,
+		summary:   "Liveness probe",
+		responses: []apiResponse{{status: http.StatusOK, body: healthResponse{}}},
+	},
+	{
+		method: http.MethodGet, path: "/readyz", tag: "operations", public: true, unversioned: true,
+		summary: "Readiness probe, checks the database and its migrations",
+		responses: []apiResponse{
+			{status: http.StatusOK, body: readinessResponse{}},
+			{status: http.StatusServiceUnavailable, description: "Not ready", body: readinessResponse{}},
+		},
+	},
+
+	{
+		method: http.MethodPost, path: "/users", tag: "users", public: true,
+		summary:   "Sign up",
+		body:      createUserRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: userResponse{}}},
+	},
+	{
+		method: http.MethodPost, path: "/users/login", tag: "users", public: true,
+		summary:   "Log in, with organization_id the token acts for the organization",
+		body:      loginUserRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: loginUserResponse{}}},
+	},
+
+	{
+		method: http.MethodGet, path: "/accounts", tag: "accounts",
+		summary:   "List the personal accounts of the caller",
+		query:     listAccountRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []db.Account{}}},
+	},
+	{
+		method: http.MethodGet, path: "/accounts/events", tag: "accounts",
+		summary: "Stream new entries and balance changes as server-sent events",
+		query:   streamAccountEventsRequest{},
+		responses: []apiResponse{{
+			status:      http.StatusOK,
+			description: "A ready event followed by entry and balance events",
+			body:        db.AccountEvent{},
+			contentType: "text/event-stream",
+		}},
+	},
+	{
+		method: http.MethodGet, path: "/accounts/:id", tag: "accounts",
+		summary:   "Get an account by id or account number with its pockets",
+		uri:       getAccountRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: accountResponse{}}},
+	},
+	{
+		method: http.MethodPost, path: "/accounts", tag: "accounts",
+		summary:   "Open a personal account",
+		body:      createAccountRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.Account{}}},
+	},
+	{
+		method: http.MethodGet, path: "/accounts/:id/members", tag: "accounts",
+		summary:   "List the members of a joint account",
+		uri:       accountURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []accountMemberResponse{}}},
+	},
+	{
+		method: http.MethodPost, path: "/accounts/:id/members", tag: "accounts",
+		summary:   "Share the account with a user",
+		uri:       accountURIRequest{},
+		body:      inviteAccountMemberRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: accountMemberResponse{}}},
+	},
+	{
+		method: http.MethodDelete, path: "/accounts/:id/members/:username", tag: "accounts",
+		summary:   "Remove a member from the account",
+		uri:       removeAccountMemberURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: accountMemberResponse{}}},
+	},
+
+	{
+		method: http.MethodGet, path: "/accounts/:id/pockets", tag: "pockets",
+		summary:   "List the pockets of the account",
+		uri:       accountURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []db.Pocket{}}},
+	},
+	{
+		method: http.MethodPost, path: "/accounts/:id/pockets", tag: "pockets",
+		summary:   "Create a pocket",
+		uri:       accountURIRequest{},
+		body:      pocketRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.Pocket{}}},
+	},
+	{
+		method: http.MethodPut, path: "/accounts/:id/pockets/:pocket_id", tag: "pockets",
+		summary:   "Rename a pocket or change its goal",
+		uri:       pocketURIRequest{},
+		body:      pocketRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.Pocket{}}},
+	},
+	{
+		method: http.MethodDelete, path: "/accounts/:id/pockets/:pocket_id", tag: "pockets",
+		summary:   "Delete an empty pocket",
+		uri:       pocketURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.Pocket{}}},
+	},
+	{
+		method: http.MethodPost, path: "/accounts/:id/pockets/:pocket_id/deposit", tag: "pockets",
+		summary:   "Move money from the account into the pocket",
+		uri:       pocketURIRequest{},
+		body:      movePocketRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.MovePocketTxResult{}}},
+	},
+	{
+		method: http.MethodPost, path: "/accounts/:id/pockets/:pocket_id/withdraw", tag: "pockets",
+		summary:   "Move money from the pocket back into the account",
+		uri:       pocketURIRequest{},
+		body:      movePocketRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.MovePocketTxResult{}}},
+	},
+
+	{
+		method: http.MethodGet, path: "/accounts/:id/webhooks", tag: "webhooks",
+		summary:   "List the webhook endpoints of the account",
+		uri:       accountURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []webhookEndpointResponse{}}},
+	},
+	{
+		method: http.MethodPost, path: "/accounts/:id/webhooks", tag: "webhooks",
+		summary:   "Register a webhook endpoint, the signing secret is only returned here",
+		uri:       accountURIRequest{},
+		body:      createWebhookRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: createWebhookResponse{}}},
+	},
+	{
+		method: http.MethodDelete, path: "/accounts/:id/webhooks/:webhook_id", tag: "webhooks",
+		summary:   "Remove a webhook endpoint with its deliveries",
+		uri:       webhookURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: webhookEndpointResponse{}}},
+	},
+	{
+		method: http.MethodGet, path: "/accounts/:id/webhooks/:webhook_id/deliveries", tag: "webhooks",
+		summary:   "List the deliveries of an endpoint, newest first",
+		uri:       webhookURIRequest{},
+		query:     listWebhookDeliveriesRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []db.WebhookDelivery{}}},
+	},
+	{
+		method: http.MethodGet, path: "/accounts/:id/webhooks/:webhook_id/deliveries/:delivery_id", tag: "webhooks",
+		summary:   "Get a delivery with all attempts to send it",
+		uri:       webhookDeliveryURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: webhookDeliveryResponse{}}},
+	},
+	{
+		method: http.MethodPost, path: "/accounts/:id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", tag: "webhooks",
+		summary:   "Queue a delivery again",
+		uri:       webhookDeliveryURIRequest{},
+		responses: []apiResponse{{status: http.StatusAccepted, body: db.WebhookDelivery{}}},
+	},
+
+	{
+		method: http.MethodPost, path: "/organizations", tag: "organizations",
+		summary:   "Create an organization with the caller as its admin",
+		body:      createOrganizationRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.Organization{}}},
+	},
+	{
+		method: http.MethodGet, path: "/organizations", tag: "organizations",
+		summary:   "List the organizations of the caller",
+		responses: []apiResponse{{status: http.StatusOK, body: []db.Organization{}}},
+	},
+	{
+		method: http.MethodGet, path: "/organizations/:id/members", tag: "organizations",
+		summary:   "List the members of the organization",
+		uri:       organizationURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []db.OrganizationMember{}}},
+	},
+	{
+		method: http.MethodPost, path: "/organizations/:id/members", tag: "organizations",
+		summary:   "Add a member to the organization",
+		uri:       organizationURIRequest{},
+		body:      addOrganizationMemberRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.OrganizationMember{}}},
+	},
+	{
+		method: http.MethodDelete, path: "/organizations/:id/members/:username", tag: "organizations",
+		summary:   "Remove a member from the organization",
+		uri:       removeOrganizationMemberURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.OrganizationMember{}}},
+	},
+	{
+		method: http.MethodGet, path: "/organizations/:id/accounts", tag: "organizations",
+		summary:   "List the accounts of the organization",
+		uri:       organizationURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []db.Account{}}},
+	},
+	{
+		method: http.MethodPost, path: "/organizations/:id/accounts", tag: "organizations",
+		summary:   "Open an account for the organization",
+		uri:       organizationURIRequest{},
+		body:      createAccountRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.Account{}}},
+	},
+	{
+		method: http.MethodGet, path: "/organizations/:id/policies", tag: "organizations",
+		summary:   "List the spending policies of the organization",
+		uri:       organizationURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []db.SpendingPolicy{}}},
+	},
+	{
+		method: http.MethodPut, path: "/organizations/:id/policies", tag: "organizations",
+		summary:   "Set how many approvals transfers from an amount need",
+		uri:       organizationURIRequest{},
+		body:      setSpendingPolicyRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.SpendingPolicy{}}},
+	},
+	{
+		method: http.MethodDelete, path: "/organizations/:id/policies/:policy_id", tag: "organizations",
+		summary:   "Delete a spending policy",
+		uri:       deleteSpendingPolicyURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.SpendingPolicy{}}},
+	},
+
+	{
+		method: http.MethodGet, path: "/recipients", tag: "transfers",
+		summary:   "Look up the masked name of a recipient before transferring",
+		query:     lookupRecipientRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: recipientResponse{}}},
+	},
+	{
+		method: http.MethodGet, path: "/transfers", tag: "transfers",
+		summary:   "Search the transfers of an account",
+		query:     listTransferRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []db.Transfer{}}},
+	},
+	{
+		method: http.MethodPost, path: "/transfers", tag: "transfers",
+		summary: "Transfer money, a transfer which needs approval is created as pending",
+		body:    transferRequest{},
+		responses: []apiResponse{
+			{status: http.StatusOK, body: db.TransferTxResult{}},
+			{status: http.StatusAccepted, description: "The transfer waits for approval", body: db.Transfer{}},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/transfers/fee", tag: "transfers",
+		summary:   "Preview the fee of a transfer",
+		query:     transferFeeRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: transferFeeResponse{}}},
+	},
+	{
+		method: http.MethodPost, path: "/transfers/:id/approve", tag: "transfers",
+		summary: "Approve a pending transfer",
+		uri:     transferURIRequest{},
+		responses: []apiResponse{
+			{status: http.StatusOK, description: "The transfer executed", body: db.TransferTxResult{}},
+			{status: http.StatusAccepted, description: "The transfer needs more approvals", body: db.TransferTxResult{}},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/transfers/:id/reject", tag: "transfers",
+		summary:   "Reject a pending transfer",
+		uri:       transferURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.Transfer{}}},
+	},
+	{
+		method: http.MethodPost, path: "/transfers/batch", tag: "transfers",
+		summary:   "Upload a batch of transfers as CSV with a header line or as JSON lines",
+		query:     createTransferBatchRequest{},
+		bodyTypes: []string{"text/csv", "application/x-ndjson"},
+		responses: []apiResponse{{status: http.StatusCreated, body: db.BatchTransferTxResult{}}},
+	},
+	{
+		method: http.MethodGet, path: "/transfers/batch/:id", tag: "transfers",
+		summary:   "Get a batch with the outcome of every row",
+		uri:       transferBatchURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.BatchTransferTxResult{}}},
+	},
+
+	{
+		method: http.MethodPost, path: "/holds", tag: "holds",
+		summary:   "Reserve an amount of the available balance",
+		body:      placeHoldRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: placeHoldResponse{}}},
+	},
+	{
+		method: http.MethodGet, path: "/holds/:id", tag: "holds",
+		summary:   "Get a hold",
+		uri:       holdURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: holdResponse{}}},
+	},
+	{
+		method: http.MethodPost, path: "/holds/:id/capture", tag: "holds",
+		summary:   "Transfer the held amount",
+		uri:       holdURIRequest{},
+		body:      captureHoldRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: captureHoldResponse{}}},
+	},
+	{
+		method: http.MethodPost, path: "/holds/:id/release", tag: "holds",
+		summary:   "Give the held amount back to the available balance",
+		uri:       holdURIRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: releaseHoldResponse{}}},
+	},
+
+	{
+		method: http.MethodPost, path: "/graphql", tag: "graphql",
+		summary:   "Query users, accounts, entries and transfers, the schema is in graphQLSchema",
+		body:      graphQLRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: graphQLResponse{}}},
+	},
+
+	{
+		method: http.MethodGet, path: "/banker/overdrafts", tag: "banker",
+		summary:   "List the overdrawn accounts",
+		query:     listOverdraftsRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []overdraftResponse{}}},
+	},
+	{
+		method: http.MethodPut, path: "/banker/accounts/:id/overdraft", tag: "banker",
+		summary:   "Set the overdraft limit and rate of an account",
+		uri:       updateOverdraftURIRequest{},
+		body:      updateOverdraftRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: db.Account{}}},
+	},
+	{
+		method: http.MethodGet, path: "/banker/audit-events", tag: "banker",
+		summary:   "Search the audit log, newest first",
+		query:     listAuditEventsRequest{},
+		responses: []apiResponse{{status: http.StatusOK, body: []db.AuditEvent{}}},
+	},
+	{
+		method: http.MethodGet, path: "/banker/audit-events/verify", tag: "banker",
+		summary:   "Verify the hash chain of the audit log",
+		responses: []apiResponse{{status: http.StatusOK, body: db.AuditChainResult{}}},
+	},
+}
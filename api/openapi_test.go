@@ -0,0 +1,471 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func openAPIDocument(t *testing.T) map[string]any {
+	data, err := json.Marshal(newOpenAPIGenerator().document(apiOperations))
+	require.NoError(t, err)
+
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(data, &spec))
+	return spec
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	server := newServerTest(t, nil)
+
+	var routes []string
+	for _, route := range server.router.Routes() {
+		routes = append(routes, route.Method+" "+route.Path)
+	}
+
+	// the versioned routes keep a deprecated alias without the prefix
+	var documented []string
+	for _, op := range apiOperations {
+		documented = append(documented, op.method+" "+op.path)
+		if !op.unversioned {
+			documented = append(documented, op.method+" "+apiVersionPrefix+op.path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(documented)
+	assert.Equal(t, routes, documented)
+}
+
+var openAPIPathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+func TestOpenAPIDocument(t *testing.T) {
+	spec := openAPIDocument(t)
+	schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+
+	// every reference points to a component
+	var checkRefs func(value any)
+	checkRefs = func(value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				assert.Contains(t, schemas, name, ref)
+			}
+			for _, item := range v {
+				checkRefs(item)
+			}
+		case []any:
+			for _, item := range v {
+				checkRefs(item)
+			}
+		}
+	}
+	checkRefs(spec)
+
+	// every parameter of a path is documented
+	for path, item := range spec["paths"].(map[string]any) {
+		for method, operation := range item.(map[string]any) {
+			declared := map[string]bool{}
+			if parameters, ok := operation.(map[string]any)["parameters"].([]any); ok {
+				for _, parameter := range parameters {
+					parameter := parameter.(map[string]any)
+					if parameter["in"] == "path" {
+						declared[parameter["name"].(string)] = true
+					}
+				}
+			}
+
+			for _, match := range openAPIPathParam.FindAllStringSubmatch(path, -1) {
+				assert.True(t, declared[match[1]], "%s %s misses the path parameter %s", method, path, match[1])
+			}
+			assert.Len(t, declared, len(openAPIPathParam.FindAllString(path, -1)), "%s %s", method, path)
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	server := newServerTest(t, nil)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec["openapi"])
+	assert.Contains(t, spec["paths"], "/v1/accounts/{id}/webhooks/{webhook_id}")
+	assert.NotContains(t, spec["paths"], "/accounts/{id}/webhooks/{webhook_id}")
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/swagger", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, recorder.Body.String(), `url: "/openapi.json"`)
+}
+
+func TestOpenAPIBindingRules(t *testing.T) {
+	spec := openAPIDocument(t)
+	schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+
+	transfer := schemas["TransferRequest"].(map[string]any)
+	properties := transfer["properties"].(map[string]any)
+	assert.ElementsMatch(t, []any{"amount", "currency"}, transfer["required"])
+	assert.Equal(t, float64(0), properties["amount"].(map[string]any)["minimum"])
+	assert.Equal(t, true, properties["amount"].(map[string]any)["exclusiveMinimum"])
+	assert.ElementsMatch(t, []any{util.USD, util.EUR, util.IDR}, properties["currency"].(map[string]any)["enum"])
+
+	webhook := schemas["CreateWebhookRequest"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, "uri", webhook["url"].(map[string]any)["format"])
+	eventTypes := webhook["event_types"].(map[string]any)
+	assert.Equal(t, float64(10), eventTypes["maxItems"])
+	assert.ElementsMatch(t,
+		[]any{util.WebhookEventTransferOutgoing, util.WebhookEventTransferIncoming},
+		eventTypes["items"].(map[string]any)["enum"],
+	)
+}
+
+// validateSchema checks the JSON value against the subset of OpenAPI the generator uses,
+// it returns the first mismatch
+func validateSchema(spec map[string]any, schema map[string]any, value any, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		component, ok := spec["components"].(map[string]any)["schemas"].(map[string]any)[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: unknown reference %s", at, ref)
+		}
+		return validateSchema(spec, component, value, at)
+	}
+
+	if value == nil {
+		if schema["nullable"] == true || len(schema) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not nullable", at)
+	}
+
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			if err := validateSchema(spec, sub.(map[string]any), value, at); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: %v is not an object", at, value)
+		}
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := object[name.(string)]; !ok {
+					return fmt.Errorf("%s: misses the required %s", at, name)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, item := range object {
+			if property, ok := properties[name].(map[string]any); ok {
+				if err := validateSchema(spec, property, item, at+"."+name); err != nil {
+					return err
+				}
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: %s is not documented", at, name)
+				}
+			case map[string]any:
+				if err := validateSchema(spec, additional, item, at+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: %v is not an array", at, value)
+		}
+		for i, item := range items {
+			if err := validateSchema(spec, schema["items"].(map[string]any), item, at+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: %v is not a string", at, value)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%s: %v is not an integer", at, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: %v is not a number", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: %v is not a boolean", at, value)
+		}
+	}
+	return nil
+}
+
+// fillValue sets every field to a value which isn't empty, so omitempty fields are sent as well
+func fillValue(v reflect.Value) {
+	switch {
+	case v.Type() == timeType:
+		v.Set(reflect.ValueOf(time.Now()))
+		return
+	case v.Type() == rawMessageType:
+		v.Set(reflect.ValueOf(json.RawMessage(`{"key":"value"}`)))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillValue(v.Elem())
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.String:
+		v.SetString("value")
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillValue(v.Index(0))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			// the fields of embedded structs are settable even if the struct type isn't exported
+			if field := v.Type().Field(i); field.IsExported() || field.Anonymous {
+				fillValue(v.Field(i))
+			}
+		}
+	}
+}
+
+// operationContent looks up the operation of a route of apiOperations, those are
+// documented under /v1 unless they are unversioned
+func operationContent(t *testing.T, spec map[string]any, method string, path string) map[string]any {
+	paths := spec["paths"].(map[string]any)
+	item, ok := paths[openAPIPath(apiVersionPrefix+path)].(map[string]any)
+	if !ok {
+		item, ok = paths[openAPIPath(path)].(map[string]any)
+	}
+	require.True(t, ok, path)
+	operation, ok := item[strings.ToLower(method)].(map[string]any)
+	require.True(t, ok, method+" "+path)
+	return operation
+}
+
+func responseSchema(t *testing.T, spec map[string]any, method string, path string, status int, contentType string) map[string]any {
+	operation := operationContent(t, spec, method, path)
+	response, ok := operation["responses"].(map[string]any)[strconv.Itoa(status)].(map[string]any)
+	require.True(t, ok, "%s %s doesn't document %d", method, path, status)
+	return response["content"].(map[string]any)[contentType].(map[string]any)["schema"].(map[string]any)
+}
+
+func TestOpenAPIMatchesStructs(t *testing.T) {
+	spec := openAPIDocument(t)
+
+	for _, op := range apiOperations {
+		if op.body != nil {
+			t.Run(op.method+" "+op.path+" body", func(t *testing.T) {
+				operation := operationContent(t, spec, op.method, op.path)
+				schema := operation["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+
+				requireSchemaMatchesValue(t, spec, schema, op.body)
+			})
+		}
+
+		for _, resp := range op.responses {
+			if resp.body == nil || resp.contentType == "text/html" {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s %s %d", op.method, op.path, resp.status), func(t *testing.T) {
+				contentType := resp.contentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				schema := responseSchema(t, spec, op.method, op.path, resp.status, contentType)
+
+				requireSchemaMatchesValue(t, spec, schema, resp.body)
+			})
+		}
+	}
+}
+
+// requireSchemaMatchesValue validates a filled value of the same type as the sample
+func requireSchemaMatchesValue(t *testing.T, spec map[string]any, schema map[string]any, sample any) {
+	value := reflect.New(reflect.TypeOf(sample)).Elem()
+	fillValue(value)
+
+	data, err := json.Marshal(value.Interface())
+	require.NoError(t, err)
+
+	var decoded any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.NoError(t, validateSchema(spec, schema, decoded, "body"))
+}
+
+// TestOpenAPIMatchesHandlers validates the responses of the handlers themselves
+func TestOpenAPIMatchesHandlers(t *testing.T) {
+	spec := openAPIDocument(t)
+
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	pocket := randomPocket(account)
+	endpoint := randomWebhookEndpoint(account)
+	event := randomAuditEvent(user.Username)
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		url        string
+		role       string
+		body       any
+		status     int
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name:   "CreateUser",
+			method: http.MethodPost,
+			path:   "/users",
+			url:    "/v1/users",
+			body: gin.H{
+				"username":  user.Username,
+				"password":  util.RandomString(8),
+				"full_name": user.FullName,
+				"email":     user.Email,
+			},
+			status: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+			},
+		},
+		{
+			name:   "ListAccounts",
+			method: http.MethodGet,
+			path:   "/accounts",
+			url:    "/v1/accounts?page=1&limit=5",
+			status: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{account}, nil)
+			},
+		},
+		{
+			name:   "GetAccount",
+			method: http.MethodGet,
+			path:   "/accounts/:id",
+			url:    fmt.Sprintf("/v1/accounts/%d", account.ID),
+			status: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListPockets(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return([]db.Pocket{pocket}, nil)
+			},
+		},
+		{
+			name:   "CreateWebhook",
+			method: http.MethodPost,
+			path:   "/accounts/:id/webhooks",
+			url:    fmt.Sprintf("/v1/accounts/%d/webhooks", account.ID),
+			body:   gin.H{"url": endpoint.Url},
+			status: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(1).Return(endpoint, nil)
+			},
+		},
+		{
+			name:   "ListWebhooks",
+			method: http.MethodGet,
+			path:   "/accounts/:id/webhooks",
+			url:    fmt.Sprintf("/v1/accounts/%d/webhooks", account.ID),
+			status: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListWebhookEndpoints(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return([]db.WebhookEndpoint{endpoint}, nil)
+			},
+		},
+		{
+			name:   "ListAuditEvents",
+			method: http.MethodGet,
+			path:   "/banker/audit-events",
+			url:    "/v1/banker/audit-events?page=1&limit=10",
+			role:   util.BankerRole,
+			status: http.StatusOK,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(1).Return([]db.AuditEvent{event}, nil)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			role := tc.role
+			if role == "" {
+				role = util.DepositorRole
+			}
+			addAuthorization(t, request, server.tokenMaker, user.Username, role)
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, tc.status, recorder.Code, recorder.Body.String())
+
+			var decoded any
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+			schema := responseSchema(t, spec, tc.method, tc.path, tc.status, "application/json")
+			assert.NoError(t, validateSchema(spec, schema, decoded, "body"))
+		})
+	}
+}
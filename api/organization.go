@@ -0,0 +1,278 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+var ErrNotActingForOrganization = errors.New("token is not acting for the organization")
+
+var (
+	organizationRoles = []string{util.OrganizationAdminRole, util.OrganizationInitiatorRole, util.OrganizationApproverRole}
+	adminRoles        = []string{util.OrganizationAdminRole}
+)
+
+type createOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+func (server *Server) createOrganization(c *gin.Context) {
+	var body createOrganizationRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	organization, err := server.store.CreateOrganizationTx(c, db.CreateOrganizationParams{
+		Name:      body.Name,
+		CreatedBy: authPayload.Username,
+	})
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// listOrganizations returns the organizations the user can log in for
+func (server *Server) listOrganizations(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	organizations, err := server.store.ListOrganizationsByMember(c, authPayload.Username)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
+
+type organizationURIRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) listOrganizationMembers(c *gin.Context) {
+	var uri organizationURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := server.getActingMember(c, uri.ID, organizationRoles); !ok {
+		return
+	}
+
+	members, err := server.store.ListOrganizationMembers(c, uri.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+type addOrganizationMemberRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Role     string `json:"role" binding:"required,organization_role"`
+}
+
+func (server *Server) addOrganizationMember(c *gin.Context) {
+	var uri organizationURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body addOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := server.getActingMember(c, uri.ID, adminRoles); !ok {
+		return
+	}
+
+	member, err := server.store.AddOrganizationMember(c, db.AddOrganizationMemberParams{
+		OrganizationID: uri.ID,
+		Username:       body.Username,
+		Role:           body.Role,
+	})
+	if err != nil {
+		if pgError, ok := err.(*pq.Error); ok {
+			switch pgError.Constraint {
+			case "organization_members_username_fkey":
+				writeError(c, http.StatusForbidden, newAPIError(http.StatusNotFound, CodeUserNotFound, "cannot add user %s doesn't exists", body.Username))
+				return
+			}
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+type removeOrganizationMemberURIRequest struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required"`
+}
+
+// removeOrganizationMember lets admins remove other members, every member but an admin may leave
+// so that the organization is never left without one
+func (server *Server) removeOrganizationMember(c *gin.Context) {
+	var uri removeOrganizationMemberURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	roles := adminRoles
+	if uri.Username == authPayload.Username {
+		roles = organizationRoles
+	}
+
+	member, ok := server.getActingMember(c, uri.ID, roles)
+	if !ok {
+		return
+	}
+	if uri.Username == member.Username && member.Role == util.OrganizationAdminRole {
+		writeError(c, http.StatusBadRequest, errors.New("an admin cannot leave the organization"))
+		return
+	}
+
+	removed, err := server.store.RemoveOrganizationMember(c, db.RemoveOrganizationMemberParams{
+		OrganizationID: uri.ID,
+		Username:       uri.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, removed)
+}
+
+func (server *Server) createOrganizationAccount(c *gin.Context) {
+	var uri organizationURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body createAccountRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	member, ok := server.getActingMember(c, uri.ID, adminRoles)
+	if !ok {
+		return
+	}
+
+	accountType := body.AccountType
+	if accountType == "" {
+		accountType = util.CheckingAccount
+	}
+
+	account, err := server.store.CreateAccount(c, db.CreateAccountParams{
+		Owner:          member.Username,
+		Balance:        0,
+		Currency:       body.Currency,
+		AccountType:    accountType,
+		OrganizationID: &uri.ID,
+	})
+	if err != nil {
+		if pgError, ok := err.(*pq.Error); ok {
+			switch pgError.Constraint {
+			case "organization_currency_type_key":
+				writeError(c, http.StatusForbidden, newAPIError(http.StatusConflict, CodeAlreadyExists, "cannot create account with same currency and type"))
+				return
+			}
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (server *Server) listOrganizationAccounts(c *gin.Context) {
+	var uri organizationURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := server.getActingMember(c, uri.ID, organizationRoles); !ok {
+		return
+	}
+
+	accounts, err := server.store.ListOrganizationAccounts(c, &uri.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// organizationRole returns the role of the user in the organization, empty when they aren't a member
+func (server *Server) organizationRole(ctx context.Context, organizationID int64, username string) (string, error) {
+	member, err := server.store.GetOrganizationMember(ctx, db.GetOrganizationMemberParams{
+		OrganizationID: organizationID,
+		Username:       username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// getActingMember makes sure the token acts for the organization and its holder still has
+// one of the roles in it, the error response is already written when it returns false
+func (server *Server) getActingMember(c *gin.Context, organizationID int64, roles []string) (db.OrganizationMember, bool) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.OrganizationID != organizationID {
+		writeError(c, http.StatusForbidden, ErrNotActingForOrganization)
+		return db.OrganizationMember{}, false
+	}
+
+	member, err := server.store.GetOrganizationMember(c, db.GetOrganizationMemberParams{
+		OrganizationID: organizationID,
+		Username:       authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusForbidden, ErrPermissionDenied)
+			return db.OrganizationMember{}, false
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return db.OrganizationMember{}, false
+	}
+	if !slices.Contains(roles, member.Role) {
+		writeError(c, http.StatusForbidden, ErrPermissionDenied)
+		return db.OrganizationMember{}, false
+	}
+
+	return member, true
+}
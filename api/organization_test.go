@@ -0,0 +1,341 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func addOrganizationAuthorization(t *testing.T, request *http.Request, tokenMaker token.Maker, username string, organizationID int64) {
+	accessToken, err := tokenMaker.CreateToken(username, util.DepositorRole, organizationID, time.Minute)
+	assert.NoError(t, err)
+	request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
+}
+
+func randomOrganizationMember(organizationID int64, role string) db.OrganizationMember {
+	return db.OrganizationMember{
+		OrganizationID: organizationID,
+		Username:       util.RandomString(6),
+		Role:           role,
+	}
+}
+
+func TestCreateOrganization(t *testing.T) {
+	user, _ := randomUser(t)
+	organization := db.Organization{
+		ID:        util.RandomInt(1, 100),
+		Name:      util.RandomString(8),
+		CreatedBy: user.Username,
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateOrganizationTx(gomock.Any(), gomock.Eq(db.CreateOrganizationParams{
+			Name:      organization.Name,
+			CreatedBy: user.Username,
+		})).
+		Times(1).
+		Return(organization, nil)
+
+	server := newServerTest(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(createOrganizationRequest{Name: organization.Name})
+	assert.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/organizations", bytes.NewBuffer(data))
+	assert.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+	server.router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp db.Organization
+	err = json.Unmarshal(recorder.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, organization, resp)
+}
+
+func TestAddOrganizationMember(t *testing.T) {
+	organizationID := util.RandomInt(1, 100)
+	admin := randomOrganizationMember(organizationID, util.OrganizationAdminRole)
+	initiator := randomOrganizationMember(organizationID, util.OrganizationInitiatorRole)
+	approver := randomOrganizationMember(organizationID, util.OrganizationApproverRole)
+
+	testCases := []struct {
+		name           string
+		username       string
+		organizationID int64
+		body           addOrganizationMemberRequest
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "OK",
+			username:       admin.Username,
+			organizationID: organizationID,
+			body:           addOrganizationMemberRequest{Username: approver.Username, Role: approver.Role},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOrganizationMember(gomock.Any(), gomock.Eq(db.GetOrganizationMemberParams{
+						OrganizationID: organizationID,
+						Username:       admin.Username,
+					})).
+					Times(1).
+					Return(admin, nil)
+				store.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Eq(db.AddOrganizationMemberParams{
+						OrganizationID: organizationID,
+						Username:       approver.Username,
+						Role:           approver.Role,
+					})).
+					Times(1).
+					Return(approver, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp db.OrganizationMember
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, approver, resp)
+			},
+		},
+		{
+			name:           "NotActingForOrganization",
+			username:       admin.Username,
+			organizationID: 0,
+			body:           addOrganizationMemberRequest{Username: approver.Username, Role: approver.Role},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AddOrganizationMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:           "InitiatorCannotAdd",
+			username:       initiator.Username,
+			organizationID: organizationID,
+			body:           addOrganizationMemberRequest{Username: approver.Username, Role: approver.Role},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(initiator, nil)
+				store.EXPECT().AddOrganizationMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:           "NoLongerMember",
+			username:       admin.Username,
+			organizationID: organizationID,
+			body:           addOrganizationMemberRequest{Username: approver.Username, Role: approver.Role},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(db.OrganizationMember{}, sql.ErrNoRows)
+				store.EXPECT().AddOrganizationMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:           "UserNotExists",
+			username:       admin.Username,
+			organizationID: organizationID,
+			body:           addOrganizationMemberRequest{Username: "ghost", Role: approver.Role},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(admin, nil)
+				store.EXPECT().
+					AddOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OrganizationMember{}, &pq.Error{Constraint: "organization_members_username_fkey"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:           "InvalidRole",
+			username:       admin.Username,
+			organizationID: organizationID,
+			body:           addOrganizationMemberRequest{Username: approver.Username, Role: util.AccountViewerRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/organizations/%d/members", organizationID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addOrganizationAuthorization(t, request, server.tokenMaker, tc.username, tc.organizationID)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRemoveOrganizationMember(t *testing.T) {
+	organizationID := util.RandomInt(1, 100)
+	admin := randomOrganizationMember(organizationID, util.OrganizationAdminRole)
+	approver := randomOrganizationMember(organizationID, util.OrganizationApproverRole)
+
+	testCases := []struct {
+		name          string
+		username      string
+		member        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "AdminRemovesMember",
+			username: admin.Username,
+			member:   approver.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(admin, nil)
+				store.EXPECT().
+					RemoveOrganizationMember(gomock.Any(), gomock.Eq(db.RemoveOrganizationMemberParams{
+						OrganizationID: organizationID,
+						Username:       approver.Username,
+					})).
+					Times(1).
+					Return(approver, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "MemberLeaves",
+			username: approver.Username,
+			member:   approver.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(approver, nil)
+				store.EXPECT().RemoveOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(approver, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "AdminCannotLeave",
+			username: admin.Username,
+			member:   admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(admin, nil)
+				store.EXPECT().RemoveOrganizationMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ApproverRemovesOther",
+			username: approver.Username,
+			member:   admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(approver, nil)
+				store.EXPECT().RemoveOrganizationMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/organizations/%d/members/%s", organizationID, tc.member)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			assert.NoError(t, err)
+
+			addOrganizationAuthorization(t, request, server.tokenMaker, tc.username, organizationID)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateOrganizationAccount(t *testing.T) {
+	organizationID := util.RandomInt(1, 100)
+	admin := randomOrganizationMember(organizationID, util.OrganizationAdminRole)
+	account := randomAccount(admin.Username)
+	account.OrganizationID = &organizationID
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(admin, nil)
+	store.EXPECT().
+		CreateAccount(gomock.Any(), gomock.Eq(db.CreateAccountParams{
+			Owner:          admin.Username,
+			Currency:       account.Currency,
+			AccountType:    util.CheckingAccount,
+			OrganizationID: &organizationID,
+		})).
+		Times(1).
+		Return(account, nil)
+
+	server := newServerTest(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(createAccountRequest{Currency: account.Currency})
+	assert.NoError(t, err)
+
+	url := fmt.Sprintf("/organizations/%d/accounts", organizationID)
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+	assert.NoError(t, err)
+
+	addOrganizationAuthorization(t, request, server.tokenMaker, admin.Username, organizationID)
+	server.router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	requiredAccountMatchBody(t, recorder.Body, account)
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+var ErrPocketNotEmpty = errors.New("pocket is not empty, move its balance out first")
+
+type pocketRequest struct {
+	Name       string `json:"name" binding:"required,max=50"`
+	GoalAmount *int64 `json:"goal_amount" binding:"omitempty,gt=0"`
+}
+
+func (server *Server) createPocket(c *gin.Context) {
+	var uri accountURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body pocketRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getMemberAccount(c, uri.ID, transactRoles)
+	if !ok {
+		return
+	}
+
+	pocket, err := server.store.CreatePocket(c, db.CreatePocketParams{
+		AccountID:  account.ID,
+		Name:       body.Name,
+		GoalAmount: body.GoalAmount,
+	})
+	if err != nil {
+		pocketErrorResponse(c, err, body.Name)
+		return
+	}
+
+	c.JSON(http.StatusOK, pocket)
+}
+
+func (server *Server) listPockets(c *gin.Context) {
+	var uri accountURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getMemberAccount(c, uri.ID, readRoles)
+	if !ok {
+		return
+	}
+
+	pockets, err := server.store.ListPockets(c, account.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pockets)
+}
+
+type pocketURIRequest struct {
+	ID       int64 `uri:"id" binding:"required,min=1"`
+	PocketID int64 `uri:"pocket_id" binding:"required,min=1"`
+}
+
+// updatePocket renames the pocket and sets its goal, a missing goal_amount removes the goal
+func (server *Server) updatePocket(c *gin.Context) {
+	var uri pocketURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body pocketRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	pocket, ok := server.getMemberPocket(c, uri.ID, uri.PocketID, transactRoles)
+	if !ok {
+		return
+	}
+
+	pocket, err := server.store.UpdatePocket(c, db.UpdatePocketParams{
+		ID:         pocket.ID,
+		Name:       body.Name,
+		GoalAmount: body.GoalAmount,
+	})
+	if err != nil {
+		pocketErrorResponse(c, err, body.Name)
+		return
+	}
+
+	c.JSON(http.StatusOK, pocket)
+}
+
+type movePocketRequest struct {
+	Amount int64 `json:"amount" binding:"required,gt=0,max=1000000000000000"`
+}
+
+// depositPocket moves money from the account into the pocket
+func (server *Server) depositPocket(c *gin.Context) {
+	server.movePocket(c, 1)
+}
+
+// withdrawPocket moves money from the pocket back to the account
+func (server *Server) withdrawPocket(c *gin.Context) {
+	server.movePocket(c, -1)
+}
+
+func (server *Server) movePocket(c *gin.Context, sign int64) {
+	var uri pocketURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body movePocketRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	pocket, ok := server.getMemberPocket(c, uri.ID, uri.PocketID, transactRoles)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.MovePocketTx(c, db.MovePocketTxParams{
+		PocketID: pocket.ID,
+		Amount:   sign * body.Amount,
+	})
+	if err != nil {
+		pocketErrorResponse(c, err, pocket.Name)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// deletePocket only deletes an empty pocket so that no money is lost with it
+func (server *Server) deletePocket(c *gin.Context) {
+	var uri pocketURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	pocket, ok := server.getMemberPocket(c, uri.ID, uri.PocketID, transactRoles)
+	if !ok {
+		return
+	}
+
+	pocket, err := server.store.DeleteEmptyPocket(c, pocket.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusConflict, ErrPocketNotEmpty)
+			return
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pocket)
+}
+
+func pocketErrorResponse(c *gin.Context, err error, name string) {
+	if pgError, ok := err.(*pq.Error); ok {
+		switch pgError.Constraint {
+		case "account_pocket_name_key":
+			writeError(c, http.StatusForbidden, newAPIError(http.StatusConflict, CodeAlreadyExists, "pocket %s already exists", name))
+			return
+		}
+	}
+
+	switch {
+	case err == sql.ErrNoRows:
+		writeError(c, http.StatusNotFound, err)
+	case errors.Is(err, db.ErrInsufficientFunds):
+		writeError(c, http.StatusBadRequest, err)
+	default:
+		writeError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// getMemberPocket loads a pocket of the account and makes sure the caller has one of the roles
+// on the account, the error response is already written when it returns false
+func (server *Server) getMemberPocket(c *gin.Context, accountID int64, pocketID int64, roles []string) (db.Pocket, bool) {
+	account, ok := server.getMemberAccount(c, accountID, roles)
+	if !ok {
+		return db.Pocket{}, false
+	}
+
+	pocket, err := server.store.GetPocket(c, pocketID)
+	if err != nil {
+		pocketErrorResponse(c, err, "")
+		return db.Pocket{}, false
+	}
+	if pocket.AccountID != account.ID {
+		writeError(c, http.StatusNotFound, sql.ErrNoRows)
+		return db.Pocket{}, false
+	}
+
+	return pocket, true
+}
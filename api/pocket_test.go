@@ -0,0 +1,293 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lib/pq"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func randomPocket(account db.Account) db.Pocket {
+	goal := util.RandomInt(1000, 2000)
+	return db.Pocket{
+		ID:         util.RandomInt(1, 100),
+		AccountID:  account.ID,
+		Name:       util.RandomString(8),
+		Balance:    util.RandomInt(0, 1000),
+		GoalAmount: &goal,
+	}
+}
+
+func TestCreatePocket(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	pocket := randomPocket(account)
+	pocket.Balance = 0
+	viewer := randomAccountMember(account, util.AccountViewerRole)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          pocketRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body:     pocketRequest{Name: pocket.Name, GoalAmount: pocket.GoalAmount},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreatePocket(gomock.Any(), gomock.Eq(db.CreatePocketParams{
+						AccountID:  account.ID,
+						Name:       pocket.Name,
+						GoalAmount: pocket.GoalAmount,
+					})).
+					Times(1).
+					Return(pocket, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp db.Pocket
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, pocket, resp)
+			},
+		},
+		{
+			name:     "DuplicateName",
+			username: user.Username,
+			body:     pocketRequest{Name: pocket.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreatePocket(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Pocket{}, &pq.Error{Constraint: "account_pocket_name_key"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "ViewerCannotCreate",
+			username: viewer.Username,
+			body:     pocketRequest{Name: pocket.Name},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(viewer, nil)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidGoal",
+			username: user.Username,
+			body:     pocketRequest{Name: pocket.Name, GoalAmount: new(int64)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/pockets", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestMovePocket(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	pocket := randomPocket(account)
+
+	otherPocket := randomPocket(account)
+	otherPocket.AccountID = account.ID + 1
+
+	testCases := []struct {
+		name          string
+		action        string
+		pocket        db.Pocket
+		amount        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Deposit",
+			action: "deposit",
+			pocket: pocket,
+			amount: 50,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					MovePocketTx(gomock.Any(), gomock.Eq(db.MovePocketTxParams{PocketID: pocket.ID, Amount: 50})).
+					Times(1).
+					Return(db.MovePocketTxResult{Pocket: pocket, Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Withdraw",
+			action: "withdraw",
+			pocket: pocket,
+			amount: 50,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					MovePocketTx(gomock.Any(), gomock.Eq(db.MovePocketTxParams{PocketID: pocket.ID, Amount: -50})).
+					Times(1).
+					Return(db.MovePocketTxResult{Pocket: pocket, Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "InsufficientFunds",
+			action: "withdraw",
+			pocket: pocket,
+			amount: 5000,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					MovePocketTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MovePocketTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "PocketOfOtherAccount",
+			action: "deposit",
+			pocket: otherPocket,
+			amount: 50,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().MovePocketTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(tc.pocket.ID)).Times(1).Return(tc.pocket, nil)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(movePocketRequest{Amount: tc.amount})
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/pockets/%d/%s", account.ID, tc.pocket.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeletePocket(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	pocket := randomPocket(account)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteEmptyPocket(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotEmpty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteEmptyPocket(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(db.Pocket{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/pockets/%d", account.ID, pocket.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
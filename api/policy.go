@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+type setSpendingPolicyRequest struct {
+	Currency          string `json:"currency" binding:"required,currency"`
+	MinAmount         int64  `json:"min_amount" binding:"required,gt=0"`
+	RequiredApprovals int32  `json:"required_approvals" binding:"required,min=1,max=10"`
+}
+
+// setSpendingPolicy creates the policy for the currency and amount or changes how many approvals it requires
+func (server *Server) setSpendingPolicy(c *gin.Context) {
+	var uri organizationURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body setSpendingPolicyRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := server.getActingMember(c, uri.ID, adminRoles); !ok {
+		return
+	}
+
+	policy, err := server.store.SetSpendingPolicy(c, db.SetSpendingPolicyParams{
+		OrganizationID:    uri.ID,
+		Currency:          body.Currency,
+		MinAmount:         body.MinAmount,
+		RequiredApprovals: body.RequiredApprovals,
+	})
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (server *Server) listSpendingPolicies(c *gin.Context) {
+	var uri organizationURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := server.getActingMember(c, uri.ID, organizationRoles); !ok {
+		return
+	}
+
+	policies, err := server.store.ListSpendingPolicies(c, uri.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+type deleteSpendingPolicyURIRequest struct {
+	ID       int64 `uri:"id" binding:"required,min=1"`
+	PolicyID int64 `uri:"policy_id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteSpendingPolicy(c *gin.Context) {
+	var uri deleteSpendingPolicyURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := server.getActingMember(c, uri.ID, adminRoles); !ok {
+		return
+	}
+
+	policy, err := server.store.DeleteSpendingPolicy(c, db.DeleteSpendingPolicyParams{
+		ID:             uri.PolicyID,
+		OrganizationID: uri.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// transferApprovals is the policy engine for transfers out of the account, it returns how
+// many approvals a transfer of the amount needs before money moves, 0 when it moves at once.
+// The approval threshold of the currency asks for one approval, the spending policy of the
+// organization owning the account may ask for more.
+func (server *Server) transferApprovals(ctx context.Context, account db.Account, amount int64) (int32, error) {
+	var approvals int32
+
+	threshold, err := server.approvalThreshold(ctx, account.Currency)
+	if err != nil {
+		return 0, err
+	}
+	if threshold > 0 && amount >= threshold {
+		approvals = 1
+	}
+
+	if account.OrganizationID == nil {
+		return approvals, nil
+	}
+
+	policy, err := server.store.GetSpendingPolicy(ctx, db.GetSpendingPolicyParams{
+		OrganizationID: *account.OrganizationID,
+		Currency:       account.Currency,
+		Amount:         amount,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return approvals, nil
+		}
+		return 0, err
+	}
+	return max(approvals, policy.RequiredApprovals), nil
+}
+
+// approvalLimit returns the smallest amount a transfer out of the account needs approval for,
+// 0 when no transfer ever does
+func (server *Server) approvalLimit(ctx context.Context, account db.Account) (int64, error) {
+	limit, err := server.approvalThreshold(ctx, account.Currency)
+	if err != nil {
+		return 0, err
+	}
+
+	if account.OrganizationID == nil {
+		return limit, nil
+	}
+
+	policies, err := server.store.ListSpendingPolicies(ctx, *account.OrganizationID)
+	if err != nil {
+		return 0, err
+	}
+	for _, policy := range policies {
+		if policy.Currency == account.Currency && (limit == 0 || policy.MinAmount < limit) {
+			limit = policy.MinAmount
+		}
+	}
+	return limit, nil
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSetSpendingPolicy(t *testing.T) {
+	organizationID := util.RandomInt(1, 100)
+	admin := randomOrganizationMember(organizationID, util.OrganizationAdminRole)
+	initiator := randomOrganizationMember(organizationID, util.OrganizationInitiatorRole)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          setSpendingPolicyRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: admin.Username,
+			body:     setSpendingPolicyRequest{Currency: util.USD, MinAmount: 10000, RequiredApprovals: 2},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(admin, nil)
+				store.EXPECT().
+					SetSpendingPolicy(gomock.Any(), gomock.Eq(db.SetSpendingPolicyParams{
+						OrganizationID:    organizationID,
+						Currency:          util.USD,
+						MinAmount:         10000,
+						RequiredApprovals: 2,
+					})).
+					Times(1).
+					Return(db.SpendingPolicy{
+						ID:                util.RandomInt(1, 100),
+						OrganizationID:    organizationID,
+						Currency:          util.USD,
+						MinAmount:         10000,
+						RequiredApprovals: 2,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InitiatorCannotSet",
+			username: initiator.Username,
+			body:     setSpendingPolicyRequest{Currency: util.USD, MinAmount: 10000, RequiredApprovals: 2},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(initiator, nil)
+				store.EXPECT().SetSpendingPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "TooManyApprovals",
+			username: admin.Username,
+			body:     setSpendingPolicyRequest{Currency: util.USD, MinAmount: 10000, RequiredApprovals: 11},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetSpendingPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/organizations/%d/policies", organizationID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addOrganizationAuthorization(t, request, server.tokenMaker, tc.username, organizationID)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateOrganizationTransfer(t *testing.T) {
+	organizationID := util.RandomInt(1, 100)
+	admin := randomOrganizationMember(organizationID, util.OrganizationAdminRole)
+	initiator := randomOrganizationMember(organizationID, util.OrganizationInitiatorRole)
+	approver := randomOrganizationMember(organizationID, util.OrganizationApproverRole)
+
+	fromAccount := randomAccount(admin.Username)
+	fromAccount.Currency = util.USD
+	fromAccount.AvailableBalance = 100000
+	fromAccount.OrganizationID = &organizationID
+
+	toAccount := randomAccount(util.RandomString(6))
+	toAccount.ID = fromAccount.ID + 1
+	toAccount.Currency = util.USD
+
+	policy := db.SpendingPolicy{
+		OrganizationID:    organizationID,
+		Currency:          util.USD,
+		MinAmount:         10000,
+		RequiredApprovals: 2,
+	}
+
+	// the stubs every transfer from the organization account goes through before the policy engine
+	buildAccountStubs := func(store *mockdb.MockStore, member db.OrganizationMember) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+		store.EXPECT().
+			GetOrganizationMember(gomock.Any(), gomock.Eq(db.GetOrganizationMemberParams{
+				OrganizationID: organizationID,
+				Username:       member.Username,
+			})).
+			Times(1).
+			Return(member, nil)
+	}
+
+	testCases := []struct {
+		name           string
+		username       string
+		organizationID int64
+		amount         int64
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "PolicyRequiresApprovals",
+			username:       initiator.Username,
+			organizationID: organizationID,
+			amount:         20000,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAccountStubs(store, initiator)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetApprovalThreshold(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetSpendingPolicy(gomock.Any(), gomock.Eq(db.GetSpendingPolicyParams{
+						OrganizationID: organizationID,
+						Currency:       util.USD,
+						Amount:         20000,
+					})).
+					Times(1).
+					Return(policy, nil)
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreatePendingTransferParams) (db.Transfer, error) {
+						assert.Equal(t, initiator.Username, *arg.InitiatedBy)
+						assert.Equal(t, policy.RequiredApprovals, arg.RequiredApprovals)
+
+						return db.Transfer{
+							ID:                util.RandomInt(1, 100),
+							FromAccountID:     arg.FromAccountID,
+							ToAccountID:       arg.ToAccountID,
+							Amount:            arg.Amount,
+							Status:            db.TransferStatusPending,
+							InitiatedBy:       arg.InitiatedBy,
+							RequiredApprovals: arg.RequiredApprovals,
+						}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var transfer db.Transfer
+				err = json.Unmarshal(data, &transfer)
+				assert.NoError(t, err)
+				assert.Equal(t, db.TransferStatusPending, transfer.Status)
+				assert.Equal(t, int32(2), transfer.RequiredApprovals)
+			},
+		},
+		{
+			name:           "BelowPolicy",
+			username:       initiator.Username,
+			organizationID: organizationID,
+			amount:         500,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAccountStubs(store, initiator)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetApprovalThreshold(gomock.Any(), gomock.Any()).Times(1).Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+				store.EXPECT().GetSpendingPolicy(gomock.Any(), gomock.Any()).Times(1).Return(db.SpendingPolicy{}, sql.ErrNoRows)
+				store.EXPECT().CreatePendingTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:           "ApproverCannotInitiate",
+			username:       approver.Username,
+			organizationID: organizationID,
+			amount:         500,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildAccountStubs(store, approver)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:           "NotActingForOrganization",
+			username:       initiator.Username,
+			organizationID: 0,
+			amount:         500,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(transferRequest{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        tc.amount,
+				Currency:      util.USD,
+			})
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addOrganizationAuthorization(t, request, server.tokenMaker, tc.username, tc.organizationID)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApproveOrganizationTransfer(t *testing.T) {
+	organizationID := util.RandomInt(1, 100)
+	initiator := randomOrganizationMember(organizationID, util.OrganizationInitiatorRole)
+	approver := randomOrganizationMember(organizationID, util.OrganizationApproverRole)
+
+	account := randomAccount(util.RandomString(6))
+	account.OrganizationID = &organizationID
+
+	transfer := randomPendingTransfer(account)
+	transfer.InitiatedBy = &initiator.Username
+	transfer.RequiredApprovals = 2
+
+	testCases := []struct {
+		name          string
+		member        db.OrganizationMember
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "FirstOfTwoApprovals",
+			member: approver,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransfer(gomock.Any(), gomock.Eq(db.ApproveTransferParams{
+						TransferID: transfer.ID,
+						ApprovedBy: approver.Username,
+					})).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:   "InitiatorIsNotApprover",
+			member: initiator,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "CannotApproveOwnTransfer",
+			member: db.OrganizationMember{OrganizationID: organizationID, Username: initiator.Username, Role: util.OrganizationAdminRole},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyApproved",
+			member: approver,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrTransferAlreadyApproved)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(tc.member, nil)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d/approve", transfer.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			assert.NoError(t, err)
+
+			addOrganizationAuthorization(t, request, server.tokenMaker, tc.member.Username, organizationID)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
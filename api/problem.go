@@ -0,0 +1,338 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+)
+
+const (
+	apiVersionPrefix   = "/v1"
+	problemContentType = "application/problem+json"
+	// legacyRouteKey marks the requests to the unversioned routes
+	legacyRouteKey = "legacy_route"
+)
+
+// The codes of the problem documents, clients may rely on them while the detail is for people
+const (
+	CodeBadRequest           = "BAD_REQUEST"
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeUnauthenticated      = "UNAUTHENTICATED"
+	CodeTokenExpired         = "TOKEN_EXPIRED"
+	CodePermissionDenied     = "PERMISSION_DENIED"
+	CodeNotActingForOrg      = "NOT_ACTING_FOR_ORGANIZATION"
+	CodeNotFound             = "NOT_FOUND"
+	CodeUserNotFound         = "USER_NOT_FOUND"
+	CodeRecipientNotFound    = "RECIPIENT_NOT_FOUND"
+	CodeAlreadyExists        = "ALREADY_EXISTS"
+	CodeConflict             = "CONFLICT"
+	CodeConcurrentUpdate     = "CONCURRENT_UPDATE"
+	CodeInsufficientFunds    = "INSUFFICIENT_FUNDS"
+	CodeCurrencyMismatch     = "CURRENCY_MISMATCH"
+	CodeSameAccount          = "SAME_ACCOUNT"
+	CodePocketNotEmpty       = "POCKET_NOT_EMPTY"
+	CodeTransferNotPending   = "TRANSFER_NOT_PENDING"
+	CodeTransferExpired      = "TRANSFER_EXPIRED"
+	CodeAlreadyApproved      = "TRANSFER_ALREADY_APPROVED"
+	CodeHoldNotPending       = "HOLD_NOT_PENDING"
+	CodeHoldExpired          = "HOLD_EXPIRED"
+	CodeConstraintViolation  = "CONSTRAINT_VIOLATION"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUnavailable          = "UNAVAILABLE"
+	CodeInternal             = "INTERNAL"
+)
+
+// problem is an RFC 7807 problem document, the error response of the /v1 routes
+type problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []fieldError `json:"errors,omitempty"`
+}
+
+// fieldError tells what is wrong with a field of the request
+type fieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// apiError is an error of a handler with the code and the status it has on /v1
+type apiError struct {
+	status  int
+	code    string
+	message string
+	fields  []fieldError
+}
+
+func (err *apiError) Error() string {
+	return err.message
+}
+
+func newAPIError(status int, code string, format string, args ...any) *apiError {
+	return &apiError{status: status, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// knownErrors are the sentinel errors with a code of their own
+var knownErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{ErrTokenIsNotProvided, http.StatusUnauthorized, CodeUnauthenticated},
+	{ErrTokenIsInvalid, http.StatusUnauthorized, CodeUnauthenticated},
+	{ErrUnsupportedAuthType, http.StatusUnauthorized, CodeUnauthenticated},
+	{token.ErrInvalidToken, http.StatusUnauthorized, CodeUnauthenticated},
+	{token.ErrExpiredToken, http.StatusUnauthorized, CodeTokenExpired},
+	{ErrPermissionDenied, http.StatusForbidden, CodePermissionDenied},
+	{ErrNotActingForOrganization, http.StatusForbidden, CodeNotActingForOrg},
+	{ErrRecipientNotFound, http.StatusNotFound, CodeRecipientNotFound},
+	{ErrPocketNotEmpty, http.StatusConflict, CodePocketNotEmpty},
+	{ErrAccountEventsUnavailable, http.StatusServiceUnavailable, CodeUnavailable},
+	{db.ErrInsufficientFunds, http.StatusUnprocessableEntity, CodeInsufficientFunds},
+	{db.ErrAmountOverflow, http.StatusUnprocessableEntity, CodeConstraintViolation},
+	{db.ErrTransferNotPending, http.StatusConflict, CodeTransferNotPending},
+	{db.ErrTransferExpired, http.StatusConflict, CodeTransferExpired},
+	{db.ErrTransferAlreadyApproved, http.StatusConflict, CodeAlreadyApproved},
+	{db.ErrHoldNotPending, http.StatusConflict, CodeHoldNotPending},
+	{db.ErrHoldExpired, http.StatusConflict, CodeHoldExpired},
+	{ErrInvalidCursor, http.StatusBadRequest, CodeBadRequest},
+	{ErrInvalidID, http.StatusBadRequest, CodeBadRequest},
+	{sql.ErrNoRows, http.StatusNotFound, CodeNotFound},
+}
+
+// statusCodes are the codes of errors without one, by the status the handler chose
+var statusCodes = map[int]string{
+	http.StatusBadRequest:           CodeBadRequest,
+	http.StatusUnauthorized:         CodeUnauthenticated,
+	http.StatusForbidden:            CodePermissionDenied,
+	http.StatusNotFound:             CodeNotFound,
+	http.StatusConflict:             CodeConflict,
+	http.StatusUnsupportedMediaType: CodeUnsupportedMediaType,
+	http.StatusUnprocessableEntity:  CodeConstraintViolation,
+	http.StatusServiceUnavailable:   CodeUnavailable,
+}
+
+// writeError responds with the error. The /v1 routes get a problem document whose status
+// may be refined from the error, e.g. a unique violation is 409 even if the handler only
+// knew it failed. The unversioned routes keep the status of the handler and their
+// {"error": detail} body so existing clients don't break.
+func writeError(c *gin.Context, status int, err error) {
+	p := newProblem(c, status, err)
+	if p.Status >= http.StatusInternalServerError {
+		// the client only sees the code, the error goes to the log of the request
+		c.Error(err)
+	}
+
+	if c.GetBool(legacyRouteKey) {
+		resp := gin.H{"error": p.Detail}
+		var rowsErr *batchRowsError
+		if errors.As(err, &rowsErr) {
+			resp["rows"] = rowsErr.rows
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.JSON(p.Status, p)
+}
+
+func abortWithError(c *gin.Context, status int, err error) {
+	writeError(c, status, err)
+	c.Abort()
+}
+
+// newProblem maps the error to its code, the detail never holds messages of the
+// database or the validator, those stay in the log
+func newProblem(c *gin.Context, status int, err error) problem {
+	p := resolveProblem(status, err)
+	p.Instance = c.Request.URL.Path
+	p.RequestID = c.Writer.Header().Get(requestIDHeaderKey)
+	return p
+}
+
+// resolveProblem is the part of newProblem which doesn't depend on the request,
+// the gRPC services map their errors with it too
+func resolveProblem(status int, err error) problem {
+	p := problem{
+		Type:   "about:blank",
+		Status: status,
+		Code:   statusCodes[status],
+		Detail: err.Error(),
+	}
+
+	var (
+		apiErr           *apiError
+		rowsErr          *batchRowsError
+		validationErrs   validator.ValidationErrors
+		syntaxErr        *json.SyntaxError
+		unmarshalTypeErr *json.UnmarshalTypeError
+		numErr           *strconv.NumError
+		pqErr            *pq.Error
+	)
+	switch {
+	case errors.As(err, &apiErr):
+		p.Status, p.Code, p.Errors = apiErr.status, apiErr.code, apiErr.fields
+	case errors.As(err, &rowsErr):
+		p.Status, p.Code = http.StatusBadRequest, CodeValidationFailed
+		for _, row := range rowsErr.rows {
+			p.Errors = append(p.Errors, fieldError{
+				Field:   fmt.Sprintf("rows[%d]", row.Row),
+				Rule:    "row",
+				Message: row.Error,
+			})
+		}
+	case errors.As(err, &validationErrs):
+		p.Status, p.Code = http.StatusBadRequest, CodeValidationFailed
+		messages := make([]string, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			field := fieldError{Field: fe.Field(), Rule: fe.Tag(), Message: validationMessage(fe)}
+			p.Errors = append(p.Errors, field)
+			messages = append(messages, field.Field+" "+field.Message)
+		}
+		p.Detail = strings.Join(messages, ", ")
+	case errors.As(err, &unmarshalTypeErr):
+		p.Status, p.Code = http.StatusBadRequest, CodeValidationFailed
+		field := fieldError{Field: unmarshalTypeErr.Field, Rule: "type", Message: "must be " + jsonTypeName(unmarshalTypeErr.Type)}
+		p.Errors = []fieldError{field}
+		p.Detail = field.Field + " " + field.Message
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		p.Status, p.Code, p.Detail = http.StatusBadRequest, CodeBadRequest, "the request body is not valid JSON"
+	case errors.Is(err, io.EOF):
+		p.Status, p.Code, p.Detail = http.StatusBadRequest, CodeBadRequest, "the request body is empty"
+	case errors.As(err, &numErr):
+		p.Status, p.Code, p.Detail = http.StatusBadRequest, CodeBadRequest, fmt.Sprintf("%q is not a valid number", numErr.Num)
+	case errors.As(err, &pqErr):
+		p.Status, p.Code, p.Detail = postgresProblem(pqErr)
+	default:
+		for _, known := range knownErrors {
+			if errors.Is(err, known.err) {
+				p.Status, p.Code = known.status, known.code
+				break
+			}
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			p.Detail = "the resource was not found"
+		}
+	}
+
+	if p.Status >= http.StatusInternalServerError && p.Code != CodeUnavailable {
+		p.Code, p.Detail = CodeInternal, "internal server error"
+	}
+	if p.Code == "" {
+		p.Code = CodeBadRequest
+	}
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+
+// postgresProblem maps the class of the error, the constraints a handler
+// knows about are mapped by the handler with a better detail
+func postgresProblem(err *pq.Error) (int, string, string) {
+	switch err.Code.Name() {
+	case "unique_violation":
+		return http.StatusConflict, CodeAlreadyExists, "the resource already exists"
+	case "foreign_key_violation":
+		return http.StatusUnprocessableEntity, CodeConstraintViolation, "the resource refers to a resource which doesn't exist"
+	case "check_violation", "not_null_violation":
+		return http.StatusUnprocessableEntity, CodeConstraintViolation, "the request violates a constraint"
+	case "serialization_failure", "deadlock_detected":
+		return http.StatusConflict, CodeConcurrentUpdate, "the resource was changed at the same time, try again"
+	}
+	return http.StatusInternalServerError, CodeInternal, "internal server error"
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s items or characters", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at most %s items or characters", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "email":
+		return "must be an email address"
+	case "http_url":
+		return "must be an http or https URL"
+	case "public_url":
+		return "must point to a public address"
+	case "alphanum":
+		return "must only have letters and digits"
+	case "currency":
+		return "must be a supported currency"
+	case "account_number":
+		return "must be an account number"
+	}
+	if enum, ok := openAPIEnums[fe.Tag()]; ok {
+		return "must be one of " + strings.Join(enum, ", ")
+	}
+	return "is not valid"
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+// fieldName names the fields in validation errors the way the client sends them
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// legacyRouteMiddleware marks the unversioned routes as deprecated in favor of /v1
+func legacyRouteMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(legacyRouteKey, true)
+		ctx.Header("Deprecation", "true")
+		ctx.Header("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", apiVersionPrefix, ctx.Request.URL.Path))
+		ctx.Next()
+	}
+}
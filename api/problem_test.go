@@ -0,0 +1,251 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProblemResponses(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.ID = util.RandomInt(1, 1000)
+
+	spec := openAPIDocument(t)
+	problemSchema := map[string]any{"$ref": "#/components/schemas/Problem"}
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, resp problem)
+	}{
+		{
+			name:   "ValidationFailed",
+			method: http.MethodPost,
+			url:    "/v1/users",
+			body:   gin.H{"username": "a!", "full_name": user.FullName, "email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, resp problem) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+				assert.Equal(t, CodeValidationFailed, resp.Code)
+				assert.ElementsMatch(t, []fieldError{
+					{Field: "username", Rule: "alphanum", Message: "must only have letters and digits"},
+					{Field: "password", Rule: "required", Message: "is required"},
+				}, resp.Errors)
+			},
+		},
+		{
+			name:   "AlreadyExists",
+			method: http.MethodPost,
+			url:    "/v1/users",
+			body:   gin.H{"username": user.Username, "password": "secret", "full_name": user.FullName, "email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &pq.Error{Code: "23505", Constraint: "users_pkey"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, resp problem) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+				assert.Equal(t, CodeAlreadyExists, resp.Code)
+				assert.Equal(t, "username already exists", resp.Detail)
+			},
+		},
+		{
+			name:   "NotFound",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/v1/accounts/%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, resp problem) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+				assert.Equal(t, CodeNotFound, resp.Code)
+				assert.Equal(t, fmt.Sprintf("/v1/accounts/%d", account.ID), resp.Instance)
+			},
+		},
+		{
+			name:   "InternalError",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/v1/accounts/%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "XX000", Message: "relation accounts is broken"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, resp problem) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+				assert.Equal(t, CodeInternal, resp.Code)
+				assert.NotContains(t, resp.Detail, "relation")
+			},
+		},
+		{
+			name:   "RouteNotFound",
+			method: http.MethodGet,
+			url:    "/v1/unknown",
+			buildStubs: func(store *mockdb.MockStore) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, resp problem) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+				assert.Equal(t, CodeNotFound, resp.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+
+			var decoded any
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+			assert.NoError(t, validateSchema(spec, problemSchema, decoded, "body"))
+
+			var resp problem
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+			assert.Equal(t, recorder.Code, resp.Status)
+			assert.Equal(t, http.StatusText(recorder.Code), resp.Title)
+			tc.checkResponse(t, recorder, resp)
+		})
+	}
+}
+
+func TestLegacyRouteError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := randomUser(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateUser(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, &pq.Error{Code: "23505", Constraint: "users_email_key"})
+
+	server := newServerTest(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"username": user.Username, "password": "secret", "full_name": user.FullName, "email": user.Email})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/users", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+
+	// the unversioned routes keep their status and error body
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, "true", recorder.Header().Get("Deprecation"))
+	assert.Equal(t, `</v1/users>; rel="successor-version"`, recorder.Header().Get("Link"))
+
+	var resp gin.H
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.Equal(t, gin.H{"error": "email already exists"}, resp)
+}
+
+func TestNewProblem(t *testing.T) {
+	account := randomAccount(util.RandomString(6))
+	account.Currency = util.USD
+	account.AvailableBalance = 10
+	account.OverdraftLimit = 0
+
+	testCases := []struct {
+		name   string
+		status int
+		err    error
+		want   problem
+	}{
+		{
+			name:   "InsufficientFunds",
+			status: http.StatusBadRequest,
+			err:    checkBalance(account, 20),
+			want:   problem{Status: http.StatusUnprocessableEntity, Code: CodeInsufficientFunds},
+		},
+		{
+			name:   "CurrencyMismatch",
+			status: http.StatusBadRequest,
+			err:    checkCurrency(account, util.EUR),
+			want:   problem{Status: http.StatusUnprocessableEntity, Code: CodeCurrencyMismatch},
+		},
+		{
+			name:   "KnownError",
+			status: http.StatusBadRequest,
+			err:    fmt.Errorf("cannot create transfer: %w", db.ErrTransferExpired),
+			want:   problem{Status: http.StatusConflict, Code: CodeTransferExpired},
+		},
+		{
+			name:   "ForeignKeyViolation",
+			status: http.StatusInternalServerError,
+			err:    &pq.Error{Code: "23503", Message: "insert violates fk_accounts_owner"},
+			want:   problem{Status: http.StatusUnprocessableEntity, Code: CodeConstraintViolation},
+		},
+		{
+			name:   "InvalidJSON",
+			status: http.StatusBadRequest,
+			err:    json.Unmarshal([]byte(`{"amount":`), &gin.H{}),
+			want:   problem{Status: http.StatusBadRequest, Code: CodeBadRequest},
+		},
+		{
+			name:   "StatusOfHandler",
+			status: http.StatusBadRequest,
+			err:    fmt.Errorf("exactly one of to_account_id, to_account_number or recipient is required"),
+			want:   problem{Status: http.StatusBadRequest, Code: CodeBadRequest},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Error(t, tc.err)
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/transfers", nil)
+
+			p := newProblem(c, tc.status, tc.err)
+			assert.Equal(t, tc.want.Status, p.Status)
+			assert.Equal(t, tc.want.Code, p.Code)
+			assert.NotContains(t, p.Detail, "fk_accounts_owner")
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+var ErrRecipientNotFound = errors.New("recipient not found")
+
+type lookupRecipientRequest struct {
+	Recipient string `form:"recipient" binding:"required,max=254"`
+	Currency  string `form:"currency" binding:"required,currency"`
+}
+
+type recipientResponse struct {
+	DisplayName string `json:"display_name"`
+	Currency    string `json:"currency"`
+}
+
+func (server *Server) lookupRecipient(c *gin.Context) {
+	var query lookupRecipientRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, account, ok := server.resolveRecipient(c, query.Recipient, query.Currency)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, recipientResponse{
+		DisplayName: maskName(user.FullName),
+		Currency:    account.Currency,
+	})
+}
+
+// resolveRecipient finds the checking account in the currency for a username or email,
+// the error response is already written when it returns false
+func (server *Server) resolveRecipient(c *gin.Context, recipient string, currency string) (db.User, db.Account, bool) {
+	user, account, err := server.findRecipient(c, recipient, currency)
+	if err != nil {
+		if errors.Is(err, ErrRecipientNotFound) {
+			writeError(c, http.StatusNotFound, err)
+			return db.User{}, db.Account{}, false
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return db.User{}, db.Account{}, false
+	}
+	return user, account, true
+}
+
+// findRecipient returns ErrRecipientNotFound both for a missing user and for a user
+// without a checking account in the currency, they look the same to the caller
+func (server *Server) findRecipient(ctx context.Context, recipient string, currency string) (db.User, db.Account, error) {
+	user, err := server.store.GetUserByUsernameOrEmail(ctx, recipient)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.User{}, db.Account{}, ErrRecipientNotFound
+		}
+		return db.User{}, db.Account{}, err
+	}
+
+	account, err := server.store.GetAccountByOwnerAndCurrency(ctx, db.GetAccountByOwnerAndCurrencyParams{
+		Owner:       user.Username,
+		Currency:    currency,
+		AccountType: util.CheckingAccount,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.User{}, db.Account{}, ErrRecipientNotFound
+		}
+		return db.User{}, db.Account{}, err
+	}
+
+	return user, account, nil
+}
+
+// maskName keeps the first letter of every word, e.g. "John Doe" becomes "J*** D**"
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
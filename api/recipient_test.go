@@ -0,0 +1,154 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLookupRecipient(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	recipient.FullName = "John Doe"
+	account := randomAccount(recipient.Username)
+	account.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		recipient     string
+		currency      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			recipient: recipient.Email,
+			currency:  util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsernameOrEmail(gomock.Any(), gomock.Eq(recipient.Email)).
+					Times(1).
+					Return(recipient, nil)
+
+				store.EXPECT().
+					GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerAndCurrencyParams{
+						Owner:       recipient.Username,
+						Currency:    util.USD,
+						AccountType: util.CheckingAccount,
+					})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp recipientResponse
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, recipientResponse{DisplayName: "J*** D**", Currency: util.USD}, resp)
+				assert.NotContains(t, string(data), recipient.Username)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			recipient: "nobody",
+			currency:  util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsernameOrEmail(gomock.Any(), gomock.Eq("nobody")).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAccountInCurrency",
+			recipient: recipient.Username,
+			currency:  util.EUR,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsernameOrEmail(gomock.Any(), gomock.Eq(recipient.Username)).
+					Times(1).
+					Return(recipient, nil)
+				store.EXPECT().
+					GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			recipient: recipient.Username,
+			currency:  util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsernameOrEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidCurrency",
+			recipient: recipient.Username,
+			currency:  "XXX",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsernameOrEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			query := url.Values{"recipient": {tc.recipient}, "currency": {tc.currency}}
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/recipients?%s", query.Encode()), nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, sender.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestMaskName(t *testing.T) {
+	assert.Equal(t, "J*** D**", maskName("John Doe"))
+	assert.Equal(t, "A", maskName("A"))
+	assert.Equal(t, "É****", maskName("Élise"))
+	assert.Equal(t, "M**** S****", maskName("  Maria   Silva "))
+	assert.Equal(t, "", maskName(""))
+}
@@ -1,51 +1,188 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/novalyezu/simplebank-backend/config"
 	db "github.com/novalyezu/simplebank-backend/db/sqlc"
 	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/tracing"
+	"github.com/novalyezu/simplebank-backend/util"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
-	store      db.Store
-	tokenMaker token.Maker
-	router     *gin.Engine
+	config        config.Config
+	store         db.Store
+	tokenMaker    token.Maker
+	accountEvents AccountEventSubscriber
+	graphQL       *graphql.Schema
+	logger        *slog.Logger
+	router        *gin.Engine
+	// shuttingDown fails readyz once the server drains its requests
+	shuttingDown atomic.Bool
 }
 
-func NewServer(store db.Store, tokenMaker token.Maker) *Server {
-	server := &Server{store: store, tokenMaker: tokenMaker}
+// readHeaderTimeout bounds the time a client may take to send the headers of a request
+const readHeaderTimeout = 10 * time.Second
+
+// NewServer creates the server, without accountEvents the event stream responds with 503.
+// Every request is logged to logger.
+func NewServer(config config.Config, store db.Store, tokenMaker token.Maker, accountEvents AccountEventSubscriber, logger *slog.Logger) *Server {
+	server := &Server{config: config, store: store, tokenMaker: tokenMaker, accountEvents: accountEvents, logger: logger}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("account_type", validAccountType)
+		v.RegisterValidation("account_role", validAccountRole)
+		v.RegisterValidation("organization_role", validOrganizationRole)
+		v.RegisterValidation("account_number", validAccountNumber)
+		v.RegisterValidation("webhook_event", validWebhookEvent)
+		v.RegisterValidation("public_url", validPublicURL)
+		v.RegisterTagNameFunc(fieldName)
 	}
 
+	server.graphQL = newGraphQLSchema(server)
 	server.setupRouter()
 	return server
 }
 
 func (server *Server) setupRouter() {
-	router := gin.Default()
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(
+		otelgin.Middleware(tracing.ServiceName),
+		requestIDMiddleware(),
+		loggerMiddleware(server.logger),
+		metricsMiddleware(),
+		// the panic and its stack go to the log of the request
+		gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+			abortWithError(c, http.StatusInternalServerError, fmt.Errorf("panic: %v\n%s", recovered, debug.Stack()))
+		}),
+		auditMiddleware(),
+	)
+
+	router.GET("/openapi.json", server.serveOpenAPI)
+	router.GET("/swagger", server.serveSwaggerUI)
+	router.GET("/healthz", server.healthz)
+	router.GET("/readyz", server.readyz)
+
+	server.setupRoutes(router.Group(apiVersionPrefix))
+	// the unversioned routes stay for the clients which haven't moved to /v1 yet
+	server.setupRoutes(router.Group("/", legacyRouteMiddleware()))
+
+	router.NoRoute(func(c *gin.Context) {
+		writeError(c, http.StatusNotFound, errors.New("route not found"))
+	})
 
+	server.router = router
+}
+
+func (server *Server) setupRoutes(router *gin.RouterGroup) {
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
 
 	authenticated := router.Group("/", authMiddleware(server.tokenMaker))
 
 	authenticated.GET("/accounts", server.listAccount)
+	authenticated.GET("/accounts/events", server.streamAccountEvents)
 	authenticated.GET("/accounts/:id", server.getAccount)
 	authenticated.POST("/accounts", server.createAccount)
+	authenticated.GET("/accounts/:id/members", server.listAccountMembers)
+	authenticated.POST("/accounts/:id/members", server.inviteAccountMember)
+	authenticated.DELETE("/accounts/:id/members/:username", server.removeAccountMember)
+	authenticated.GET("/accounts/:id/pockets", server.listPockets)
+	authenticated.POST("/accounts/:id/pockets", server.createPocket)
+	authenticated.PUT("/accounts/:id/pockets/:pocket_id", server.updatePocket)
+	authenticated.DELETE("/accounts/:id/pockets/:pocket_id", server.deletePocket)
+	authenticated.POST("/accounts/:id/pockets/:pocket_id/deposit", server.depositPocket)
+	authenticated.POST("/accounts/:id/pockets/:pocket_id/withdraw", server.withdrawPocket)
+	authenticated.GET("/accounts/:id/webhooks", server.listWebhooks)
+	authenticated.POST("/accounts/:id/webhooks", server.createWebhook)
+	authenticated.DELETE("/accounts/:id/webhooks/:webhook_id", server.deleteWebhook)
+	authenticated.GET("/accounts/:id/webhooks/:webhook_id/deliveries", server.listWebhookDeliveries)
+	authenticated.GET("/accounts/:id/webhooks/:webhook_id/deliveries/:delivery_id", server.getWebhookDelivery)
+	authenticated.POST("/accounts/:id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", server.redeliverWebhook)
 
+	authenticated.POST("/organizations", server.createOrganization)
+	authenticated.GET("/organizations", server.listOrganizations)
+	authenticated.GET("/organizations/:id/members", server.listOrganizationMembers)
+	authenticated.POST("/organizations/:id/members", server.addOrganizationMember)
+	authenticated.DELETE("/organizations/:id/members/:username", server.removeOrganizationMember)
+	authenticated.GET("/organizations/:id/accounts", server.listOrganizationAccounts)
+	authenticated.POST("/organizations/:id/accounts", server.createOrganizationAccount)
+	authenticated.GET("/organizations/:id/policies", server.listSpendingPolicies)
+	authenticated.PUT("/organizations/:id/policies", server.setSpendingPolicy)
+	authenticated.DELETE("/organizations/:id/policies/:policy_id", server.deleteSpendingPolicy)
+
+	authenticated.GET("/recipients", server.lookupRecipient)
+	authenticated.GET("/transfers", server.listTransfers)
 	authenticated.POST("/transfers", server.createTransfer)
+	authenticated.GET("/transfers/fee", server.previewTransferFee)
+	authenticated.POST("/transfers/:id/approve", server.approveTransfer)
+	authenticated.POST("/transfers/:id/reject", server.rejectTransfer)
+	authenticated.POST("/transfers/batch", server.createTransferBatch)
+	authenticated.GET("/transfers/batch/:id", server.getTransferBatch)
 
-	server.router = router
+	authenticated.POST("/holds", server.placeHold)
+	authenticated.GET("/holds/:id", server.getHold)
+	authenticated.POST("/holds/:id/capture", server.captureHold)
+	authenticated.POST("/holds/:id/release", server.releaseHold)
+
+	authenticated.POST("/graphql", server.serveGraphQL)
+
+	banker := authenticated.Group("/banker", roleMiddleware(util.BankerRole))
+
+	banker.GET("/overdrafts", server.listOverdrafts)
+	banker.PUT("/accounts/:id/overdraft", server.updateOverdraft)
+	banker.GET("/audit-events", server.listAuditEvents)
+	banker.GET("/audit-events/verify", server.verifyAuditChain)
 }
 
-func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+// Start serves HTTP on address until ctx is done, see Serve
+func (server *Server) Start(ctx context.Context, address string, drainTimeout time.Duration) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return server.Serve(ctx, listener, drainTimeout)
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// Serve serves HTTP on listener until ctx is done. It then stops accepting connections,
+// fails readyz and waits up to drainTimeout for the requests in flight, e.g. transfers.
+// The event streams end when the broker they subscribe to stops.
+func (server *Server) Serve(ctx context.Context, listener net.Listener, drainTimeout time.Duration) error {
+	httpServer := &http.Server{
+		Handler:           server.router,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	server.shuttingDown.Store(true)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
 }
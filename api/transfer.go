@@ -1,20 +1,37 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/novalyezu/simplebank-backend/db/sqlc"
 	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+const (
+	maxMetadataSize = 4096
+	maxMetadataKeys = 32
 )
 
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID     int64           `json:"from_account_id" binding:"omitempty,min=1"`
+	FromAccountNumber string          `json:"from_account_number" binding:"omitempty,account_number"`
+	ToAccountID       int64           `json:"to_account_id" binding:"omitempty,min=1"`
+	ToAccountNumber   string          `json:"to_account_number" binding:"omitempty,account_number"`
+	Recipient         string          `json:"recipient" binding:"omitempty,max=254"`
+	Amount            int64           `json:"amount" binding:"required,gt=0,max=1000000000000000"`
+	Currency          string          `json:"currency" binding:"required,currency"`
+	Description       string          `json:"description" binding:"max=140"`
+	Reference         string          `json:"reference" binding:"max=64"`
+	Metadata          json.RawMessage `json:"metadata"`
 }
 
 func (server *Server) createTransfer(c *gin.Context) {
@@ -22,74 +39,349 @@ func (server *Server) createTransfer(c *gin.Context) {
 
 	err := c.ShouldBindJSON(&body)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	if body.FromAccountID == body.ToAccountID {
-		c.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("cannot transfer to same account")))
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	outcome, status, err := server.executeTransfer(c, authPayload, body)
+	if err != nil {
+		writeError(c, status, err)
+		return
+	}
+	if outcome.Pending != nil {
+		c.JSON(http.StatusAccepted, outcome.Pending)
 		return
 	}
 
-	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
-	fromAccount := server.getAccountByID(c, body.FromAccountID)
-	toAccount := server.getAccountByID(c, body.ToAccountID)
+	c.JSON(http.StatusOK, outcome.Result)
+}
+
+// transferOutcome is either the pending transfer waiting for approval or the moved money,
+// with the account numbers of both sides for the responses which leave the ids out
+type transferOutcome struct {
+	Pending           *db.Transfer
+	Result            db.TransferTxResult
+	FromAccountNumber string
+	ToAccountNumber   string
+}
+
+// executeTransfer holds the rules of a transfer for both the HTTP and the gRPC
+// handler. The status is the one of the unversioned routes, like writeError takes it.
+func (server *Server) executeTransfer(ctx context.Context, authPayload *token.Payload, body transferRequest) (transferOutcome, int, error) {
+	destinations := 0
+	for _, set := range []bool{body.ToAccountID != 0, body.ToAccountNumber != "", body.Recipient != ""} {
+		if set {
+			destinations++
+		}
+	}
+	if destinations != 1 {
+		return transferOutcome{}, http.StatusBadRequest, fmt.Errorf("exactly one of to_account_id, to_account_number or recipient is required")
+	}
+	if (body.FromAccountID != 0) == (body.FromAccountNumber != "") {
+		return transferOutcome{}, http.StatusBadRequest, fmt.Errorf("exactly one of from_account_id or from_account_number is required")
+	}
+
+	if body.FromAccountID != 0 && body.FromAccountID == body.ToAccountID {
+		return transferOutcome{}, http.StatusBadRequest, newAPIError(http.StatusBadRequest, CodeSameAccount, "cannot transfer to same account")
+	}
+
+	if err := validateMetadata(body.Metadata); err != nil {
+		return transferOutcome{}, http.StatusBadRequest, err
+	}
+
+	var fromAccount db.Account
+	var err error
+	if body.FromAccountNumber != "" {
+		fromAccount, err = server.store.GetAccountByNumber(ctx, body.FromAccountNumber)
+	} else {
+		fromAccount, err = server.store.GetAccount(ctx, body.FromAccountID)
+	}
+	if err != nil {
+		return transferOutcome{}, storeErrorStatus(err), err
+	}
+
+	var toAccount db.Account
+	switch {
+	case body.Recipient != "":
+		_, toAccount, err = server.findRecipient(ctx, body.Recipient, body.Currency)
+	case body.ToAccountNumber != "":
+		toAccount, err = server.store.GetAccountByNumber(ctx, body.ToAccountNumber)
+	default:
+		toAccount, err = server.store.GetAccount(ctx, body.ToAccountID)
+	}
+	if err != nil {
+		return transferOutcome{}, storeErrorStatus(err), err
+	}
+	if toAccount.ID == fromAccount.ID {
+		return transferOutcome{}, http.StatusBadRequest, newAPIError(http.StatusBadRequest, CodeSameAccount, "cannot transfer to same account")
+	}
+
+	role, err := server.accountRole(ctx, fromAccount, authPayload)
+	if err != nil {
+		return transferOutcome{}, http.StatusInternalServerError, err
+	}
+	if !slices.Contains(transactRoles, role) {
+		return transferOutcome{}, http.StatusBadRequest, newAPIError(http.StatusForbidden, CodePermissionDenied, "cannot transfer from other account")
+	}
+
+	for _, account := range []db.Account{fromAccount, toAccount} {
+		if err := checkCurrency(account, body.Currency); err != nil {
+			return transferOutcome{}, http.StatusBadRequest, err
+		}
+	}
+
+	fee, err := server.store.CalculateFee(ctx, db.CalculateFeeParams{
+		Currency:    body.Currency,
+		AccountType: fromAccount.AccountType,
+		Amount:      body.Amount,
+	})
+	if err != nil {
+		return transferOutcome{}, http.StatusInternalServerError, err
+	}
+
+	total, ok := util.AddAmounts(body.Amount, fee)
+	if !ok {
+		return transferOutcome{}, http.StatusBadRequest, db.ErrAmountOverflow
+	}
+	if err := checkBalance(fromAccount, total); err != nil {
+		return transferOutcome{}, http.StatusBadRequest, err
+	}
+
+	approvals, err := server.transferApprovals(ctx, fromAccount, body.Amount)
+	if err != nil {
+		return transferOutcome{}, http.StatusInternalServerError, err
+	}
+	if approvals > 0 {
+		expiresAt := time.Now().Add(pendingTransferExpiry)
+		transfer, err := server.store.CreatePendingTransfer(ctx, db.CreatePendingTransferParams{
+			FromAccountID:     fromAccount.ID,
+			ToAccountID:       toAccount.ID,
+			Amount:            body.Amount,
+			Description:       body.Description,
+			Reference:         body.Reference,
+			Metadata:          metadataOrEmpty(body.Metadata),
+			ExpiresAt:         &expiresAt,
+			InitiatedBy:       &authPayload.Username,
+			RequiredApprovals: approvals,
+		})
+		if err != nil {
+			return transferOutcome{}, http.StatusInternalServerError, err
+		}
+		return transferOutcome{
+			Pending:           &transfer,
+			FromAccountNumber: fromAccount.AccountNumber,
+			ToAccountNumber:   toAccount.AccountNumber,
+		}, http.StatusAccepted, nil
+	}
+
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        body.Amount,
+		Description:   body.Description,
+		Reference:     body.Reference,
+		Metadata:      normalizeMetadata(body.Metadata),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAmountOverflow) {
+			return transferOutcome{}, http.StatusBadRequest, err
+		}
+		return transferOutcome{}, http.StatusInternalServerError, err
+	}
 
-	if authPayload.Username != fromAccount.Owner {
-		c.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("cannot transfer from other account")))
+	return transferOutcome{
+		Result:            result,
+		FromAccountNumber: fromAccount.AccountNumber,
+		ToAccountNumber:   toAccount.AccountNumber,
+	}, http.StatusOK, nil
+}
+
+// storeErrorStatus is the status of a failed lookup, a missing row or recipient is 404
+func storeErrorStatus(err error) int {
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrRecipientNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// normalizeMetadata treats an explicit JSON null the same as missing metadata
+func normalizeMetadata(metadata json.RawMessage) json.RawMessage {
+	if string(metadata) == "null" {
+		return nil
+	}
+	return metadata
+}
+
+// validateMetadata accepts an empty value or a small JSON object
+func validateMetadata(metadata json.RawMessage) error {
+	if len(normalizeMetadata(metadata)) == 0 {
+		return nil
+	}
+	if len(metadata) > maxMetadataSize {
+		return fmt.Errorf("metadata must not be larger than %d bytes", maxMetadataSize)
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(metadata, &object); err != nil {
+		return errors.New("metadata must be a JSON object")
+	}
+	if len(object) > maxMetadataKeys {
+		return fmt.Errorf("metadata must not have more than %d keys", maxMetadataKeys)
+	}
+	return nil
+}
+
+type listTransferRequest struct {
+	AccountID int64  `form:"account_id" binding:"required,min=1"`
+	Page      int32  `form:"page" binding:"required,min=1"`
+	Limit     int32  `form:"limit" binding:"required,min=1,max=100"`
+	Query     string `form:"q" binding:"max=140"`
+	Reference string `form:"reference" binding:"max=64"`
+	Metadata  string `form:"metadata"`
+}
+
+func (server *Server) listTransfers(c *gin.Context) {
+	var query listTransferRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	if !server.isValidCurrency(c, fromAccount, body.Currency) {
+	metadata := json.RawMessage(query.Metadata)
+	if err := validateMetadata(metadata); err != nil {
+		writeError(c, http.StatusBadRequest, err)
 		return
 	}
-	if !server.isValidCurrency(c, toAccount, body.Currency) {
+
+	if _, ok := server.getMemberAccount(c, query.AccountID, readRoles); !ok {
 		return
 	}
-	if !server.isValidBalance(c, fromAccount, body.Amount) {
+
+	transfers, err := server.store.SearchTransfers(c, db.SearchTransfersParams{
+		AccountID:  query.AccountID,
+		Query:      query.Query,
+		Reference:  query.Reference,
+		Metadata:   metadataOrEmpty(metadata),
+		PageLimit:  query.Limit,
+		PageOffset: (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	arg := db.TransferTxParams{
-		FromAccountID: body.FromAccountID,
-		ToAccountID:   body.ToAccountID,
-		Amount:        body.Amount,
+	c.JSON(http.StatusOK, transfers)
+}
+
+// metadataOrEmpty defaults to an empty object, which is what the column stores
+// and what matches every transfer when used as a filter
+func metadataOrEmpty(metadata json.RawMessage) json.RawMessage {
+	if len(normalizeMetadata(metadata)) == 0 {
+		return json.RawMessage("{}")
+	}
+	return metadata
+}
+
+type transferFeeRequest struct {
+	FromAccountID     int64  `form:"from_account_id" binding:"omitempty,min=1"`
+	FromAccountNumber string `form:"from_account_number" binding:"omitempty,account_number"`
+	Amount            int64  `form:"amount" binding:"required,gt=0,max=1000000000000000"`
+	Currency          string `form:"currency" binding:"required,currency"`
+}
+
+type transferFeeResponse struct {
+	Amount   int64  `json:"amount"`
+	Fee      int64  `json:"fee"`
+	Total    int64  `json:"total"`
+	Currency string `json:"currency"`
+}
+
+func (server *Server) previewTransferFee(c *gin.Context) {
+	var query transferFeeRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
 	}
 
-	result, err := server.store.TransferTx(c, arg)
+	if (query.FromAccountID != 0) == (query.FromAccountNumber != "") {
+		writeError(c, http.StatusBadRequest, errors.New("exactly one of from_account_id or from_account_number is required"))
+		return
+	}
+
+	var fromAccount db.Account
+	var ok bool
+	if query.FromAccountNumber != "" {
+		fromAccount, ok = server.getAccountByNumber(c, query.FromAccountNumber)
+		ok = ok && server.authorizeAccount(c, fromAccount, readRoles)
+	} else {
+		fromAccount, ok = server.getMemberAccount(c, query.FromAccountID, readRoles)
+	}
+	if !ok {
+		return
+	}
+	if !server.isValidCurrency(c, fromAccount, query.Currency) {
+		return
+	}
+
+	fee, err := server.store.CalculateFee(c, db.CalculateFeeParams{
+		Currency:    query.Currency,
+		AccountType: fromAccount.AccountType,
+		Amount:      query.Amount,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	total, ok := util.AddAmounts(query.Amount, fee)
+	if !ok {
+		writeError(c, http.StatusBadRequest, db.ErrAmountOverflow)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, transferFeeResponse{
+		Amount:   query.Amount,
+		Fee:      fee,
+		Total:    total,
+		Currency: query.Currency,
+	})
 }
 
-func (server *Server) getAccountByID(c *gin.Context, accountID int64) db.Account {
-	account, err := server.store.GetAccount(c, accountID)
+// getAccountByNumber loads the account with the external account number,
+// the error response is already written when it returns false
+func (server *Server) getAccountByNumber(c *gin.Context, accountNumber string) (db.Account, bool) {
+	account, err := server.store.GetAccountByNumber(c, accountNumber)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, errorResponse(err))
-			return db.Account{}
+			writeError(c, http.StatusNotFound, err)
+			return db.Account{}, false
 		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
-		return db.Account{}
+		writeError(c, http.StatusInternalServerError, err)
+		return db.Account{}, false
 	}
-	return account
+	return account, true
 }
 
 func (server *Server) isValidCurrency(c *gin.Context, account db.Account, currency string) bool {
-	if account.Currency != currency {
-		c.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("currency not valid [%s], %s vs %s", account.Owner, account.Currency, currency)))
+	if err := checkCurrency(account, currency); err != nil {
+		writeError(c, http.StatusBadRequest, err)
 		return false
 	}
 	return true
 }
 
-func (server *Server) isValidBalance(c *gin.Context, account db.Account, amount int64) bool {
-	if account.Balance < amount {
-		c.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("balance not valid [%s], %d vs %d", account.Owner, account.Balance, amount)))
-		return false
+func checkCurrency(account db.Account, currency string) error {
+	if account.Currency != currency {
+		return newAPIError(http.StatusUnprocessableEntity, CodeCurrencyMismatch, "currency not valid, %s vs %s", account.Currency, currency)
 	}
-	return true
+	return nil
+}
+
+// checkBalance allows the account to go into its overdraft
+func checkBalance(account db.Account, amount int64) error {
+	if account.AvailableBalance+account.OverdraftLimit < amount {
+		return newAPIError(http.StatusUnprocessableEntity, CodeInsufficientFunds, "balance not valid, the available balance is too low for %d", amount)
+	}
+	return nil
 }
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+)
+
+const pendingTransferExpiry = 24 * time.Hour
+
+// approvalThreshold returns 0 when transfers in the currency never need approval
+func (server *Server) approvalThreshold(ctx context.Context, currency string) (int64, error) {
+	threshold, err := server.store.GetApprovalThreshold(ctx, currency)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return threshold.MinAmount, nil
+}
+
+type transferURIRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) approveTransfer(c *gin.Context) {
+	var uri transferURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, account, ok := server.getMemberTransfer(c, uri.ID, approveRoles)
+	if !ok {
+		return
+	}
+
+	// the owner of a personal account approves their own transfers,
+	// a transfer of an organization needs someone else to approve it
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.OrganizationID != nil && transfer.InitiatedBy != nil && *transfer.InitiatedBy == authPayload.Username {
+		writeError(c, http.StatusForbidden, errors.New("cannot approve your own transfer"))
+		return
+	}
+
+	result, err := server.store.ApproveTransfer(c, db.ApproveTransferParams{
+		TransferID: transfer.ID,
+		ApprovedBy: authPayload.Username,
+	})
+	if err != nil {
+		transferErrorResponse(c, err)
+		return
+	}
+
+	// more approvals are needed before the transfer executes
+	if result.Transfer.Status == db.TransferStatusPending {
+		c.JSON(http.StatusAccepted, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (server *Server) rejectTransfer(c *gin.Context) {
+	var uri transferURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, _, ok := server.getMemberTransfer(c, uri.ID, approveRoles)
+	if !ok {
+		return
+	}
+
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	transfer, err := server.store.RejectTransfer(c, db.RejectTransferParams{
+		TransferID: transfer.ID,
+		RejectedBy: authPayload.Username,
+	})
+	if err != nil {
+		transferErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func transferErrorResponse(c *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		writeError(c, http.StatusNotFound, err)
+	case errors.Is(err, db.ErrInsufficientFunds):
+		writeError(c, http.StatusBadRequest, err)
+	case errors.Is(err, db.ErrTransferNotPending), errors.Is(err, db.ErrTransferExpired),
+		errors.Is(err, db.ErrTransferAlreadyApproved):
+		writeError(c, http.StatusConflict, err)
+	default:
+		writeError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// getMemberTransfer loads the transfer and the account it is sent from and makes sure the caller
+// has one of the roles on that account, the error response is already written when it returns false
+func (server *Server) getMemberTransfer(c *gin.Context, transferID int64, roles []string) (db.Transfer, db.Account, bool) {
+	transfer, err := server.store.GetTransfer(c, transferID)
+	if err != nil {
+		transferErrorResponse(c, err)
+		return db.Transfer{}, db.Account{}, false
+	}
+
+	account, ok := server.getMemberAccount(c, transfer.FromAccountID, roles)
+	if !ok {
+		return db.Transfer{}, db.Account{}, false
+	}
+
+	return transfer, account, true
+}
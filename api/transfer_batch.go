@@ -0,0 +1,352 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+const (
+	maxBatchRows = 1000
+	maxBatchSize = 1 << 20
+)
+
+var batchColumns = []string{"to_account_id", "recipient", "amount", "description", "reference"}
+
+type createTransferBatchRequest struct {
+	FromAccountID int64  `form:"from_account_id" binding:"required,min=1"`
+	Currency      string `form:"currency" binding:"required,currency"`
+	Mode          string `form:"mode" binding:"omitempty,oneof=all_or_nothing best_effort"`
+}
+
+// batchRow is one uploaded row, the recipient is given either by account ID or by username/email
+type batchRow struct {
+	ToAccountID int64  `json:"to_account_id"`
+	Recipient   string `json:"recipient"`
+	Amount      int64  `json:"amount"`
+	Description string `json:"description"`
+	Reference   string `json:"reference"`
+}
+
+type batchRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// batchRowsError holds what is wrong with each invalid row of a batch
+type batchRowsError struct {
+	rows []batchRowError
+}
+
+func (err *batchRowsError) Error() string {
+	return "batch has invalid rows"
+}
+
+// createTransferBatch takes the rows as the request body, either text/csv with a header line
+// or application/x-ndjson with one JSON object per line. Every row is validated before any
+// transfer is made, an invalid row rejects the whole upload.
+func (server *Server) createTransferBatch(c *gin.Context) {
+	var query createTransferBatchRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	if query.Mode == "" {
+		query.Mode = db.BatchModeAllOrNothing
+	}
+
+	fromAccount, ok := server.getMemberAccount(c, query.FromAccountID, transactRoles)
+	if !ok {
+		return
+	}
+	if !server.isValidCurrency(c, fromAccount, query.Currency) {
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchSize)
+
+	var rows []batchRow
+	var err error
+	switch c.ContentType() {
+	case "text/csv":
+		rows, err = parseCSVBatch(body)
+	case "application/x-ndjson", "application/jsonl":
+		rows, err = parseJSONLinesBatch(body)
+	default:
+		writeError(c, http.StatusUnsupportedMediaType, errors.New("batch must be text/csv or application/x-ndjson"))
+		return
+	}
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(rows) == 0 {
+		writeError(c, http.StatusBadRequest, errors.New("batch has no rows"))
+		return
+	}
+	if len(rows) > maxBatchRows {
+		writeError(c, http.StatusBadRequest, fmt.Errorf("batch must not have more than %d rows", maxBatchRows))
+		return
+	}
+
+	limit, err := server.approvalLimit(c, fromAccount)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	transfers, rowErrors, err := server.validateBatchRows(c, fromAccount, rows, limit)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(rowErrors) > 0 {
+		writeError(c, http.StatusBadRequest, &batchRowsError{rows: rowErrors})
+		return
+	}
+	// the rows of a batch move money at once, so a payment split into small rows
+	// is held to the same approval limit as one transfer
+	if limit > 0 && batchTotal(transfers) >= limit {
+		writeError(c, http.StatusBadRequest, errors.New("batch total requires approval, batches can't be approved"))
+		return
+	}
+
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.BatchTransferTx(c, db.BatchTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		CreatedBy:     authPayload.Username,
+		Mode:          query.Mode,
+		Rows:          transfers,
+	})
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// validateBatchRows resolves the recipient of every row and collects what is wrong with each,
+// err is only set when the lookups themselves fail
+func (server *Server) validateBatchRows(c *gin.Context, fromAccount db.Account, rows []batchRow, limit int64) ([]db.BatchTransferRow, []batchRowError, error) {
+	var err error
+	transfers := make([]db.BatchTransferRow, 0, len(rows))
+	var rowErrors []batchRowError
+	invalid := func(i int, message string) {
+		rowErrors = append(rowErrors, batchRowError{Row: i + 1, Error: message})
+	}
+
+	for i, row := range rows {
+		switch {
+		case (row.ToAccountID == 0) == (row.Recipient == ""):
+			invalid(i, "either to_account_id or recipient is required")
+			continue
+		case row.Amount <= 0:
+			invalid(i, "amount must be greater than 0")
+			continue
+		case row.Amount > util.MaxAmount:
+			invalid(i, fmt.Sprintf("amount must be at most %d", util.MaxAmount))
+			continue
+		case len(row.Description) > 140:
+			invalid(i, "description must not be longer than 140 characters")
+			continue
+		case len(row.Reference) > 64:
+			invalid(i, "reference must not be longer than 64 characters")
+			continue
+		case limit > 0 && row.Amount >= limit:
+			invalid(i, "amount requires approval, send it as a single transfer")
+			continue
+		}
+
+		var toAccount db.Account
+		if row.Recipient != "" {
+			_, toAccount, err = server.findRecipient(c, row.Recipient, fromAccount.Currency)
+			if errors.Is(err, ErrRecipientNotFound) {
+				invalid(i, err.Error())
+				continue
+			}
+		} else {
+			toAccount, err = server.store.GetAccount(c, row.ToAccountID)
+			if err == sql.ErrNoRows {
+				invalid(i, "account not found")
+				continue
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if toAccount.Currency != fromAccount.Currency {
+			invalid(i, fmt.Sprintf("currency not valid, %s vs %s", toAccount.Currency, fromAccount.Currency))
+			continue
+		}
+		if toAccount.ID == fromAccount.ID {
+			invalid(i, "cannot transfer to same account")
+			continue
+		}
+
+		transfers = append(transfers, db.BatchTransferRow{
+			ToAccountID: toAccount.ID,
+			Amount:      row.Amount,
+			Description: row.Description,
+			Reference:   row.Reference,
+		})
+	}
+
+	return transfers, rowErrors, nil
+}
+
+// batchTotal sums the amounts of the rows, it stops at math.MaxInt64 instead of overflowing
+func batchTotal(rows []db.BatchTransferRow) int64 {
+	var total int64
+	for _, row := range rows {
+		if total > math.MaxInt64-row.Amount {
+			return math.MaxInt64
+		}
+		total += row.Amount
+	}
+	return total
+}
+
+// parseCSVBatch reads a header line naming the columns followed by one row per transfer
+func parseCSVBatch(r io.Reader) ([]batchRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// spreadsheets like to start the file with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if !slices.Contains(batchColumns, name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["amount"]; !ok {
+		return nil, errors.New("amount column is required")
+	}
+
+	var rows []batchRow
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := batchRow{
+			Recipient:   field("recipient"),
+			Description: field("description"),
+			Reference:   field("reference"),
+		}
+		if value := field("to_account_id"); value != "" {
+			row.ToAccountID, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: to_account_id is not a number", n)
+			}
+		}
+		row.Amount, err = strconv.ParseInt(field("amount"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: amount is not a number", n)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseJSONLinesBatch reads one JSON object per line, blank lines are skipped
+func parseJSONLinesBatch(r io.Reader) ([]batchRow, error) {
+	var rows []batchRow
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+
+		var row batchRow
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("row %d: %w", n, err)
+		}
+
+		rows = append(rows, row)
+		n++
+	}
+
+	return rows, scanner.Err()
+}
+
+type transferBatchURIRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransferBatch(c *gin.Context) {
+	var uri transferBatchURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	batch, err := server.store.GetTransferBatch(c, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, ok := server.getMemberAccount(c, batch.FromAccountID, readRoles); !ok {
+		return
+	}
+
+	rows, err := server.store.ListTransferBatchRows(c, batch.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, db.BatchTransferTxResult{
+		Batch: batch,
+		Rows:  rows,
+	})
+}
@@ -0,0 +1,378 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateTransferBatch(t *testing.T) {
+	user, _ := randomUser(t)
+	fromAccount := randomAccount(user.Username)
+	fromAccount.Currency = util.USD
+
+	recipient, _ := randomUser(t)
+	toAccount1 := randomAccount(recipient.Username)
+	toAccount1.ID = fromAccount.ID + 1
+	toAccount1.Currency = util.USD
+	toAccount2 := randomAccount(util.RandomString(6))
+	toAccount2.ID = fromAccount.ID + 2
+	toAccount2.Currency = util.USD
+
+	csvBody := fmt.Sprintf("to_account_id,recipient,amount,description\n%d,,100,salary\n,%s,200,salary\n", toAccount2.ID, recipient.Email)
+	jsonLinesBody := fmt.Sprintf("{\"to_account_id\":%d,\"amount\":100}\n\n{\"recipient\":%q,\"amount\":200}\n", toAccount2.ID, recipient.Username)
+
+	expectedRows := []db.BatchTransferRow{
+		{ToAccountID: toAccount2.ID, Amount: 100, Description: "salary"},
+		{ToAccountID: toAccount1.ID, Amount: 200, Description: "salary"},
+	}
+
+	batchResult := db.BatchTransferTxResult{
+		Batch: db.TransferBatch{
+			ID:            util.RandomInt(1, 100),
+			FromAccountID: fromAccount.ID,
+			CreatedBy:     user.Username,
+			Mode:          db.BatchModeAllOrNothing,
+			Status:        db.BatchStatusCompleted,
+			TotalRows:     2,
+			SucceededRows: 2,
+		},
+		Rows: []db.TransferBatchRow{
+			{RowNumber: 1, ToAccountID: toAccount2.ID, Amount: 100, Status: db.BatchRowStatusSucceeded},
+			{RowNumber: 2, ToAccountID: toAccount1.ID, Amount: 200, Status: db.BatchRowStatusSucceeded},
+		},
+	}
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+		store.EXPECT().GetApprovalThreshold(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount2.ID)).Times(1).Return(toAccount2, nil)
+		store.EXPECT().GetUserByUsernameOrEmail(gomock.Any(), gomock.Any()).Times(1).Return(recipient, nil)
+		store.EXPECT().GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).Times(1).Return(toAccount1, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		contentType   string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "CSV",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD", fromAccount.ID),
+			contentType: "text/csv",
+			body:        csvBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(db.BatchTransferTxParams{
+						FromAccountID: fromAccount.ID,
+						CreatedBy:     user.Username,
+						Mode:          db.BatchModeAllOrNothing,
+						Rows:          expectedRows,
+					})).
+					Times(1).
+					Return(batchResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp db.BatchTransferTxResult
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, batchResult, resp)
+			},
+		},
+		{
+			name:        "JSONLinesBestEffort",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD&mode=best_effort", fromAccount.ID),
+			contentType: "application/x-ndjson",
+			body:        jsonLinesBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(db.BatchTransferTxParams{
+						FromAccountID: fromAccount.ID,
+						CreatedBy:     user.Username,
+						Mode:          db.BatchModeBestEffort,
+						Rows: []db.BatchTransferRow{
+							{ToAccountID: toAccount2.ID, Amount: 100},
+							{ToAccountID: toAccount1.ID, Amount: 200},
+						},
+					})).
+					Times(1).
+					Return(batchResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:        "InvalidRows",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD", fromAccount.ID),
+			contentType: "text/csv",
+			body:        fmt.Sprintf("to_account_id,amount\n%d,100\n%d,-5\n%d,10\n", toAccount2.ID, toAccount2.ID, fromAccount.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(2).Return(fromAccount, nil)
+				store.EXPECT().GetApprovalThreshold(gomock.Any(), gomock.Any()).Times(1).Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount2.ID)).Times(1).Return(toAccount2, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp struct {
+					Rows []batchRowError `json:"rows"`
+				}
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, []batchRowError{
+					{Row: 2, Error: "amount must be greater than 0"},
+					{Row: 3, Error: "cannot transfer to same account"},
+				}, resp.Rows)
+			},
+		},
+		{
+			name:        "RowRequiresApproval",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD", fromAccount.ID),
+			contentType: "text/csv",
+			body:        fmt.Sprintf("to_account_id,amount\n%d,5000\n", toAccount2.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.USD)).
+					Times(1).
+					Return(db.ApprovalThreshold{Currency: util.USD, MinAmount: 1000}, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "TotalRequiresApproval",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD", fromAccount.ID),
+			contentType: "text/csv",
+			body:        fmt.Sprintf("to_account_id,amount\n%d,600\n%d,600\n", toAccount2.ID, toAccount2.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.USD)).
+					Times(1).
+					Return(db.ApprovalThreshold{Currency: util.USD, MinAmount: 1000}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount2.ID)).Times(2).Return(toAccount2, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "MalformedCSV",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD", fromAccount.ID),
+			contentType: "text/csv",
+			body:        "to_account_id,amount\n12,ten\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "EmptyBatch",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD", fromAccount.ID),
+			contentType: "text/csv",
+			body:        "to_account_id,amount\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "UnsupportedContentType",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD", fromAccount.ID),
+			contentType: "application/xml",
+			body:        "<rows/>",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		{
+			name:        "InvalidMode",
+			query:       fmt.Sprintf("from_account_id=%d&currency=USD&mode=sometimes", fromAccount.ID),
+			contentType: "text/csv",
+			body:        csvBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/batch?"+tc.query, bytes.NewBufferString(tc.body))
+			assert.NoError(t, err)
+			request.Header.Set("Content-Type", tc.contentType)
+
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransferBatch(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	batch := db.TransferBatch{
+		ID:            util.RandomInt(1, 100),
+		FromAccountID: account.ID,
+		CreatedBy:     user.Username,
+		Mode:          db.BatchModeBestEffort,
+		Status:        db.BatchStatusPartiallyCompleted,
+		TotalRows:     2,
+		SucceededRows: 1,
+	}
+	rows := []db.TransferBatchRow{
+		{BatchID: batch.ID, RowNumber: 1, Amount: 100, Status: db.BatchRowStatusSucceeded},
+		{BatchID: batch.ID, RowNumber: 2, Amount: 200, Status: db.BatchRowStatusFailed, Error: db.ErrInsufficientFunds.Error()},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListTransferBatchRows(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(rows, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp db.BatchTransferTxResult
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, db.BatchTransferTxResult{Batch: batch, Rows: rows}, resp)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(db.TransferBatch{}, sql.ErrNoRows)
+				store.EXPECT().ListTransferBatchRows(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListTransferBatchRows(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/transfers/batch/%d", batch.ID), nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestParseCSVBatch(t *testing.T) {
+	rows, err := parseCSVBatch(strings.NewReader("\uFEFFAmount, Recipient ,reference\n100,jane@example.com,PAY-1\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []batchRow{{Recipient: "jane@example.com", Amount: 100, Reference: "PAY-1"}}, rows)
+
+	_, err = parseCSVBatch(strings.NewReader("to_account_id,amount,iban\n1,100,x\n"))
+	assert.ErrorContains(t, err, "unknown column")
+
+	_, err = parseCSVBatch(strings.NewReader("to_account_id\n1\n"))
+	assert.ErrorContains(t, err, "amount column is required")
+
+	_, err = parseCSVBatch(strings.NewReader("to_account_id,amount\n1,100,extra\n"))
+	assert.Error(t, err)
+}
+
+func TestParseJSONLinesBatch(t *testing.T) {
+	rows, err := parseJSONLinesBatch(strings.NewReader("{\"to_account_id\":1,\"amount\":100}\n{\"recipient\":\"jane\",\"amount\":5}"))
+	assert.NoError(t, err)
+	assert.Equal(t, []batchRow{{ToAccountID: 1, Amount: 100}, {Recipient: "jane", Amount: 5}}, rows)
+
+	_, err = parseJSONLinesBatch(strings.NewReader("{\"to_account_id\":1,\"amount\":100,\"iban\":\"x\"}\n"))
+	assert.ErrorContains(t, err, "row 1")
+}
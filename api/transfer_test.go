@@ -2,9 +2,12 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -23,11 +26,13 @@ func TestCreateTransfer(t *testing.T) {
 	user1, _ := randomUser(t)
 	account1 := randomAccount(user1.Username)
 	account1.Balance = 1000
+	account1.AvailableBalance = 1000
 	account1.Currency = util.IDR
 
 	user2, _ := randomUser(t)
 	account2 := randomAccount(user2.Username)
 	account2.Balance = 500
+	account2.AvailableBalance = 500
 	account2.Currency = util.IDR
 
 	amount := int64(100)
@@ -38,6 +43,7 @@ func TestCreateTransfer(t *testing.T) {
 			FromAccountID: account1.ID,
 			ToAccountID:   account2.ID,
 			Amount:        amount,
+			Metadata:      json.RawMessage("{}"),
 		},
 		FromAccount: account1,
 		ToAccount:   account2,
@@ -69,7 +75,7 @@ func TestCreateTransfer(t *testing.T) {
 				Currency:      util.IDR,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, err := tokenMaker.CreateToken(user1.Username, time.Minute)
+				accessToken, err := tokenMaker.CreateToken(user1.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
 			},
@@ -86,6 +92,22 @@ func TestCreateTransfer(t *testing.T) {
 					Times(1).
 					Return(account2, nil)
 
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Eq(db.CalculateFeeParams{
+						Currency:    util.IDR,
+						AccountType: util.CheckingAccount,
+						Amount:      amount,
+					})).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.
+					EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+
 				store.
 					EXPECT().
 					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
@@ -118,7 +140,7 @@ func TestCreateTransfer(t *testing.T) {
 				Currency:      "",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, err := tokenMaker.CreateToken(user1.Username, time.Minute)
+				accessToken, err := tokenMaker.CreateToken(user1.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
 			},
@@ -151,7 +173,7 @@ func TestCreateTransfer(t *testing.T) {
 				Currency:      util.IDR,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, err := tokenMaker.CreateToken(user1.Username, time.Minute)
+				accessToken, err := tokenMaker.CreateToken(user1.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
 			},
@@ -193,7 +215,7 @@ func TestCreateTransfer(t *testing.T) {
 				Currency:      util.IDR,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, err := tokenMaker.CreateToken(user2.Username, time.Minute)
+				accessToken, err := tokenMaker.CreateToken(user2.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
 			},
@@ -210,6 +232,15 @@ func TestCreateTransfer(t *testing.T) {
 					Times(1).
 					Return(account2, nil)
 
+				store.
+					EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{
+						AccountID: account1.ID,
+						Username:  user2.Username,
+					})).
+					Times(1).
+					Return(db.AccountMember{}, sql.ErrNoRows)
+
 				store.
 					EXPECT().
 					TransferTx(gomock.Any(), gomock.Any()).
@@ -237,7 +268,7 @@ func TestCreateTransfer(t *testing.T) {
 				Currency:      util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, err := tokenMaker.CreateToken(user1.Username, time.Minute)
+				accessToken, err := tokenMaker.CreateToken(user1.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
 			},
@@ -270,6 +301,9 @@ func TestCreateTransfer(t *testing.T) {
 				assert.NoError(t, err)
 
 				assert.Contains(t, resp["error"], "currency not valid")
+				// the owner of an account found by its number stays masked
+				assert.NotContains(t, resp["error"], account1.Owner)
+				assert.NotContains(t, resp["error"], account2.Owner)
 			},
 		},
 		{
@@ -281,7 +315,7 @@ func TestCreateTransfer(t *testing.T) {
 				Currency:      util.IDR,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				accessToken, err := tokenMaker.CreateToken(user1.Username, time.Minute)
+				accessToken, err := tokenMaker.CreateToken(user1.Username, util.DepositorRole, 0, time.Minute)
 				assert.NoError(t, err)
 				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
 			},
@@ -298,6 +332,16 @@ func TestCreateTransfer(t *testing.T) {
 					Times(1).
 					Return(account2, nil)
 
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Eq(db.CalculateFeeParams{
+						Currency:    util.IDR,
+						AccountType: util.CheckingAccount,
+						Amount:      1500,
+					})).
+					Times(1).
+					Return(int64(0), nil)
+
 				store.
 					EXPECT().
 					TransferTx(gomock.Any(), gomock.Any()).
@@ -314,28 +358,1007 @@ func TestCreateTransfer(t *testing.T) {
 				assert.NoError(t, err)
 
 				assert.Contains(t, resp["error"], "balance not valid")
+				assert.NotContains(t, resp["error"], account1.Owner)
+				assert.NotContains(t, resp["error"], fmt.Sprint(account1.AvailableBalance))
 			},
 		},
-	}
+		{
+			name: "InvalidBalanceWithFee",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        int64(1000),
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, err := tokenMaker.CreateToken(user1.Username, util.DepositorRole, 0, time.Minute)
+				assert.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			store := mockdb.NewMockStore(ctrl)
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
 
-			tc.buildStubs(store)
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Eq(db.CalculateFeeParams{
+						Currency:    util.IDR,
+						AccountType: util.CheckingAccount,
+						Amount:      1000,
+					})).
+					Times(1).
+					Return(int64(1), nil)
 
-			server := newServerTest(t, store)
-			recorder := httptest.NewRecorder()
-			url := "/transfers"
-			data, err := json.Marshal(tc.body)
-			assert.NoError(t, err)
+				store.
+					EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
 
-			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
-			assert.NoError(t, err)
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
 
-			tc.setupAuth(t, request, server.tokenMaker)
+				resp := gin.H{}
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+
+				assert.Contains(t, resp["error"], "balance not valid")
+			},
+		},
+		{
+			name: "WithinOverdraft",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        int64(1500),
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, err := tokenMaker.CreateToken(user1.Username, util.DepositorRole, 0, time.Minute)
+				assert.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				overdraftAccount := account1
+				overdraftAccount.OverdraftLimit = 500
+
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(overdraftAccount, nil)
+
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.
+					EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+
+				store.
+					EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        1500,
+					})).
+					Times(1).
+					Return(transferTxResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, err := tokenMaker.CreateToken(user1.Username, util.DepositorRole, 0, time.Minute)
+				assert.NoError(t, err)
+				request.Header.Add(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.
+					EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+
+				store.
+					EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "WithDetails",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+				Description:   "rent for march",
+				Reference:     "INV-2024-03",
+				Metadata:      json.RawMessage(`{"invoice":"INV-2024-03"}`),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.
+					EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+
+				store.
+					EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+						Description:   "rent for march",
+						Reference:     "INV-2024-03",
+						Metadata:      json.RawMessage(`{"invoice":"INV-2024-03"}`),
+					})).
+					Times(1).
+					Return(transferTxResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ToRecipient",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				Recipient:     user2.Email,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+
+				store.
+					EXPECT().
+					GetUserByUsernameOrEmail(gomock.Any(), gomock.Eq(user2.Email)).
+					Times(1).
+					Return(user2, nil)
+
+				store.
+					EXPECT().
+					GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerAndCurrencyParams{
+						Owner:       user2.Username,
+						Currency:    util.IDR,
+						AccountType: util.CheckingAccount,
+					})).
+					Times(1).
+					Return(account2, nil)
+
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.
+					EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+
+				store.
+					EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+					})).
+					Times(1).
+					Return(transferTxResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ToAccountNumber",
+			body: transferRequest{
+				FromAccountID:   account1.ID,
+				ToAccountNumber: account2.AccountNumber,
+				Amount:          amount,
+				Currency:        util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+
+				store.
+					EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account2.AccountNumber)).
+					Times(1).
+					Return(account2, nil)
+
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.
+					EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+
+				store.
+					EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+					})).
+					Times(1).
+					Return(transferTxResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FromAccountNumber",
+			body: transferRequest{
+				FromAccountNumber: account1.AccountNumber,
+				ToAccountNumber:   account2.AccountNumber,
+				Amount:            amount,
+				Currency:          util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+				store.
+					EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account1.AccountNumber)).
+					Times(1).
+					Return(account1, nil)
+
+				store.
+					EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account2.AccountNumber)).
+					Times(1).
+					Return(account2, nil)
+
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.
+					EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					Return(db.ApprovalThreshold{}, sql.ErrNoRows)
+
+				store.
+					EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+					})).
+					Times(1).
+					Return(transferTxResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FromAccountIDAndNumber",
+			body: transferRequest{
+				FromAccountID:     account1.ID,
+				FromAccountNumber: account1.AccountNumber,
+				ToAccountID:       account2.ID,
+				Amount:            amount,
+				Currency:          util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoSource",
+			body: transferRequest{
+				ToAccountID: account2.ID,
+				Amount:      amount,
+				Currency:    util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAccountNumber",
+			body: transferRequest{
+				FromAccountID:   account1.ID,
+				ToAccountNumber: "SB00123456789012",
+				Amount:          amount,
+				Currency:        util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "RecipientNotFound",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				Recipient:     "nobody",
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+
+				store.
+					EXPECT().
+					GetUserByUsernameOrEmail(gomock.Any(), gomock.Eq("nobody")).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "RecipientIsSender",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				Recipient:     user1.Username,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+
+				store.
+					EXPECT().
+					GetUserByUsernameOrEmail(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return(user1, nil)
+
+				store.
+					EXPECT().
+					GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(account1, nil)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AccountAndRecipient",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Recipient:     user2.Username,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoDestination",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AmountTooLarge",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        math.MaxInt64,
+				Currency:      util.IDR,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "RequiresApproval",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+				Reference:     "INV-2024-03",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+
+				store.
+					EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+					Times(1).
+					Return(account2, nil)
+
+				store.
+					EXPECT().
+					CalculateFee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.
+					EXPECT().
+					GetApprovalThreshold(gomock.Any(), gomock.Eq(util.IDR)).
+					Times(1).
+					Return(db.ApprovalThreshold{Currency: util.IDR, MinAmount: amount}, nil)
+
+				store.
+					EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreatePendingTransferParams) (db.Transfer, error) {
+						assert.Equal(t, account1.ID, arg.FromAccountID)
+						assert.Equal(t, account2.ID, arg.ToAccountID)
+						assert.Equal(t, amount, arg.Amount)
+						assert.Equal(t, "INV-2024-03", arg.Reference)
+						assert.Equal(t, json.RawMessage("{}"), arg.Metadata)
+						assert.WithinDuration(t, time.Now().Add(pendingTransferExpiry), *arg.ExpiresAt, time.Minute)
+						assert.Equal(t, user1.Username, *arg.InitiatedBy)
+						assert.Equal(t, int32(1), arg.RequiredApprovals)
+
+						return db.Transfer{
+							ID:            util.RandomInt(1, 99),
+							FromAccountID: arg.FromAccountID,
+							ToAccountID:   arg.ToAccountID,
+							Amount:        arg.Amount,
+							Reference:     arg.Reference,
+							Metadata:      arg.Metadata,
+							Status:        db.TransferStatusPending,
+							ExpiresAt:     arg.ExpiresAt,
+						}, nil
+					})
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var transfer db.Transfer
+				err = json.Unmarshal(data, &transfer)
+				assert.NoError(t, err)
+				assert.Equal(t, db.TransferStatusPending, transfer.Status)
+				assert.NotNil(t, transfer.ExpiresAt)
+			},
+		},
+		{
+			name: "InvalidMetadata",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+				Metadata:      json.RawMessage(`["not","an","object"]`),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DescriptionTooLong",
+			body: transferRequest{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.IDR,
+				Description:   util.RandomString(141),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, user1.Username, util.DepositorRole)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+			url := "/transfers"
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestExecuteTransferAmountOverflow covers the callers which don't bind the amount,
+// amount plus fee used to wrap around and pass the balance check
+func TestExecuteTransferAmountOverflow(t *testing.T) {
+	user, _ := randomUser(t)
+	account1 := randomAccount(user.Username)
+	account1.Currency = util.IDR
+	account2 := randomAccount(util.RandomString(6))
+	account2.Currency = util.IDR
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(1).Return(int64(2), nil)
+	store.EXPECT().CreatePendingTransfer(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newServerTest(t, store)
+	_, status, err := server.executeTransfer(context.Background(), &token.Payload{Username: user.Username}, transferRequest{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        math.MaxInt64,
+		Currency:      util.IDR,
+	})
+	assert.ErrorIs(t, err, db.ErrAmountOverflow)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestPreviewTransferFee(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: fmt.Sprintf("from_account_id=%d&amount=%d&currency=%s", account.ID, 2000, util.USD),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					CalculateFee(gomock.Any(), gomock.Eq(db.CalculateFeeParams{
+						Currency:    util.USD,
+						AccountType: util.CheckingAccount,
+						Amount:      2000,
+					})).
+					Times(1).
+					Return(int64(30), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp transferFeeResponse
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+
+				assert.Equal(t, transferFeeResponse{
+					Amount:   2000,
+					Fee:      30,
+					Total:    2030,
+					Currency: util.USD,
+				}, resp)
+			},
+		},
+		{
+			name:  "FromAccountNumber",
+			query: fmt.Sprintf("from_account_number=%s&amount=%d&currency=%s", account.AccountNumber, 2000, util.USD),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					CalculateFee(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(30), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "FromAccountIDAndNumber",
+			query: fmt.Sprintf("from_account_id=%d&from_account_number=%s&amount=%d&currency=%s", account.ID, account.AccountNumber, 2000, util.USD),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "BadRequest",
+			query: fmt.Sprintf("from_account_id=%d&amount=%d&currency=%s", account.ID, 0, util.USD),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidCurrency",
+			query: fmt.Sprintf("from_account_id=%d&amount=%d&currency=%s", account.ID, 2000, util.EUR),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().CalculateFee(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers/fee?"+tc.query, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTransfers(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	transfers := []db.Transfer{
+		{
+			ID:            util.RandomInt(1, 100),
+			FromAccountID: account.ID,
+			ToAccountID:   util.RandomInt(101, 200),
+			Amount:        100,
+			Description:   "rent for march",
+			Reference:     "INV-2024-03",
+			Metadata:      json.RawMessage(`{"invoice":"INV-2024-03"}`),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			query:    fmt.Sprintf("account_id=%d&page=1&limit=5&q=rent&reference=INV-2024-03", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+						AccountID:  account.ID,
+						Query:      "rent",
+						Reference:  "INV-2024-03",
+						Metadata:   json.RawMessage("{}"),
+						PageLimit:  5,
+						PageOffset: 0,
+					})).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				assert.NoError(t, err)
+
+				var resp []db.Transfer
+				err = json.Unmarshal(data, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, transfers, resp)
+			},
+		},
+		{
+			name:     "MetadataFilter",
+			query:    fmt.Sprintf(`account_id=%d&page=2&limit=5&metadata={"invoice":"INV-2024-03"}`, account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+						AccountID:  account.ID,
+						Metadata:   json.RawMessage(`{"invoice":"INV-2024-03"}`),
+						PageLimit:  5,
+						PageOffset: 5,
+					})).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidMetadata",
+			query:    fmt.Sprintf("account_id=%d&page=1&limit=5&metadata=invoice", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			query:    fmt.Sprintf("account_id=%d&page=1&limit=5", account.ID),
+			username: "unauthorized",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidPageSize",
+			query:    fmt.Sprintf("account_id=%d&page=1&limit=1000", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers", nil)
+			assert.NoError(t, err)
+			request.URL.RawQuery = tc.query
 
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
 			server.router.ServeHTTP(recorder, request)
 
 			tc.checkResponse(t, recorder)
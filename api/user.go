@@ -3,7 +3,6 @@ package api
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 	"net/http"
 	"time"
 
@@ -24,6 +23,7 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Role              string    `json:"role"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -33,6 +33,7 @@ func toUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -41,13 +42,13 @@ func toUserResponse(user db.User) userResponse {
 func (server *Server) createUser(c *gin.Context) {
 	var body createUserRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	hashedPassword, err := util.HashPassword(body.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -58,19 +59,21 @@ func (server *Server) createUser(c *gin.Context) {
 		Email:          body.Email,
 	}
 
+	// nobody is logged in yet, the new user signs up by themselves
+	setAuditActor(c, arg.Username)
 	user, err := server.store.CreateUser(c, arg)
 	if err != nil {
 		if pgError, ok := err.(*pq.Error); ok {
 			switch pgError.Constraint {
 			case "users_pkey":
-				c.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("username already exists")))
+				writeError(c, http.StatusForbidden, newAPIError(http.StatusConflict, CodeAlreadyExists, "username already exists"))
 				return
 			case "users_email_key":
-				c.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("email already exists")))
+				writeError(c, http.StatusForbidden, newAPIError(http.StatusConflict, CodeAlreadyExists, "email already exists"))
 				return
 			}
 		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -79,8 +82,9 @@ func (server *Server) createUser(c *gin.Context) {
 }
 
 type loginUserRequest struct {
-	Username string `json:"username" binding:"required,alphanum,min=3"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username       string `json:"username" binding:"required,alphanum,min=3"`
+	Password       string `json:"password" binding:"required,min=6"`
+	OrganizationID int64  `json:"organization_id" binding:"omitempty,min=1"`
 }
 
 type loginUserResponse struct {
@@ -91,29 +95,57 @@ type loginUserResponse struct {
 func (server *Server) loginUser(c *gin.Context) {
 	var body loginUserRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	user, err := server.store.GetUser(c, body.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			c.JSON(http.StatusUnauthorized, errorResponse(errors.New("username or password is wrong")))
+			writeError(c, http.StatusUnauthorized, errors.New("username or password is wrong"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	err = util.CheckPassword(body.Password, user.HashedPassword)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, errorResponse(errors.New("username or password is wrong")))
+		writeError(c, http.StatusUnauthorized, errors.New("username or password is wrong"))
+		return
+	}
+
+	// the token acts for the organization, only its members may ask for that
+	if body.OrganizationID != 0 {
+		_, err = server.store.GetOrganizationMember(c, db.GetOrganizationMemberParams{
+			OrganizationID: body.OrganizationID,
+			Username:       user.Username,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeError(c, http.StatusForbidden, ErrPermissionDenied)
+				return
+			}
+			writeError(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	accessToken, err := server.tokenMaker.CreateToken(user.Username, user.Role, body.OrganizationID, server.config.AccessTokenDuration)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	accessToken, err := server.tokenMaker.CreateToken(body.Username, time.Minute*15)
+	setAuditActor(c, user.Username)
+	err = server.store.RecordAuditEvent(c, db.AuditRecord{
+		Action:   db.AuditActionLogin,
+		Entity:   "user",
+		EntityID: user.Username,
+		After:    gin.H{"organization_id": body.OrganizationID},
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, http.StatusInternalServerError, err)
 		return
 	}
 
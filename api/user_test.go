@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
@@ -30,6 +32,7 @@ func randomUser(t *testing.T) (db.User, string) {
 		HashedPassword: hashedPassword,
 		Email:          fmt.Sprintf("%s@email.com", util.RandomString(6)),
 		FullName:       util.RandomString(6),
+		Role:           util.DepositorRole,
 	}, password
 }
 
@@ -251,9 +254,23 @@ func TestLoginUser(t *testing.T) {
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+
+				store.
+					EXPECT().
+					RecordAuditEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, record db.AuditRecord) error {
+						info := db.AuditInfoFrom(ctx)
+						assert.Equal(t, user.Username, info.Actor)
+						assert.NotEmpty(t, info.RequestID)
+						assert.Equal(t, db.AuditActionLogin, record.Action)
+						assert.Equal(t, user.Username, record.EntityID)
+						return nil
+					})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.NotEmpty(t, recorder.Header().Get(requestIDHeaderKey))
 				data, err := io.ReadAll(recorder.Body)
 				assert.NoError(t, err)
 
@@ -318,6 +335,29 @@ func TestLoginUser(t *testing.T) {
 				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "AuditFailed",
+			body: loginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.
+					EXPECT().
+					RecordAuditEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name: "InternalServerError",
 			body: loginUserRequest{
@@ -359,3 +399,89 @@ func TestLoginUser(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginUserWithOrganization(t *testing.T) {
+	user, password := randomUser(t)
+	organizationID := util.RandomInt(1, 100)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetOrganizationMember(gomock.Any(), gomock.Eq(db.GetOrganizationMemberParams{
+						OrganizationID: organizationID,
+						Username:       user.Username,
+					})).
+					Times(1).
+					Return(db.OrganizationMember{
+						OrganizationID: organizationID,
+						Username:       user.Username,
+						Role:           util.OrganizationInitiatorRole,
+					}, nil)
+
+				store.
+					EXPECT().
+					RecordAuditEvent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp loginUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+
+				payload, err := server.tokenMaker.VerifyToken(resp.AccessToken)
+				assert.NoError(t, err)
+				assert.Equal(t, organizationID, payload.OrganizationID)
+				assert.WithinDuration(t, payload.IssuedAt.Add(testConfig.AccessTokenDuration), payload.ExpiredAt, time.Second)
+			},
+		},
+		{
+			name: "NotMember",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.
+					EXPECT().
+					GetOrganizationMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OrganizationMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+			data, err := json.Marshal(loginUserRequest{
+				Username:       user.Username,
+				Password:       password,
+				OrganizationID: organizationID,
+			})
+			assert.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
@@ -12,3 +12,51 @@ var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
 	}
 	return false
 }
+
+var validAccountType validator.Func = func(fl validator.FieldLevel) bool {
+	accountType, ok := fl.Field().Interface().(string)
+	if ok {
+		return util.IsSupportedAccountType(accountType)
+	}
+	return false
+}
+
+var validAccountRole validator.Func = func(fl validator.FieldLevel) bool {
+	role, ok := fl.Field().Interface().(string)
+	if ok {
+		return util.IsSupportedAccountRole(role)
+	}
+	return false
+}
+
+var validOrganizationRole validator.Func = func(fl validator.FieldLevel) bool {
+	role, ok := fl.Field().Interface().(string)
+	if ok {
+		return util.IsSupportedOrganizationRole(role)
+	}
+	return false
+}
+
+var validAccountNumber validator.Func = func(fl validator.FieldLevel) bool {
+	accountNumber, ok := fl.Field().Interface().(string)
+	if ok {
+		return util.IsValidAccountNumber(accountNumber)
+	}
+	return false
+}
+
+var validWebhookEvent validator.Func = func(fl validator.FieldLevel) bool {
+	eventType, ok := fl.Field().Interface().(string)
+	if ok {
+		return util.IsSupportedWebhookEvent(eventType)
+	}
+	return false
+}
+
+var validPublicURL validator.Func = func(fl validator.FieldLevel) bool {
+	rawURL, ok := fl.Field().Interface().(string)
+	if ok {
+		return util.IsPublicWebhookURL(rawURL)
+	}
+	return false
+}
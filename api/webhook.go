@@ -0,0 +1,292 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+// webhookRoles may manage the webhooks of an account, an endpoint learns about every transfer
+// of the account so only its owner or an admin of its organization may add one
+var webhookRoles = []string{util.AccountOwnerRole, util.OrganizationAdminRole}
+
+type webhookEndpointResponse struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func toWebhookEndpointResponse(endpoint db.WebhookEndpoint) webhookEndpointResponse {
+	return webhookEndpointResponse{
+		ID:         endpoint.ID,
+		AccountID:  endpoint.AccountID,
+		Url:        endpoint.Url,
+		EventTypes: endpoint.EventTypes,
+		CreatedBy:  endpoint.CreatedBy,
+		CreatedAt:  endpoint.CreatedAt,
+	}
+}
+
+// createWebhookResponse is the only response with the secret, it can't be read again
+type createWebhookResponse struct {
+	webhookEndpointResponse
+	Secret string `json:"secret"`
+}
+
+type createWebhookRequest struct {
+	Url        string   `json:"url" binding:"required,http_url,public_url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"omitempty,max=10,dive,webhook_event"`
+}
+
+// createWebhook registers an endpoint for the account, without event_types it receives every event
+func (server *Server) createWebhook(c *gin.Context) {
+	var uri accountURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var body createWebhookRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getMemberAccount(c, uri.ID, webhookRoles)
+	if !ok {
+		return
+	}
+
+	secret, err := util.NewWebhookSecret()
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	eventTypes := body.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	endpoint, err := server.store.CreateWebhookEndpoint(c, db.CreateWebhookEndpointParams{
+		AccountID:  account.ID,
+		Url:        body.Url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		CreatedBy:  authPayload.Username,
+	})
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, createWebhookResponse{
+		webhookEndpointResponse: toWebhookEndpointResponse(endpoint),
+		Secret:                  endpoint.Secret,
+	})
+}
+
+func (server *Server) listWebhooks(c *gin.Context) {
+	var uri accountURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := server.getMemberAccount(c, uri.ID, webhookRoles)
+	if !ok {
+		return
+	}
+
+	endpoints, err := server.store.ListWebhookEndpoints(c, account.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := make([]webhookEndpointResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		resp = append(resp, toWebhookEndpointResponse(endpoint))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+type webhookURIRequest struct {
+	ID        int64 `uri:"id" binding:"required,min=1"`
+	WebhookID int64 `uri:"webhook_id" binding:"required,min=1"`
+}
+
+// deleteWebhook removes the endpoint together with its deliveries
+func (server *Server) deleteWebhook(c *gin.Context) {
+	var uri webhookURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	endpoint, ok := server.getMemberWebhook(c, uri.ID, uri.WebhookID)
+	if !ok {
+		return
+	}
+
+	endpoint, err := server.store.DeleteWebhookEndpoint(c, endpoint.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toWebhookEndpointResponse(endpoint))
+}
+
+type listWebhookDeliveriesRequest struct {
+	Page  int32 `form:"page" binding:"required,min=1"`
+	Limit int32 `form:"limit" binding:"required,min=1,max=100"`
+}
+
+// listWebhookDeliveries returns the newest deliveries of the endpoint first
+func (server *Server) listWebhookDeliveries(c *gin.Context) {
+	var uri webhookURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var query listWebhookDeliveriesRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	endpoint, ok := server.getMemberWebhook(c, uri.ID, uri.WebhookID)
+	if !ok {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(c, db.ListWebhookDeliveriesParams{
+		EndpointID: endpoint.ID,
+		Limit:      query.Limit,
+		Offset:     (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+type webhookDeliveryURIRequest struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	WebhookID  int64 `uri:"webhook_id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+type webhookDeliveryResponse struct {
+	db.WebhookDelivery
+	AttemptLog []db.WebhookAttempt `json:"attempt_log"`
+}
+
+// getWebhookDelivery returns the delivery with every attempt made to send it
+func (server *Server) getWebhookDelivery(c *gin.Context) {
+	var uri webhookDeliveryURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	delivery, ok := server.getMemberWebhookDelivery(c, uri)
+	if !ok {
+		return
+	}
+
+	attempts, err := server.store.ListWebhookAttempts(c, delivery.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookDeliveryResponse{
+		WebhookDelivery: delivery,
+		AttemptLog:      attempts,
+	})
+}
+
+// redeliverWebhook queues the delivery again, also after it failed for good or succeeded
+func (server *Server) redeliverWebhook(c *gin.Context) {
+	var uri webhookDeliveryURIRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		writeError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	delivery, ok := server.getMemberWebhookDelivery(c, uri)
+	if !ok {
+		return
+	}
+
+	delivery, err := server.store.RedeliverWebhook(c, delivery.ID)
+	if err != nil {
+		writeError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// getMemberWebhook loads an endpoint of the account and makes sure the caller may manage
+// the webhooks of the account, the error response is already written when it returns false
+func (server *Server) getMemberWebhook(c *gin.Context, accountID int64, webhookID int64) (db.WebhookEndpoint, bool) {
+	account, ok := server.getMemberAccount(c, accountID, webhookRoles)
+	if !ok {
+		return db.WebhookEndpoint{}, false
+	}
+
+	endpoint, err := server.store.GetWebhookEndpoint(c, webhookID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return db.WebhookEndpoint{}, false
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return db.WebhookEndpoint{}, false
+	}
+	if endpoint.AccountID != account.ID {
+		writeError(c, http.StatusNotFound, sql.ErrNoRows)
+		return db.WebhookEndpoint{}, false
+	}
+
+	return endpoint, true
+}
+
+func (server *Server) getMemberWebhookDelivery(c *gin.Context, uri webhookDeliveryURIRequest) (db.WebhookDelivery, bool) {
+	endpoint, ok := server.getMemberWebhook(c, uri.ID, uri.WebhookID)
+	if !ok {
+		return db.WebhookDelivery{}, false
+	}
+
+	delivery, err := server.store.GetWebhookDelivery(c, uri.DeliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(c, http.StatusNotFound, err)
+			return db.WebhookDelivery{}, false
+		}
+		writeError(c, http.StatusInternalServerError, err)
+		return db.WebhookDelivery{}, false
+	}
+	if delivery.EndpointID != endpoint.ID {
+		writeError(c, http.StatusNotFound, sql.ErrNoRows)
+		return db.WebhookDelivery{}, false
+	}
+
+	return delivery, true
+}
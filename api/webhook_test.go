@@ -0,0 +1,384 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func randomWebhookEndpoint(account db.Account) db.WebhookEndpoint {
+	return db.WebhookEndpoint{
+		ID:         util.RandomInt(1, 100),
+		AccountID:  account.ID,
+		Url:        "https://example.com/hooks/" + util.RandomString(6),
+		Secret:     "whsec_" + util.RandomString(32),
+		EventTypes: []string{util.WebhookEventTransferIncoming},
+		CreatedBy:  account.Owner,
+	}
+}
+
+func TestCreateWebhook(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	endpoint := randomWebhookEndpoint(account)
+	coOwner := randomAccountMember(account, util.AccountCoOwnerRole)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body:     gin.H{"url": endpoint.Url, "event_types": endpoint.EventTypes},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						assert.Equal(t, account.ID, arg.AccountID)
+						assert.Equal(t, endpoint.Url, arg.Url)
+						assert.Equal(t, endpoint.EventTypes, arg.EventTypes)
+						assert.Equal(t, user.Username, arg.CreatedBy)
+						assert.NotEmpty(t, arg.Secret)
+						return endpoint, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp createWebhookResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, endpoint.ID, resp.ID)
+				assert.Equal(t, endpoint.Secret, resp.Secret)
+			},
+		},
+		{
+			name:     "AllEvents",
+			username: user.Username,
+			body:     gin.H{"url": endpoint.Url},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						assert.Equal(t, []string{}, arg.EventTypes)
+						return endpoint, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CoOwnerCannotCreate",
+			username: coOwner.Username,
+			body:     gin.H{"url": endpoint.Url},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(coOwner, nil)
+				store.EXPECT().CreateWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidURL",
+			username: user.Username,
+			body:     gin.H{"url": "ftp://example.com/hooks"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalURL",
+			username: user.Username,
+			body:     gin.H{"url": "http://169.254.169.254/latest/meta-data"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "must point to a public address")
+			},
+		},
+		{
+			name:     "InvalidEventType",
+			username: user.Username,
+			body:     gin.H{"url": endpoint.Url, "event_types": []string{"account.deleted"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/webhooks", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, tc.username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListWebhooks(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	endpoint := randomWebhookEndpoint(account)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().
+		ListWebhookEndpoints(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return([]db.WebhookEndpoint{endpoint}, nil)
+
+	server := newServerTest(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/accounts/%d/webhooks", account.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+	server.router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), endpoint.Secret)
+
+	var resp []webhookEndpointResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, []webhookEndpointResponse{toWebhookEndpointResponse(endpoint)}, resp)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	endpoint := randomWebhookEndpoint(account)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.NotContains(t, recorder.Body.String(), endpoint.Secret)
+			},
+		},
+		{
+			name: "EndpointOfOtherAccount",
+			buildStubs: func(store *mockdb.MockStore) {
+				other := endpoint
+				other.AccountID = account.ID + 1
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(other, nil)
+				store.EXPECT().DeleteWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/webhooks/%d", account.ID, endpoint.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	endpoint := randomWebhookEndpoint(account)
+
+	delivery := db.WebhookDelivery{
+		ID:         util.RandomInt(1, 100),
+		EndpointID: endpoint.ID,
+		EventType:  util.WebhookEventTransferIncoming,
+		Payload:    json.RawMessage(`{"type":"transfer.incoming"}`),
+		Status:     db.WebhookDeliveryFailed,
+		Attempts:   db.WebhookMaxAttempts,
+	}
+	attempts := []db.WebhookAttempt{
+		{ID: 1, DeliveryID: delivery.ID, StatusCode: http.StatusInternalServerError},
+		{ID: 2, DeliveryID: delivery.ID, Error: "connection refused"},
+	}
+	deliveryURL := fmt.Sprintf("/accounts/%d/webhooks/%d/deliveries/%d", account.ID, endpoint.ID, delivery.ID)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "List",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d/webhooks/%d/deliveries?page=1&limit=10", account.ID, endpoint.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListWebhookDeliveries(gomock.Any(), gomock.Eq(db.ListWebhookDeliveriesParams{
+						EndpointID: endpoint.ID,
+						Limit:      10,
+						Offset:     0,
+					})).
+					Times(1).
+					Return([]db.WebhookDelivery{delivery}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp []db.WebhookDelivery
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Len(t, resp, 1)
+				assert.Equal(t, delivery.ID, resp[0].ID)
+			},
+		},
+		{
+			name:   "GetWithAttempts",
+			method: http.MethodGet,
+			url:    deliveryURL,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+				store.EXPECT().ListWebhookAttempts(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(attempts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp webhookDeliveryResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, delivery.ID, resp.ID)
+				assert.Equal(t, attempts, resp.AttemptLog)
+			},
+		},
+		{
+			name:   "Redeliver",
+			method: http.MethodPost,
+			url:    deliveryURL + "/redeliver",
+			buildStubs: func(store *mockdb.MockStore) {
+				queued := delivery
+				queued.Status = db.WebhookDeliveryPending
+				queued.Attempts = 0
+
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+				store.EXPECT().RedeliverWebhook(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(queued, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var resp db.WebhookDelivery
+				err := json.Unmarshal(recorder.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, db.WebhookDeliveryPending, resp.Status)
+			},
+		},
+		{
+			name:   "RedeliverOfOtherEndpoint",
+			method: http.MethodPost,
+			url:    deliveryURL + "/redeliver",
+			buildStubs: func(store *mockdb.MockStore) {
+				other := delivery
+				other.EndpointID = endpoint.ID + 1
+
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(other, nil)
+				store.EXPECT().RedeliverWebhook(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+			tc.buildStubs(store)
+
+			server := newServerTest(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			assert.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, user.Username, util.DepositorRole)
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Command backfill-interest accrues savings interest for days the server missed.
+//
+//	go run ./cmd/backfill-interest -from 2024-01-01 -to 2024-01-31
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log/slog"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/novalyezu/simplebank-backend/config"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/worker"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	fatal := func(msg string, err error) {
+		logger.Error(msg, slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+
+	fromFlag := flag.String("from", "", "first day to accrue, YYYY-MM-DD")
+	toFlag := flag.String("to", yesterday, "last day to accrue, YYYY-MM-DD")
+	flag.Parse()
+
+	from, err := time.Parse(time.DateOnly, *fromFlag)
+	if err != nil {
+		fatal("Invalid -from date", err)
+	}
+	to, err := time.Parse(time.DateOnly, *toFlag)
+	if err != nil {
+		fatal("Invalid -to date", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Invalid configuration", err)
+	}
+
+	dbDriver := "postgres"
+	dbSource := cfg.DBSource()
+
+	conn, err := sql.Open(dbDriver, dbSource)
+	if err != nil {
+		fatal("Cannot connect to db", err)
+	}
+
+	store := db.NewStore(conn, logger)
+	err = worker.Backfill(context.Background(), store, from, to, logger)
+	if err != nil {
+		fatal("Cannot backfill interest", err)
+	}
+}
@@ -0,0 +1,207 @@
+// Package config loads the configuration of the service. Every setting is read from an
+// environment variable, then from the optional config file, then from its default.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/novalyezu/simplebank-backend/tracing"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// DefaultFile is the config file read when CONFIG_FILE isn't set, the service runs
+// without it, e.g. in a container which gets its settings from the environment
+const DefaultFile = ".env"
+
+// fileEnvKey names a config file which must exist
+const fileEnvKey = "CONFIG_FILE"
+
+// Config holds the settings, the env tag is the name of the variable in the environment
+// and in the config file, which has the format of a .env file
+type Config struct {
+	HTTPServerAddress string `env:"HTTP_SERVER_ADDRESS" default:":3000"`
+	GRPCServerAddress string `env:"GRPC_SERVER_ADDRESS" default:":9090"`
+	// MetricsServerAddress serves the Prometheus metrics, it must not be reachable by the clients
+	MetricsServerAddress string `env:"METRICS_SERVER_ADDRESS" default:":9100"`
+	// ShutdownTimeout is how long the servers wait for the requests in flight on shutdown,
+	// it must be shorter than the termination grace period of the pod
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+
+	DBHost            string        `env:"POSTGRES_HOST" default:"localhost"`
+	DBPort            int           `env:"POSTGRES_PORT" default:"5432"`
+	DBUser            string        `env:"POSTGRES_USER"`
+	DBPassword        string        `env:"POSTGRES_PASSWORD"`
+	DBName            string        `env:"POSTGRES_DATABASE"`
+	DBSSLMode         string        `env:"POSTGRES_SSLMODE" default:"disable"`
+	DBMaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"25"`
+	DBMaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"25"`
+	DBConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+
+	TokenSymmetricKey   string        `env:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration time.Duration `env:"ACCESS_TOKEN_DURATION" default:"15m"`
+
+	OutboxSink       string `env:"OUTBOX_SINK"`
+	OutboxWebhookURL string `env:"OUTBOX_WEBHOOK_URL"`
+
+	TraceExporter string `env:"OTEL_TRACES_EXPORTER"`
+}
+
+// The sinks of the outbox relay
+const (
+	OutboxSinkStdout = "stdout"
+	OutboxSinkHTTP   = "http"
+)
+
+// Load reads the config file named by CONFIG_FILE, or DefaultFile if it exists,
+// and validates the settings. An empty CONFIG_FILE reads the environment only.
+func Load() (Config, error) {
+	path, required := os.LookupEnv(fileEnvKey)
+	if !required {
+		path = DefaultFile
+	}
+
+	file := map[string]string{}
+	if path != "" {
+		var err error
+		file, err = godotenv.Read(path)
+		if errors.Is(err, os.ErrNotExist) && !required {
+			file = map[string]string{}
+		} else if err != nil {
+			return Config{}, fmt.Errorf("cannot read config file: %w", err)
+		}
+	}
+
+	return load(func(key string) (string, bool) {
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		value, ok := file[key]
+		return value, ok
+	})
+}
+
+// load fills the config with lookup, the default of a setting is used when lookup doesn't have it
+func load(lookup func(key string) (string, bool)) (Config, error) {
+	var config Config
+
+	v := reflect.ValueOf(&config).Elem()
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("env")
+
+		// an empty variable is unset, like the ones .env.example leaves empty
+		value, ok := lookup(key)
+		if !ok || value == "" {
+			value = field.Tag.Get("default")
+		}
+		if value == "" {
+			continue
+		}
+
+		if err := setField(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return Config{}, errors.Join(errs...)
+	}
+
+	return config, config.Validate()
+}
+
+func setField(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == reflect.TypeOf(time.Duration(0)):
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 30s or 15m", value)
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.Int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		field.SetInt(int64(number))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once
+func (config Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validAddress(config.HTTPServerAddress), "HTTP_SERVER_ADDRESS: %q is not a host:port address", config.HTTPServerAddress)
+	check(validAddress(config.GRPCServerAddress), "GRPC_SERVER_ADDRESS: %q is not a host:port address", config.GRPCServerAddress)
+	check(validAddress(config.MetricsServerAddress), "METRICS_SERVER_ADDRESS: %q is not a host:port address", config.MetricsServerAddress)
+	check(config.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT: must be positive")
+
+	check(config.DBHost != "", "POSTGRES_HOST: is required")
+	check(config.DBPort > 0 && config.DBPort <= 65535, "POSTGRES_PORT: %d is not a port", config.DBPort)
+	check(config.DBUser != "", "POSTGRES_USER: is required")
+	check(config.DBName != "", "POSTGRES_DATABASE: is required")
+	check(config.DBMaxOpenConns > 0, "DB_MAX_OPEN_CONNS: must be positive")
+	check(config.DBMaxIdleConns >= 0 && config.DBMaxIdleConns <= config.DBMaxOpenConns,
+		"DB_MAX_IDLE_CONNS: must be between 0 and DB_MAX_OPEN_CONNS")
+	check(config.DBConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME: must not be negative")
+
+	check(len(config.TokenSymmetricKey) == chacha20poly1305.KeySize,
+		"TOKEN_SYMMETRIC_KEY: must be exactly %d characters", chacha20poly1305.KeySize)
+	check(config.AccessTokenDuration > 0, "ACCESS_TOKEN_DURATION: must be positive")
+
+	check(slices.Contains([]string{"", OutboxSinkStdout, OutboxSinkHTTP}, config.OutboxSink),
+		"OUTBOX_SINK: %q is not stdout or http", config.OutboxSink)
+	if config.OutboxSink == OutboxSinkHTTP {
+		check(validURL(config.OutboxWebhookURL), "OUTBOX_WEBHOOK_URL: must be an http or https URL for the http sink")
+	}
+
+	check(slices.Contains([]string{"", tracing.ExporterOTLP, tracing.ExporterStdout, tracing.ExporterNone}, config.TraceExporter),
+		"OTEL_TRACES_EXPORTER: %q is not otlp, stdout or none", config.TraceExporter)
+
+	return errors.Join(errs...)
+}
+
+// DBSource is the connection string of the database
+func (config Config) DBSource() string {
+	source := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     net.JoinHostPort(config.DBHost, strconv.Itoa(config.DBPort)),
+		Path:     config.DBName,
+		RawQuery: url.Values{"sslmode": {config.DBSSLMode}}.Encode(),
+	}
+	return source.String()
+}
+
+func validAddress(address string) bool {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	number, err := strconv.Atoi(port)
+	return err == nil && number >= 0 && number <= 65535
+}
+
+func validURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,29 @@
+-- the fee revenue accounts can't go while rows reference them, so their entries,
+-- transfers and holds go first. The fee entries of the payers stay, the revenue
+-- side of the fees is lost.
+CREATE TEMP TABLE "dropped_accounts" AS
+SELECT "id" FROM "accounts" WHERE "owner" = 'system_fee';
+
+DELETE FROM "holds"
+WHERE "account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "transfer_id" IN (
+    SELECT "id" FROM "transfers"
+    WHERE "from_account_id" IN (SELECT "id" FROM "dropped_accounts")
+      OR "to_account_id" IN (SELECT "id" FROM "dropped_accounts")
+  );
+
+DELETE FROM "transfers"
+WHERE "from_account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "to_account_id" IN (SELECT "id" FROM "dropped_accounts");
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "dropped_accounts");
+
+DELETE FROM "accounts" WHERE "id" IN (SELECT "id" FROM "dropped_accounts");
+
+DROP TABLE "dropped_accounts";
+
+DELETE FROM "users" WHERE "username" = 'system_fee';
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+DROP TABLE IF EXISTS "fee_rules";
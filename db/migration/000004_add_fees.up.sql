@@ -0,0 +1,31 @@
+-- a transfer is charged by the single most specific rule matching its currency,
+-- account type and amount, fee = flat_fee + amount * basis_points / 10000.
+-- tiers are expressed as several rules over [min_amount, max_amount) ranges.
+CREATE TABLE "fee_rules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "account_type" varchar,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "max_amount" bigint,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "basis_points" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fee_rules" ADD CONSTRAINT "fee_rules_amount_check" CHECK ("min_amount" >= 0 AND ("max_amount" IS NULL OR "max_amount" > "min_amount"));
+
+ALTER TABLE "fee_rules" ADD CONSTRAINT "fee_rules_fee_check" CHECK ("flat_fee" >= 0 AND "basis_points" BETWEEN 0 AND 10000);
+
+CREATE INDEX ON "fee_rules" ("currency", "account_type");
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+-- system user owning the fee revenue accounts, it has no usable password
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('system_fee', '', 'Fee Revenue', 'fee@system.simplebank');
+
+INSERT INTO "accounts" ("owner", "balance", "available_balance", "currency")
+VALUES
+  ('system_fee', 0, 0, 'USD'),
+  ('system_fee', 0, 0, 'EUR'),
+  ('system_fee', 0, 0, 'IDR');
@@ -0,0 +1,44 @@
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "interest_rates";
+
+-- an owner has one account per currency again: the interest expense accounts go, and
+-- so does the savings account of an owner who has a checking account in its currency.
+-- The rows referencing them go first, the money moved by them is lost.
+CREATE TEMP TABLE "dropped_accounts" AS
+SELECT "id" FROM "accounts" WHERE "owner" = 'system_interest'
+UNION
+SELECT "savings"."id" FROM "accounts" "savings"
+JOIN "accounts" "checking"
+  ON "checking"."owner" = "savings"."owner"
+  AND "checking"."currency" = "savings"."currency"
+  AND "checking"."account_type" = 'checking'
+WHERE "savings"."account_type" = 'savings';
+
+DELETE FROM "holds"
+WHERE "account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "transfer_id" IN (
+    SELECT "id" FROM "transfers"
+    WHERE "from_account_id" IN (SELECT "id" FROM "dropped_accounts")
+      OR "to_account_id" IN (SELECT "id" FROM "dropped_accounts")
+  );
+
+DELETE FROM "transfers"
+WHERE "from_account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "to_account_id" IN (SELECT "id" FROM "dropped_accounts");
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "dropped_accounts");
+
+DELETE FROM "accounts" WHERE "id" IN (SELECT "id" FROM "dropped_accounts");
+
+DROP TABLE "dropped_accounts";
+
+DELETE FROM "users" WHERE "username" = 'system_interest';
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_type_key";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_account_type_check";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "account_type";
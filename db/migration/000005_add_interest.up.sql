@@ -0,0 +1,54 @@
+ALTER TABLE "accounts" ADD COLUMN "account_type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_account_type_check" CHECK ("account_type" IN ('checking', 'savings'));
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_key";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_type_key" UNIQUE ("owner", "currency", "account_type");
+
+-- annual rate in basis points, the latest rate effective on a day applies to that day
+CREATE TABLE "interest_rates" (
+  "id" bigserial PRIMARY KEY,
+  "account_type" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "annual_rate_bps" bigint NOT NULL,
+  "effective_from" date NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "interest_rates" ADD CONSTRAINT "interest_rates_rate_check" CHECK ("annual_rate_bps" >= 0);
+
+ALTER TABLE "interest_rates" ADD CONSTRAINT "interest_rates_effective_key" UNIQUE ("account_type", "currency", "effective_from");
+
+-- one row per account per day, "remainder" is the part of the daily interest
+-- smaller than one minor unit and is carried to the next day so nothing is lost
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate_bps" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "remainder" bigint NOT NULL,
+  "entry_id" bigint,
+  "posted_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "interest_accruals" ADD CONSTRAINT "interest_accruals_account_date_key" UNIQUE ("account_id", "accrual_date");
+
+CREATE INDEX ON "interest_accruals" ("posted_at", "accrual_date");
+
+-- system user owning the accounts interest is paid from, it has no usable password
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('system_interest', '', 'Interest Expense', 'interest@system.simplebank');
+
+INSERT INTO "accounts" ("owner", "balance", "available_balance", "currency")
+VALUES
+  ('system_interest', 0, 0, 'USD'),
+  ('system_interest', 0, 0, 'EUR'),
+  ('system_interest', 0, 0, 'IDR');
@@ -0,0 +1,13 @@
+DELETE FROM "interest_accruals" WHERE "kind" = 'overdraft';
+
+ALTER TABLE IF EXISTS "interest_accruals" DROP CONSTRAINT IF EXISTS "interest_accruals_account_date_kind_key";
+
+ALTER TABLE IF EXISTS "interest_accruals" ADD CONSTRAINT "interest_accruals_account_date_key" UNIQUE ("account_id", "accrual_date");
+
+ALTER TABLE IF EXISTS "interest_accruals" DROP COLUMN IF EXISTS "kind";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "overdraft_rate_bps";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "overdraft_limit";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,22 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'banker'));
+
+-- the available balance may go down to -overdraft_limit,
+-- the negative balance is charged overdraft_rate_bps a year
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "overdraft_rate_bps" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_overdraft_check" CHECK ("overdraft_limit" >= 0 AND "overdraft_rate_bps" >= 0);
+
+CREATE INDEX ON "accounts" ("balance") WHERE "balance" < 0;
+
+-- overdraft interest is accrued next to savings interest with a negative amount
+ALTER TABLE "interest_accruals" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'interest';
+
+ALTER TABLE "interest_accruals" ADD CONSTRAINT "interest_accruals_kind_check" CHECK ("kind" IN ('interest', 'overdraft'));
+
+ALTER TABLE "interest_accruals" DROP CONSTRAINT IF EXISTS "interest_accruals_account_date_key";
+
+ALTER TABLE "interest_accruals" ADD CONSTRAINT "interest_accruals_account_date_kind_key" UNIQUE ("account_id", "accrual_date", "kind");
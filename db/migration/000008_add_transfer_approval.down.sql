@@ -0,0 +1,12 @@
+-- transfers which never completed have no entries, they would look completed without a status
+DELETE FROM "transfers" WHERE "status" <> 'completed';
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "decided_at";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "decided_by";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "expires_at";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "status";
+
+DROP TABLE IF EXISTS "approval_thresholds";
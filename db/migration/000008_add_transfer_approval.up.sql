@@ -0,0 +1,24 @@
+-- transfers at or above the threshold of their currency wait in "pending" until the
+-- owner approves them, only then entries are written and balances change.
+-- a currency without a threshold never needs approval.
+CREATE TABLE "approval_thresholds" (
+  "currency" varchar PRIMARY KEY,
+  "min_amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "approval_thresholds" ADD CONSTRAINT "approval_thresholds_amount_check" CHECK ("min_amount" > 0);
+
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+
+ALTER TABLE "transfers" ADD COLUMN "expires_at" timestamptz;
+
+ALTER TABLE "transfers" ADD COLUMN "decided_by" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "decided_at" timestamptz;
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_status_check" CHECK ("status" IN ('pending', 'completed', 'rejected', 'expired'));
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("decided_by") REFERENCES "users" ("username");
+
+CREATE INDEX ON "transfers" ("status", "expires_at");
@@ -0,0 +1,47 @@
+-- a batch is a set of transfers out of one account uploaded at once, the rows keep
+-- the per-row outcome so the report can be fetched after the upload.
+-- "all_or_nothing" batches run in a single transaction, "best_effort" batches run
+-- every row in its own transaction.
+CREATE TABLE "transfer_batches" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "created_by" varchar NOT NULL,
+  "mode" varchar NOT NULL,
+  "status" varchar NOT NULL,
+  "total_rows" integer NOT NULL,
+  "succeeded_rows" integer NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_batch_rows" (
+  "id" bigserial PRIMARY KEY,
+  "batch_id" bigint NOT NULL,
+  "row_number" integer NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "reference" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL,
+  "transfer_id" bigint,
+  "error" varchar NOT NULL DEFAULT ''
+);
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_batches" ADD CONSTRAINT "transfer_batches_mode_check" CHECK ("mode" IN ('all_or_nothing', 'best_effort'));
+
+ALTER TABLE "transfer_batches" ADD CONSTRAINT "transfer_batches_status_check" CHECK ("status" IN ('completed', 'partially_completed', 'failed'));
+
+ALTER TABLE "transfer_batch_rows" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "transfer_batch_rows" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_rows" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_batch_rows" ADD CONSTRAINT "transfer_batch_rows_status_check" CHECK ("status" IN ('succeeded', 'failed', 'skipped'));
+
+ALTER TABLE "transfer_batch_rows" ADD CONSTRAINT "transfer_batch_rows_batch_row_key" UNIQUE ("batch_id", "row_number");
+
+CREATE INDEX ON "transfer_batches" ("from_account_id");
@@ -0,0 +1,20 @@
+-- people sharing an account besides accounts.owner, who is always an owner.
+-- owners manage the members, co-owners can move money and viewers can only read.
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "invited_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username")
+);
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD CONSTRAINT "account_members_role_check" CHECK ("role" IN ('owner', 'co_owner', 'viewer'));
+
+CREATE INDEX ON "account_members" ("username");
@@ -0,0 +1,59 @@
+DROP TABLE IF EXISTS "transfer_approvals";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "required_approvals";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "initiated_by";
+
+-- the organization accounts go, the rows referencing them and their transfers go
+-- first, the money moved by them is lost
+CREATE TEMP TABLE "dropped_accounts" AS
+SELECT "id" FROM "accounts" WHERE "organization_id" IS NOT NULL;
+
+CREATE TEMP TABLE "dropped_transfers" AS
+SELECT "id" FROM "transfers"
+WHERE "from_account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "to_account_id" IN (SELECT "id" FROM "dropped_accounts");
+
+DELETE FROM "interest_accruals"
+WHERE "account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "entry_id" IN (
+    SELECT "id" FROM "entries"
+    WHERE "account_id" IN (SELECT "id" FROM "dropped_accounts")
+      OR "transfer_id" IN (SELECT "id" FROM "dropped_transfers")
+  );
+
+DELETE FROM "transfer_batch_rows"
+WHERE "to_account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "transfer_id" IN (SELECT "id" FROM "dropped_transfers");
+
+DELETE FROM "transfer_batches" WHERE "from_account_id" IN (SELECT "id" FROM "dropped_accounts");
+
+DELETE FROM "holds"
+WHERE "account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "transfer_id" IN (SELECT "id" FROM "dropped_transfers");
+
+DELETE FROM "entries"
+WHERE "account_id" IN (SELECT "id" FROM "dropped_accounts")
+  OR "transfer_id" IN (SELECT "id" FROM "dropped_transfers");
+
+DELETE FROM "transfers" WHERE "id" IN (SELECT "id" FROM "dropped_transfers");
+
+DELETE FROM "accounts" WHERE "id" IN (SELECT "id" FROM "dropped_accounts");
+
+DROP TABLE "dropped_transfers";
+
+DROP TABLE "dropped_accounts";
+
+DROP INDEX IF EXISTS "organization_currency_type_key";
+
+DROP INDEX IF EXISTS "owner_currency_type_key";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_type_key" UNIQUE ("owner", "currency", "account_type");
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "organization_id";
+
+DROP TABLE IF EXISTS "spending_policies";
+
+DROP TABLE IF EXISTS "organization_members";
+
+DROP TABLE IF EXISTS "organizations";
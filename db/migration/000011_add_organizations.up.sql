@@ -0,0 +1,78 @@
+-- organizations own business accounts, what a member may do with them depends on
+-- their role: admins manage the organization, initiators send transfers and
+-- approvers approve them.
+CREATE TABLE "organizations" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "organizations" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+CREATE TABLE "organization_members" (
+  "organization_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("organization_id", "username")
+);
+
+ALTER TABLE "organization_members" ADD FOREIGN KEY ("organization_id") REFERENCES "organizations" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "organization_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "organization_members" ADD CONSTRAINT "organization_members_role_check" CHECK ("role" IN ('admin', 'initiator', 'approver'));
+
+CREATE INDEX ON "organization_members" ("username");
+
+-- a transfer from an organization account of at least min_amount needs
+-- required_approvals approvals, the policy with the highest min_amount wins
+CREATE TABLE "spending_policies" (
+  "id" bigserial PRIMARY KEY,
+  "organization_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "min_amount" bigint NOT NULL,
+  "required_approvals" integer NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "spending_policies" ADD FOREIGN KEY ("organization_id") REFERENCES "organizations" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "spending_policies" ADD CONSTRAINT "spending_policies_amount_check" CHECK ("min_amount" > 0);
+
+ALTER TABLE "spending_policies" ADD CONSTRAINT "spending_policies_approvals_check" CHECK ("required_approvals" BETWEEN 1 AND 10);
+
+ALTER TABLE "spending_policies" ADD CONSTRAINT "organization_currency_amount_key" UNIQUE ("organization_id", "currency", "min_amount");
+
+-- accounts.owner of an organization account is the admin who opened it,
+-- only personal accounts are unique per owner
+ALTER TABLE "accounts" ADD COLUMN "organization_id" bigint;
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("organization_id") REFERENCES "organizations" ("id");
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_type_key";
+
+CREATE UNIQUE INDEX "owner_currency_type_key" ON "accounts" ("owner", "currency", "account_type") WHERE "organization_id" IS NULL;
+
+CREATE UNIQUE INDEX "organization_currency_type_key" ON "accounts" ("organization_id", "currency", "account_type") WHERE "organization_id" IS NOT NULL;
+
+-- a pending transfer moves money once it has required_approvals approvals
+ALTER TABLE "transfers" ADD COLUMN "initiated_by" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "required_approvals" integer NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_required_approvals_check" CHECK ("required_approvals" >= 1);
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("initiated_by") REFERENCES "users" ("username");
+
+CREATE TABLE "transfer_approvals" (
+  "transfer_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("transfer_id", "username")
+);
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
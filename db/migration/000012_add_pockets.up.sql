@@ -0,0 +1,26 @@
+-- pockets set money of an account aside for a goal. Money moved into a pocket leaves
+-- the balance of the account, the consolidated balance is the balance of the account
+-- plus the balance of its pockets.
+CREATE TABLE "pockets" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "name" varchar NOT NULL,
+  "balance" bigint NOT NULL DEFAULT 0,
+  "goal_amount" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "pockets" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "pockets" ADD CONSTRAINT "pockets_balance_check" CHECK ("balance" >= 0);
+
+ALTER TABLE "pockets" ADD CONSTRAINT "pockets_goal_amount_check" CHECK ("goal_amount" > 0);
+
+ALTER TABLE "pockets" ADD CONSTRAINT "account_pocket_name_key" UNIQUE ("account_id", "name");
+
+-- the entry of the account for a move into or out of a pocket
+ALTER TABLE "entries" ADD COLUMN "pocket_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("pocket_id") REFERENCES "pockets" ("id") ON DELETE SET NULL;
+
+CREATE INDEX ON "entries" ("pocket_id");
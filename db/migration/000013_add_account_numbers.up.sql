@@ -0,0 +1,14 @@
+-- the external number of an account, see util.IsValidAccountNumber for the format.
+-- the default runs for every existing row as well because the function is volatile.
+CREATE FUNCTION "generate_account_number"() RETURNS varchar AS $$
+DECLARE
+  basic varchar := lpad(floor(random() * 1000000000000)::bigint::text, 12, '0');
+BEGIN
+  -- "SB" is 2811 with the letters replaced by numbers
+  RETURN 'SB' || lpad((98 - (basic || '281100')::numeric % 97)::text, 2, '0') || basic;
+END;
+$$ LANGUAGE plpgsql VOLATILE;
+
+ALTER TABLE "accounts" ADD COLUMN "account_number" varchar NOT NULL DEFAULT generate_account_number();
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_account_number_key" UNIQUE ("account_number");
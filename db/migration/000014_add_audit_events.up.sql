@@ -0,0 +1,41 @@
+-- audit_events is an append-only log of the state changing actions, written in the
+-- same transaction as the change. Every event carries the hash of the event before it,
+-- see db.AuditEvent.ComputeHash, so an edited or removed event breaks the chain.
+-- before and after are json rather than jsonb to keep the hashed text as written.
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "entity" varchar NOT NULL,
+  "entity_id" varchar NOT NULL,
+  "before" json NOT NULL,
+  "after" json NOT NULL,
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "request_id" varchar NOT NULL DEFAULT '',
+  "prev_hash" varchar NOT NULL,
+  "hash" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- two events can't follow the same event, the chain stays a single line
+ALTER TABLE "audit_events" ADD CONSTRAINT "audit_events_prev_hash_key" UNIQUE ("prev_hash");
+
+ALTER TABLE "audit_events" ADD CONSTRAINT "audit_events_hash_key" UNIQUE ("hash");
+
+CREATE INDEX ON "audit_events" ("actor");
+
+CREATE INDEX ON "audit_events" ("entity", "entity_id");
+
+CREATE FUNCTION "audit_events_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_no_update_or_delete"
+BEFORE UPDATE OR DELETE ON "audit_events"
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER "audit_events_no_truncate"
+BEFORE TRUNCATE ON "audit_events"
+FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
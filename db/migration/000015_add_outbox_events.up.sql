@@ -0,0 +1,17 @@
+-- outbox_events are the domain events for other services, written in the same
+-- transaction as the change and published later by worker.OutboxRelay.
+-- An event may be published more than once, consumers dedupe by id.
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" varchar NOT NULL,
+  "schema_version" int NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "outbox_events" ("next_attempt_at") WHERE "published_at" IS NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "webhook_attempts";
+
+DROP TABLE IF EXISTS "webhook_deliveries";
+
+DROP TABLE IF EXISTS "webhook_endpoints";
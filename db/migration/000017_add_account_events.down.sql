@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS "accounts_notify_balance" ON "accounts";
+
+DROP TRIGGER IF EXISTS "entries_notify_created" ON "entries";
+
+DROP FUNCTION IF EXISTS "notify_account_balance";
+
+DROP FUNCTION IF EXISTS "notify_entry_created";
@@ -0,0 +1,38 @@
+-- the notifications are only sent when the transaction commits,
+-- a rolled back transfer is never streamed to the clients
+
+CREATE FUNCTION "notify_entry_created"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('account_events', json_build_object(
+    'type', 'entry',
+    'account_id', NEW.account_id,
+    'entry', row_to_json(NEW)
+  )::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "entries_notify_created"
+AFTER INSERT ON "entries"
+FOR EACH ROW EXECUTE FUNCTION notify_entry_created();
+
+CREATE FUNCTION "notify_account_balance"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('account_events', json_build_object(
+    'type', 'balance',
+    'account_id', NEW.id,
+    'balance', json_build_object(
+      'balance', NEW.balance,
+      'available_balance', NEW.available_balance,
+      'currency', NEW.currency
+    )
+  )::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "accounts_notify_balance"
+AFTER UPDATE ON "accounts"
+FOR EACH ROW
+WHEN (OLD.balance IS DISTINCT FROM NEW.balance OR OLD.available_balance IS DISTINCT FROM NEW.available_balance)
+EXECUTE FUNCTION notify_account_balance();
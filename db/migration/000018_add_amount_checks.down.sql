@@ -0,0 +1,3 @@
+ALTER TABLE "entries" DROP CONSTRAINT IF EXISTS "entries_amount_check";
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_fee_check";
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_amount_check";
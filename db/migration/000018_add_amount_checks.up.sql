@@ -0,0 +1,6 @@
+-- the api bounds every amount, these keep a bug or a manual insert from moving
+-- money the wrong way. Entries are signed, a debit is negative, so they only
+-- refuse an entry of nothing.
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_amount_check" CHECK ("amount" > 0);
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_fee_check" CHECK ("fee" >= 0);
+ALTER TABLE "entries" ADD CONSTRAINT "entries_amount_check" CHECK ("amount" <> 0);
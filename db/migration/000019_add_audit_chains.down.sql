@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "audit_events_chain_id_idx";
+
+ALTER TABLE "audit_events" DROP CONSTRAINT IF EXISTS "audit_events_chain_prev_hash_key";
+
+-- the chains can't be merged back into a single line, every chain starts with an
+-- empty prev_hash, so prev_hash stays without its unique constraint
+ALTER TABLE "audit_events" DROP COLUMN IF EXISTS "chain";
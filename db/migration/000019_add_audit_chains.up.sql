@@ -0,0 +1,11 @@
+-- the audit log is split in chains, see db.auditChains, so that the transactions
+-- appending to different chains don't wait for each other. The events written
+-- before are the start of chain 0.
+ALTER TABLE "audit_events" ADD COLUMN "chain" integer NOT NULL DEFAULT 0;
+
+-- two events can't follow the same event, every chain stays a single line
+ALTER TABLE "audit_events" DROP CONSTRAINT "audit_events_prev_hash_key";
+
+ALTER TABLE "audit_events" ADD CONSTRAINT "audit_events_chain_prev_hash_key" UNIQUE ("chain", "prev_hash");
+
+CREATE INDEX ON "audit_events" ("chain", "id");
@@ -0,0 +1,113 @@
+package migration
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	names, err := files.ReadDir(".")
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+
+	version, err := LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint(len(names)), version)
+}
+
+// openScratchDatabase creates an empty database on the server of the .env file for
+// the test and drops it afterwards, the test is skipped without a server
+func openScratchDatabase(t *testing.T) *sql.DB {
+	godotenv.Load("../../.env")
+	if os.Getenv("POSTGRES_HOST") == "" {
+		t.Skip("POSTGRES_HOST is not set, the migrations need a database")
+	}
+
+	source := func(dbName string) string {
+		return fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable",
+			os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"),
+			os.Getenv("POSTGRES_HOST"), os.Getenv("POSTGRES_PORT"), dbName)
+	}
+
+	server, err := sql.Open("postgres", source(os.Getenv("POSTGRES_DATABASE")))
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	name := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	_, err = server.Exec(`CREATE DATABASE ` + name)
+	require.NoError(t, err)
+
+	conn, err := sql.Open("postgres", source(name))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		server.Exec(`DROP DATABASE ` + name + ` WITH (FORCE)`)
+	})
+	return conn
+}
+
+// runMigrations applies the migration files matching the pattern in the order given
+func runMigrations(t *testing.T, conn *sql.DB, pattern string, reverse bool) {
+	names, err := fs.Glob(os.DirFS("."), pattern)
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+
+	slices.Sort(names)
+	if reverse {
+		slices.Reverse(names)
+	}
+	for _, name := range names {
+		query, err := os.ReadFile(name)
+		require.NoError(t, err)
+		_, err = conn.Exec(string(query))
+		require.NoError(t, err, name)
+	}
+}
+
+// TestDownWithOrganizationActivity migrates all the way down a database in which an
+// organization account moved money, every row referencing it has to go first
+func TestDownWithOrganizationActivity(t *testing.T) {
+	conn := openScratchDatabase(t)
+	runMigrations(t, conn, "*.up.sql", false)
+
+	_, err := conn.Exec(`
+INSERT INTO users (username, hashed_password, full_name, email)
+VALUES ('alice', '', 'Alice', 'alice@example.com'), ('bob', '', 'Bob', 'bob@example.com');
+
+INSERT INTO organizations (id, name, created_by) VALUES (1, 'Acme', 'bob');
+INSERT INTO organization_members (organization_id, username, role) VALUES (1, 'bob', 'admin');
+INSERT INTO spending_policies (organization_id, currency, min_amount, required_approvals) VALUES (1, 'USD', 1000, 1);
+
+INSERT INTO accounts (id, owner, balance, available_balance, currency, organization_id)
+VALUES (1001, 'bob', 900, 890, 'USD', 1), (1002, 'alice', 100, 100, 'USD', NULL);
+INSERT INTO account_members (account_id, username, role, invited_by) VALUES (1001, 'alice', 'viewer', 'bob');
+
+INSERT INTO transfers (id, from_account_id, to_account_id, amount, status, initiated_by, required_approvals)
+VALUES (2001, 1001, 1002, 100, 'completed', 'bob', 1), (2002, 1001, 1002, 5000, 'pending', 'bob', 1);
+INSERT INTO transfer_approvals (transfer_id, username) VALUES (2002, 'bob');
+INSERT INTO entries (id, account_id, amount, transfer_id)
+VALUES (3001, 1001, -100, 2001), (3002, 1002, 100, 2001), (3003, 1001, 1, NULL);
+
+INSERT INTO holds (account_id, amount, status, transfer_id, expires_at)
+VALUES (1001, 100, 'captured', 2001, now()), (1001, 10, 'pending', NULL, now() + interval '1 day');
+INSERT INTO interest_accruals (account_id, accrual_date, balance, annual_rate_bps, amount, remainder, entry_id, posted_at)
+VALUES (1001, '2024-01-01', 1000, 100, 1, 0, 3003, now());
+
+INSERT INTO transfer_batches (id, from_account_id, created_by, mode, status, total_rows, succeeded_rows)
+VALUES (4001, 1001, 'bob', 'best_effort', 'completed', 1, 1);
+INSERT INTO transfer_batch_rows (batch_id, row_number, to_account_id, amount, status, transfer_id)
+VALUES (4001, 1, 1002, 100, 'succeeded', 2001);
+`)
+	require.NoError(t, err)
+
+	runMigrations(t, conn, "*.down.sql", true)
+}
@@ -1,8 +1,8 @@
 -- name: CreateAccount :one
 INSERT INTO accounts (
-  owner, balance, currency
+  owner, balance, available_balance, currency, account_type, organization_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $2, $3, $4, $5
 )
 RETURNING *;
 
@@ -10,29 +10,113 @@ RETURNING *;
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByNumber :one
+SELECT * FROM accounts
+WHERE account_number = $1 LIMIT 1;
+
+-- name: GetAccountByOwnerAndCurrency :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 AND account_type = $3 AND organization_id IS NULL LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
 -- name: ListAccounts :many
+-- personal accounts owned by the user or shared with them
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE organization_id IS NULL AND (owner = $1 OR id IN (
+  SELECT account_id FROM account_members WHERE username = $1
+))
+ORDER BY id
 LIMIT $2
 OFFSET $3;
 
+-- name: ListAccountsAfter :many
+-- ListAccounts paginated by a cursor, the page starts after the account with after_id
+SELECT * FROM accounts
+WHERE organization_id IS NULL AND (owner = @owner OR id IN (
+  SELECT account_id FROM account_members WHERE username = @owner
+)) AND id > @after_id
+ORDER BY id
+LIMIT @page_limit;
+
+-- name: GetAccountsByIDs :many
+SELECT * FROM accounts
+WHERE id = ANY(@ids::bigint[])
+ORDER BY id;
+
+-- name: ListOrganizationAccounts :many
+SELECT * FROM accounts
+WHERE organization_id = $1
+ORDER BY id;
+
 -- name: UpdateAccount :one
 UPDATE accounts
-  set balance = $2
+  set balance = $2,
+  available_balance = available_balance + $2 - balance
 WHERE id = $1
 RETURNING *;
 
 -- name: AddAccountBalance :one
+UPDATE accounts
+  set balance = balance + @amount,
+  available_balance = available_balance + @amount
+WHERE id = $1
+RETURNING *;
+
+-- name: DebitAccountBalance :one
+-- fails with no rows when the debit goes past the overdraft limit
+UPDATE accounts
+  set balance = balance - @amount,
+  available_balance = available_balance - @amount
+WHERE id = $1 AND available_balance + overdraft_limit >= @amount
+RETURNING *;
+
+-- name: WithdrawAccountBalance :one
+-- like DebitAccountBalance but never dips into the overdraft,
+-- fails with no rows when the available balance is short
+UPDATE accounts
+  set balance = balance - @amount,
+  available_balance = available_balance - @amount
+WHERE id = $1 AND available_balance >= @amount
+RETURNING *;
+
+-- name: AddAccountLedgerBalance :one
+-- only touch the ledger balance, used when capturing a hold
+-- whose amount has already been taken from the available balance
 UPDATE accounts
   set balance = balance + @amount
 WHERE id = $1
 RETURNING *;
 
+-- name: ReserveAccountBalance :one
+UPDATE accounts
+  set available_balance = available_balance - @amount
+WHERE id = $1 AND available_balance + overdraft_limit >= @amount
+RETURNING *;
+
+-- name: UpdateAccountOverdraft :one
+UPDATE accounts
+  set overdraft_limit = $2,
+  overdraft_rate_bps = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: ListOverdrawnAccounts :many
+SELECT * FROM accounts
+WHERE balance < 0 AND owner NOT LIKE 'system\_%'
+ORDER BY balance, id
+LIMIT $1
+OFFSET $2;
+
+-- name: ReleaseAccountBalance :one
+UPDATE accounts
+  set available_balance = available_balance + @amount
+WHERE id = $1
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1;
@@ -0,0 +1,27 @@
+-- name: AddAccountMember :one
+-- adding an existing member changes their role
+INSERT INTO account_members (
+  account_id, username, role, invited_by
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id, username) DO UPDATE SET role = EXCLUDED.role
+RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2 LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM account_members
+WHERE account_id = $1
+ORDER BY created_at, username;
+
+-- name: ListAccountMembershipsOfUser :many
+SELECT * FROM account_members
+WHERE username = @username AND account_id = ANY(@account_ids::bigint[]);
+
+-- name: RemoveAccountMember :one
+DELETE FROM account_members
+WHERE account_id = $1 AND username = $2
+RETURNING *;
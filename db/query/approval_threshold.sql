@@ -0,0 +1,16 @@
+-- name: SetApprovalThreshold :one
+INSERT INTO approval_thresholds (
+  currency, min_amount
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (currency) DO UPDATE SET min_amount = EXCLUDED.min_amount
+RETURNING *;
+
+-- name: GetApprovalThreshold :one
+SELECT * FROM approval_thresholds
+WHERE currency = $1 LIMIT 1;
+
+-- name: DeleteApprovalThreshold :exec
+DELETE FROM approval_thresholds
+WHERE currency = $1;
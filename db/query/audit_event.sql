@@ -0,0 +1,45 @@
+-- name: LockAuditChain :exec
+-- held until the end of the transaction, only one transaction at a time
+-- may append to a chain
+SELECT pg_advisory_xact_lock(hashtext('audit_events'), sqlc.arg(chain)::int);
+
+-- name: GetLastAuditEvent :one
+SELECT * FROM audit_events
+WHERE chain = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  actor,
+  action,
+  entity,
+  entity_id,
+  before,
+  after,
+  client_ip,
+  request_id,
+  prev_hash,
+  hash,
+  created_at,
+  chain
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(entity)::varchar IS NULL OR entity = sqlc.narg(entity))
+  AND (sqlc.narg(entity_id)::varchar IS NULL OR entity_id = sqlc.narg(entity_id))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListAuditEventsAfter :many
+SELECT * FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
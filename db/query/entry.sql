@@ -1,8 +1,16 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id, description
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: CreatePocketEntry :one
+INSERT INTO entries (
+  account_id, amount, pocket_id, description
+) VALUES (
+  $1, $2, $3, $4
 )
 RETURNING *;
 
@@ -17,6 +25,23 @@ ORDER BY id
 LIMIT $2
 OFFSET $3;
 
+-- name: ListEntriesAfterOfAccounts :many
+-- a page of entries for every account, the graphql loader batches the connections with it
+SELECT entries.* FROM unnest(@account_ids::bigint[]) AS a(account_id)
+JOIN LATERAL (
+  SELECT id FROM entries e
+  WHERE e.account_id = a.account_id AND e.id > @after_id
+  ORDER BY e.id
+  LIMIT @page_limit
+) page ON true
+JOIN entries ON entries.id = page.id
+ORDER BY entries.account_id, entries.id;
+
+-- name: ListEntriesByTransfer :many
+SELECT * FROM entries
+WHERE transfer_id = $1
+ORDER BY id;
+
 -- name: DeleteEntryByAccountID :exec
 -- for testing purpose
 DELETE FROM entries
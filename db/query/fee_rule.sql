@@ -0,0 +1,27 @@
+-- name: CreateFeeRule :one
+INSERT INTO fee_rules (
+  currency, account_type, min_amount, max_amount, flat_fee, basis_points
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetMatchingFeeRule :one
+-- rules for a specific account type win over rules for every account type,
+-- then the newest rule wins
+SELECT * FROM fee_rules
+WHERE currency = @currency
+  AND (account_type IS NULL OR account_type = @account_type::varchar)
+  AND min_amount <= @amount::bigint
+  AND (max_amount IS NULL OR @amount::bigint < max_amount)
+ORDER BY account_type NULLS LAST, id DESC
+LIMIT 1;
+
+-- name: ListFeeRules :many
+SELECT * FROM fee_rules
+WHERE currency = $1
+ORDER BY id;
+
+-- name: DeleteFeeRule :exec
+DELETE FROM fee_rules
+WHERE id = $1;
@@ -0,0 +1,106 @@
+-- name: CreateInterestRate :one
+INSERT INTO interest_rates (
+  account_type, currency, annual_rate_bps, effective_from
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: DeleteInterestRate :exec
+DELETE FROM interest_rates
+WHERE id = $1;
+
+-- name: ListInterestBearingAccounts :many
+-- savings accounts not accrued yet on accrual_date, with the rate effective on that day
+-- and the balance at the end of that day rebuilt from the entries made after it
+SELECT
+  a.id,
+  a.currency,
+  (a.balance - COALESCE((
+    SELECT SUM(e.amount) FROM entries e
+    WHERE e.account_id = a.id AND e.created_at >= @day_end::timestamptz
+  ), 0))::bigint AS balance,
+  r.annual_rate_bps
+FROM accounts a
+JOIN LATERAL (
+  SELECT ir.annual_rate_bps FROM interest_rates ir
+  WHERE ir.account_type = a.account_type
+    AND ir.currency = a.currency
+    AND ir.effective_from <= @accrual_date::date
+  ORDER BY ir.effective_from DESC
+  LIMIT 1
+) r ON true
+WHERE a.account_type = 'savings'
+  AND a.created_at < @day_end::timestamptz
+  AND NOT EXISTS (
+    SELECT 1 FROM interest_accruals ia
+    WHERE ia.account_id = a.id AND ia.accrual_date = @accrual_date::date AND ia.kind = 'interest'
+  )
+ORDER BY a.id;
+
+-- name: ListOverdraftChargeableAccounts :many
+-- accounts with an overdraft rate not charged yet on accrual_date,
+-- with the balance at the end of that day rebuilt from the entries made after it
+SELECT * FROM (
+  SELECT
+    a.id,
+    a.currency,
+    (a.balance - COALESCE((
+      SELECT SUM(e.amount) FROM entries e
+      WHERE e.account_id = a.id AND e.created_at >= @day_end::timestamptz
+    ), 0))::bigint AS balance,
+    a.overdraft_rate_bps
+  FROM accounts a
+  WHERE a.overdraft_rate_bps > 0
+    AND a.created_at < @day_end::timestamptz
+    AND NOT EXISTS (
+      SELECT 1 FROM interest_accruals ia
+      WHERE ia.account_id = a.id AND ia.accrual_date = @accrual_date::date AND ia.kind = 'overdraft'
+    )
+) day_balances
+WHERE balance < 0
+ORDER BY id;
+
+-- name: GetLastInterestAccrual :one
+SELECT * FROM interest_accruals
+WHERE account_id = $1 AND kind = $2 AND accrual_date < $3
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+  account_id, kind, accrual_date, balance, annual_rate_bps, amount, remainder
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (account_id, accrual_date, kind) DO NOTHING
+RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date, kind
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posted_at IS NULL AND accrual_date < $1
+ORDER BY account_id;
+
+-- name: ListUnpostedInterestAccrualsForUpdate :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1 AND posted_at IS NULL AND accrual_date < $2
+ORDER BY accrual_date, kind
+FOR NO KEY UPDATE;
+
+-- name: MarkInterestAccrualsPosted :exec
+UPDATE interest_accruals
+  set entry_id = $2,
+  posted_at = now()
+WHERE account_id = $1 AND posted_at IS NULL AND accrual_date < $3;
+
+-- name: DeleteInterestAccrualsByAccountID :exec
+-- for testing purpose
+DELETE FROM interest_accruals
+WHERE account_id = $1;
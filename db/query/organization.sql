@@ -0,0 +1,71 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (
+  name, created_by
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetOrganization :one
+SELECT * FROM organizations
+WHERE id = $1 LIMIT 1;
+
+-- name: ListOrganizationsByMember :many
+SELECT organizations.* FROM organizations
+JOIN organization_members ON organization_members.organization_id = organizations.id
+WHERE organization_members.username = $1
+ORDER BY organizations.id;
+
+-- name: DeleteOrganization :exec
+DELETE FROM organizations
+WHERE id = $1;
+
+-- name: AddOrganizationMember :one
+-- adding an existing member changes their role
+INSERT INTO organization_members (
+  organization_id, username, role
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (organization_id, username) DO UPDATE SET role = EXCLUDED.role
+RETURNING *;
+
+-- name: GetOrganizationMember :one
+SELECT * FROM organization_members
+WHERE organization_id = $1 AND username = $2 LIMIT 1;
+
+-- name: ListOrganizationMembers :many
+SELECT * FROM organization_members
+WHERE organization_id = $1
+ORDER BY created_at, username;
+
+-- name: RemoveOrganizationMember :one
+DELETE FROM organization_members
+WHERE organization_id = $1 AND username = $2
+RETURNING *;
+
+-- name: SetSpendingPolicy :one
+INSERT INTO spending_policies (
+  organization_id, currency, min_amount, required_approvals
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (organization_id, currency, min_amount) DO UPDATE SET required_approvals = EXCLUDED.required_approvals
+RETURNING *;
+
+-- name: GetSpendingPolicy :one
+-- the policy that applies to a transfer of the amount, the one with the highest min_amount
+SELECT * FROM spending_policies
+WHERE organization_id = $1 AND currency = $2 AND min_amount <= @amount
+ORDER BY min_amount DESC
+LIMIT 1;
+
+-- name: ListSpendingPolicies :many
+SELECT * FROM spending_policies
+WHERE organization_id = $1
+ORDER BY currency, min_amount;
+
+-- name: DeleteSpendingPolicy :one
+DELETE FROM spending_policies
+WHERE id = $1 AND organization_id = $2
+RETURNING *;
@@ -0,0 +1,42 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (
+  event_type,
+  schema_version,
+  aggregate_id,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ClaimDueOutboxEvents :many
+-- claims the due events for the lease, concurrent relays skip them until it ends
+-- and a relay which crashes before marking them leaves them due again
+UPDATE outbox_events
+  set next_attempt_at = now() + sqlc.arg(lease_seconds)::int * interval '1 second'
+WHERE id IN (
+  SELECT id FROM outbox_events
+  WHERE published_at IS NULL AND next_attempt_at <= now()
+  ORDER BY id
+  LIMIT sqlc.arg(row_limit)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+  set published_at = now(),
+  attempts = attempts + 1
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+-- the retries back off exponentially up to an hour apart
+UPDATE outbox_events
+  set attempts = attempts + 1,
+  last_error = $2,
+  next_attempt_at = now() + least(power(2, attempts), 3600) * interval '1 second'
+WHERE id = $1;
+
+-- name: GetOutboxEvent :one
+SELECT * FROM outbox_events
+WHERE id = $1 LIMIT 1;
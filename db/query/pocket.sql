@@ -0,0 +1,40 @@
+-- name: CreatePocket :one
+INSERT INTO pockets (
+  account_id, name, goal_amount
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetPocket :one
+SELECT * FROM pockets
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPocketForUpdate :one
+SELECT * FROM pockets
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPockets :many
+SELECT * FROM pockets
+WHERE account_id = $1
+ORDER BY id;
+
+-- name: UpdatePocket :one
+UPDATE pockets
+  set name = $2,
+  goal_amount = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: AddPocketBalance :one
+-- fails with no rows when more is taken out than the pocket holds
+UPDATE pockets
+  set balance = balance + @amount
+WHERE id = $1 AND balance + @amount >= 0
+RETURNING *;
+
+-- name: DeleteEmptyPocket :one
+DELETE FROM pockets
+WHERE id = $1 AND balance = 0
+RETURNING *;
@@ -1,8 +1,17 @@
 -- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, fee, description, reference, metadata
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: CreatePendingTransfer :one
+INSERT INTO transfers (
+  from_account_id, to_account_id, amount, description, reference, metadata, status, expires_at,
+  initiated_by, required_approvals
+) VALUES (
+  $1, $2, $3, $4, $5, $6, 'pending', $7, $8, $9
 )
 RETURNING *;
 
@@ -10,6 +19,41 @@ RETURNING *;
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: MarkTransferCompleted :one
+UPDATE transfers
+  set status = 'completed',
+  fee = $2,
+  decided_by = $3,
+  decided_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: MarkTransferRejected :one
+UPDATE transfers
+  set status = 'rejected',
+  decided_by = $2,
+  decided_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: ExpirePendingTransfers :many
+UPDATE transfers
+  set status = 'expired',
+  decided_at = now()
+WHERE id IN (
+  SELECT id FROM transfers
+  WHERE status = 'pending' AND expires_at <= now()
+  ORDER BY id
+  LIMIT $1
+  FOR NO KEY UPDATE SKIP LOCKED
+)
+RETURNING *;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
@@ -19,6 +63,38 @@ ORDER BY id
 LIMIT $3
 OFFSET $4;
 
+-- name: ListTransfersAfterOfAccounts :many
+-- a page of transfers for every account, the graphql loader batches the connections with it.
+-- A transfer between two of the accounts is in both pages.
+SELECT a.account_id::bigint AS account_id, sqlc.embed(transfers) FROM unnest(@account_ids::bigint[]) AS a(account_id)
+JOIN LATERAL (
+  SELECT id FROM transfers t
+  WHERE (t.from_account_id = a.account_id OR t.to_account_id = a.account_id) AND t.id > @after_id
+  ORDER BY t.id
+  LIMIT @page_limit
+) page ON true
+JOIN transfers ON transfers.id = page.id
+ORDER BY a.account_id, transfers.id;
+
+-- name: GetTransfersByIDs :many
+SELECT * FROM transfers
+WHERE id = ANY(@ids::bigint[])
+ORDER BY id;
+
+-- name: SearchTransfers :many
+-- transfers in and out of an account, optionally filtered by a text query on
+-- description and reference, an exact reference and metadata containment.
+-- strpos matches the query literally, ILIKE would take % and _ in it as wildcards.
+SELECT * FROM transfers
+WHERE
+  (from_account_id = @account_id OR to_account_id = @account_id) AND
+  (@query::text = '' OR strpos(lower(description), lower(@query::text)) > 0 OR strpos(lower(reference), lower(@query::text)) > 0) AND
+  (@reference::text = '' OR reference = @reference::text) AND
+  metadata @> @metadata::jsonb
+ORDER BY id DESC
+LIMIT @page_limit
+OFFSET @page_offset;
+
 -- name: DeleteTransfer :exec
 -- for testing purpose
 DELETE FROM transfers
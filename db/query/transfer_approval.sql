@@ -0,0 +1,17 @@
+-- name: CreateTransferApproval :execrows
+-- approving twice is a no-op, zero rows are affected
+INSERT INTO transfer_approvals (
+  transfer_id, username
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (transfer_id, username) DO NOTHING;
+
+-- name: CountTransferApprovals :one
+SELECT count(*) FROM transfer_approvals
+WHERE transfer_id = $1;
+
+-- name: ListTransferApprovals :many
+SELECT * FROM transfer_approvals
+WHERE transfer_id = $1
+ORDER BY created_at, username;
@@ -0,0 +1,29 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  from_account_id, created_by, mode, status, total_rows, succeeded_rows
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: CreateTransferBatchRow :one
+INSERT INTO transfer_batch_rows (
+  batch_id, row_number, to_account_id, amount, description, reference, status, transfer_id, error
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING *;
+
+-- name: ListTransferBatchRows :many
+SELECT * FROM transfer_batch_rows
+WHERE batch_id = $1
+ORDER BY row_number;
+
+-- name: DeleteTransferBatchesByAccountID :exec
+-- for testing purpose
+DELETE FROM transfer_batches
+WHERE from_account_id = $1;
@@ -10,6 +10,25 @@ RETURNING *;
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- name: GetUsersByUsernames :many
+SELECT * FROM users
+WHERE username = ANY(@usernames::text[])
+ORDER BY username;
+
+-- name: GetUserByUsernameOrEmail :one
+-- resolves a transfer recipient, system users can't receive transfers
+SELECT * FROM users
+WHERE
+  (username = @identifier::text OR lower(email) = lower(@identifier::text)) AND
+  username NOT LIKE 'system\_%'
+LIMIT 1;
+
+-- name: UpdateUserRole :one
+UPDATE users
+  set role = $2
+WHERE username = $1
+RETURNING *;
+
 -- name: DeleteUserByUsernameLike :exec
 -- for testing purpose
 DELETE FROM users
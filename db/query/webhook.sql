@@ -0,0 +1,102 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (
+  account_id,
+  url,
+  secret,
+  event_types,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookEndpoints :many
+SELECT * FROM webhook_endpoints
+WHERE account_id = $1
+ORDER BY id;
+
+-- name: DeleteWebhookEndpoint :one
+DELETE FROM webhook_endpoints
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateWebhookDeliveries :execrows
+-- queues the event for every endpoint of the account which subscribed to it
+INSERT INTO webhook_deliveries (endpoint_id, event_type, payload)
+SELECT id, sqlc.arg(event_type)::varchar, sqlc.arg(payload)::jsonb
+FROM webhook_endpoints
+WHERE account_id = sqlc.arg(account_id)
+  AND (cardinality(event_types) = 0 OR sqlc.arg(event_type)::varchar = ANY(event_types));
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE endpoint_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimDueWebhookDeliveries :many
+-- claims the due deliveries for the lease, concurrent dispatchers skip them until it ends
+-- and a dispatcher which crashes before recording the attempts leaves them due again
+UPDATE webhook_deliveries d
+  set next_attempt_at = now() + sqlc.arg(lease_seconds)::int * interval '1 second'
+FROM webhook_endpoints e
+WHERE e.id = d.endpoint_id AND d.id IN (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending' AND next_attempt_at <= now()
+  ORDER BY id
+  LIMIT sqlc.arg(row_limit)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING
+  d.id, d.endpoint_id, d.event_type, d.payload, d.attempts, d.created_at,
+  e.url, e.secret;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+  set status = 'succeeded',
+  attempts = attempts + 1,
+  delivered_at = now()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+-- the retries back off exponentially up to an hour apart,
+-- the delivery fails for good after max_attempts
+UPDATE webhook_deliveries
+  set attempts = attempts + 1,
+  status = CASE WHEN attempts + 1 >= sqlc.arg(max_attempts)::int THEN 'failed' ELSE 'pending' END,
+  next_attempt_at = now() + least(power(2, attempts) * 10, 3600) * interval '1 second'
+WHERE id = sqlc.arg(id);
+
+-- name: RedeliverWebhook :one
+-- queues the delivery again whatever its status, with all of its retries
+UPDATE webhook_deliveries
+  set status = 'pending',
+  attempts = 0,
+  next_attempt_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateWebhookAttempt :one
+INSERT INTO webhook_attempts (
+  delivery_id,
+  status_code,
+  error,
+  duration_ms
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListWebhookAttempts :many
+SELECT * FROM webhook_attempts
+WHERE delivery_id = $1
+ORDER BY id;
@@ -7,13 +7,16 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
-  set balance = balance + $2
+  set balance = balance + $2,
+  available_balance = available_balance + $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
 `
 
 type AddAccountBalanceParams struct {
@@ -30,27 +33,107 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const addAccountLedgerBalance = `-- name: AddAccountLedgerBalance :one
+UPDATE accounts
+  set balance = balance + $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
+`
+
+type AddAccountLedgerBalanceParams struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount"`
+}
+
+// only touch the ledger balance, used when capturing a hold
+// whose amount has already been taken from the available balance
+func (q *Queries) AddAccountLedgerBalance(ctx context.Context, arg AddAccountLedgerBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountLedgerBalance, arg.ID, arg.Amount)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
-  owner, balance, currency
+  owner, balance, available_balance, currency, account_type, organization_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $2, $3, $4, $5
 )
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner          string `json:"owner"`
+	Balance        int64  `json:"balance"`
+	Currency       string `json:"currency"`
+	AccountType    string `json:"account_type"`
+	OrganizationID *int64 `json:"organization_id"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.AccountType,
+		arg.OrganizationID,
+	)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const debitAccountBalance = `-- name: DebitAccountBalance :one
+UPDATE accounts
+  set balance = balance - $2,
+  available_balance = available_balance - $2
+WHERE id = $1 AND available_balance + overdraft_limit >= $2
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
+`
+
+type DebitAccountBalanceParams struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount"`
+}
+
+// fails with no rows when the debit goes past the overdraft limit
+func (q *Queries) DebitAccountBalance(ctx context.Context, arg DebitAccountBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, debitAccountBalance, arg.ID, arg.Amount)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -58,6 +141,12 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
 	)
 	return i, err
 }
@@ -84,7 +173,7 @@ func (q *Queries) DeleteAccountByOwnerLike(ctx context.Context, owner string) er
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -97,12 +186,72 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
+WHERE account_number = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByNumber, accountNumber)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const getAccountByOwnerAndCurrency = `-- name: GetAccountByOwnerAndCurrency :one
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
+WHERE owner = $1 AND currency = $2 AND account_type = $3 AND organization_id IS NULL LIMIT 1
+`
+
+type GetAccountByOwnerAndCurrencyParams struct {
+	Owner       string `json:"owner"`
+	Currency    string `json:"currency"`
+	AccountType string `json:"account_type"`
+}
+
+func (q *Queries) GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByOwnerAndCurrency, arg.Owner, arg.Currency, arg.AccountType)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -116,13 +265,63 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
 	)
 	return i, err
 }
 
+const getAccountsByIDs = `-- name: GetAccountsByIDs :many
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
+WHERE id = ANY($1::bigint[])
+ORDER BY id
+`
+
+func (q *Queries) GetAccountsByIDs(ctx context.Context, ids []int64) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AvailableBalance,
+			&i.AccountType,
+			&i.OverdraftLimit,
+			&i.OverdraftRateBps,
+			&i.OrganizationID,
+			&i.AccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
+WHERE organization_id IS NULL AND (owner = $1 OR id IN (
+  SELECT account_id FROM account_members WHERE username = $1
+))
+ORDER BY id
 LIMIT $2
 OFFSET $3
 `
@@ -133,6 +332,7 @@ type ListAccountsParams struct {
 	Offset int32  `json:"offset"`
 }
 
+// personal accounts owned by the user or shared with them
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
 	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
 	if err != nil {
@@ -148,6 +348,12 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.AvailableBalance,
+			&i.AccountType,
+			&i.OverdraftLimit,
+			&i.OverdraftRateBps,
+			&i.OrganizationID,
+			&i.AccountNumber,
 		); err != nil {
 			return nil, err
 		}
@@ -162,11 +368,214 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const listAccountsAfter = `-- name: ListAccountsAfter :many
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
+WHERE organization_id IS NULL AND (owner = $1 OR id IN (
+  SELECT account_id FROM account_members WHERE username = $1
+)) AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListAccountsAfterParams struct {
+	Owner     string `json:"owner"`
+	AfterID   int64  `json:"after_id"`
+	PageLimit int32  `json:"page_limit"`
+}
+
+// ListAccounts paginated by a cursor, the page starts after the account with after_id
+func (q *Queries) ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsAfter, arg.Owner, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AvailableBalance,
+			&i.AccountType,
+			&i.OverdraftLimit,
+			&i.OverdraftRateBps,
+			&i.OrganizationID,
+			&i.AccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationAccounts = `-- name: ListOrganizationAccounts :many
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
+WHERE organization_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListOrganizationAccounts(ctx context.Context, organizationID *int64) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganizationAccounts, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AvailableBalance,
+			&i.AccountType,
+			&i.OverdraftLimit,
+			&i.OverdraftRateBps,
+			&i.OrganizationID,
+			&i.AccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdrawnAccounts = `-- name: ListOverdrawnAccounts :many
+SELECT id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number FROM accounts
+WHERE balance < 0 AND owner NOT LIKE 'system\_%'
+ORDER BY balance, id
+LIMIT $1
+OFFSET $2
+`
+
+type ListOverdrawnAccountsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListOverdrawnAccounts(ctx context.Context, arg ListOverdrawnAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listOverdrawnAccounts, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AvailableBalance,
+			&i.AccountType,
+			&i.OverdraftLimit,
+			&i.OverdraftRateBps,
+			&i.OrganizationID,
+			&i.AccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseAccountBalance = `-- name: ReleaseAccountBalance :one
+UPDATE accounts
+  set available_balance = available_balance + $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
+`
+
+type ReleaseAccountBalanceParams struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount"`
+}
+
+func (q *Queries) ReleaseAccountBalance(ctx context.Context, arg ReleaseAccountBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, releaseAccountBalance, arg.ID, arg.Amount)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const reserveAccountBalance = `-- name: ReserveAccountBalance :one
+UPDATE accounts
+  set available_balance = available_balance - $2
+WHERE id = $1 AND available_balance + overdraft_limit >= $2
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
+`
+
+type ReserveAccountBalanceParams struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount"`
+}
+
+func (q *Queries) ReserveAccountBalance(ctx context.Context, arg ReserveAccountBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, reserveAccountBalance, arg.ID, arg.Amount)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
-  set balance = $2
+  set balance = $2,
+  available_balance = available_balance + $2 - balance
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
 `
 
 type UpdateAccountParams struct {
@@ -183,6 +592,79 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const updateAccountOverdraft = `-- name: UpdateAccountOverdraft :one
+UPDATE accounts
+  set overdraft_limit = $2,
+  overdraft_rate_bps = $3
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
+`
+
+type UpdateAccountOverdraftParams struct {
+	ID               int64 `json:"id"`
+	OverdraftLimit   int64 `json:"overdraft_limit"`
+	OverdraftRateBps int64 `json:"overdraft_rate_bps"`
+}
+
+func (q *Queries) UpdateAccountOverdraft(ctx context.Context, arg UpdateAccountOverdraftParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountOverdraft, arg.ID, arg.OverdraftLimit, arg.OverdraftRateBps)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const withdrawAccountBalance = `-- name: WithdrawAccountBalance :one
+UPDATE accounts
+  set balance = balance - $2,
+  available_balance = available_balance - $2
+WHERE id = $1 AND available_balance >= $2
+RETURNING id, owner, balance, currency, created_at, available_balance, account_type, overdraft_limit, overdraft_rate_bps, organization_id, account_number
+`
+
+type WithdrawAccountBalanceParams struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount"`
+}
+
+// like DebitAccountBalance but never dips into the overdraft,
+// fails with no rows when the available balance is short
+func (q *Queries) WithdrawAccountBalance(ctx context.Context, arg WithdrawAccountBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, withdrawAccountBalance, arg.ID, arg.Amount)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.AccountType,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.OrganizationID,
+		&i.AccountNumber,
 	)
 	return i, err
 }
@@ -0,0 +1,25 @@
+package db
+
+// AccountEventsChannel is where the database notifies about new entries and balance changes,
+// the notifications of a transaction are sent when it commits
+const AccountEventsChannel = "account_events"
+
+const (
+	AccountEventEntry   = "entry"
+	AccountEventBalance = "balance"
+)
+
+// AccountEvent is the payload of a notification on AccountEventsChannel,
+// Entry is set for AccountEventEntry and Balance for AccountEventBalance
+type AccountEvent struct {
+	Type      string          `json:"type"`
+	AccountID int64           `json:"account_id"`
+	Entry     *Entry          `json:"entry,omitempty"`
+	Balance   *AccountBalance `json:"balance,omitempty"`
+}
+
+type AccountBalance struct {
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+	Currency         string `json:"currency"`
+}
@@ -0,0 +1,169 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: account_member.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const addAccountMember = `-- name: AddAccountMember :one
+INSERT INTO account_members (
+  account_id, username, role, invited_by
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id, username) DO UPDATE SET role = EXCLUDED.role
+RETURNING account_id, username, role, invited_by, created_at
+`
+
+type AddAccountMemberParams struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	InvitedBy string `json:"invited_by"`
+}
+
+// adding an existing member changes their role
+func (q *Queries) AddAccountMember(ctx context.Context, arg AddAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, addAccountMember,
+		arg.AccountID,
+		arg.Username,
+		arg.Role,
+		arg.InvitedBy,
+	)
+	var i AccountMember
+	err := row.Scan(
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.InvitedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAccountMember = `-- name: GetAccountMember :one
+SELECT account_id, username, role, invited_by, created_at FROM account_members
+WHERE account_id = $1 AND username = $2 LIMIT 1
+`
+
+type GetAccountMemberParams struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+}
+
+func (q *Queries) GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, getAccountMember, arg.AccountID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.InvitedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountMembers = `-- name: ListAccountMembers :many
+SELECT account_id, username, role, invited_by, created_at FROM account_members
+WHERE account_id = $1
+ORDER BY created_at, username
+`
+
+func (q *Queries) ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountMembers, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountMember{}
+	for rows.Next() {
+		var i AccountMember
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Username,
+			&i.Role,
+			&i.InvitedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountMembershipsOfUser = `-- name: ListAccountMembershipsOfUser :many
+SELECT account_id, username, role, invited_by, created_at FROM account_members
+WHERE username = $1 AND account_id = ANY($2::bigint[])
+`
+
+type ListAccountMembershipsOfUserParams struct {
+	Username   string  `json:"username"`
+	AccountIds []int64 `json:"account_ids"`
+}
+
+func (q *Queries) ListAccountMembershipsOfUser(ctx context.Context, arg ListAccountMembershipsOfUserParams) ([]AccountMember, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountMembershipsOfUser, arg.Username, pq.Array(arg.AccountIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountMember{}
+	for rows.Next() {
+		var i AccountMember
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Username,
+			&i.Role,
+			&i.InvitedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeAccountMember = `-- name: RemoveAccountMember :one
+DELETE FROM account_members
+WHERE account_id = $1 AND username = $2
+RETURNING account_id, username, role, invited_by, created_at
+`
+
+type RemoveAccountMemberParams struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+}
+
+func (q *Queries) RemoveAccountMember(ctx context.Context, arg RemoveAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, removeAccountMember, arg.AccountID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.AccountID,
+		&i.Username,
+		&i.Role,
+		&i.InvitedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
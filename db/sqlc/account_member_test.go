@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func addRandomAccountMember(t *testing.T, account Account, role string) AccountMember {
+	ctx := context.Background()
+	user := createRandomUser(t, accPrefix)
+
+	arg := AddAccountMemberParams{
+		AccountID: account.ID,
+		Username:  user.Username,
+		Role:      role,
+		InvitedBy: account.Owner,
+	}
+
+	member, err := testQueries.AddAccountMember(ctx, arg)
+	assert.NoError(t, err)
+	assert.Equal(t, arg.AccountID, member.AccountID)
+	assert.Equal(t, arg.Username, member.Username)
+	assert.Equal(t, arg.Role, member.Role)
+	assert.Equal(t, arg.InvitedBy, member.InvitedBy)
+	assert.NotZero(t, member.CreatedAt)
+
+	return member
+}
+
+func TestAddAccountMemberChangesRole(t *testing.T) {
+	ctx := context.Background()
+	account := createRandomAccount(t, accPrefix)
+	defer deleteTestingAccount(ctx, accPrefix)
+
+	member := addRandomAccountMember(t, account, util.AccountViewerRole)
+
+	updated, err := testQueries.AddAccountMember(ctx, AddAccountMemberParams{
+		AccountID: account.ID,
+		Username:  member.Username,
+		Role:      util.AccountCoOwnerRole,
+		InvitedBy: account.Owner,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, util.AccountCoOwnerRole, updated.Role)
+
+	found, err := testQueries.GetAccountMember(ctx, GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  member.Username,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, updated, found)
+}
+
+func TestListAndRemoveAccountMembers(t *testing.T) {
+	ctx := context.Background()
+	account := createRandomAccount(t, accPrefix)
+	defer deleteTestingAccount(ctx, accPrefix)
+
+	viewer := addRandomAccountMember(t, account, util.AccountViewerRole)
+	coOwner := addRandomAccountMember(t, account, util.AccountCoOwnerRole)
+
+	members, err := testQueries.ListAccountMembers(ctx, account.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []AccountMember{viewer, coOwner}, members)
+
+	removed, err := testQueries.RemoveAccountMember(ctx, RemoveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  viewer.Username,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, viewer, removed)
+
+	_, err = testQueries.GetAccountMember(ctx, GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  viewer.Username,
+	})
+	assert.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestListAccountsIncludesSharedAccounts(t *testing.T) {
+	ctx := context.Background()
+	shared := createRandomAccount(t, accPrefix)
+	own := createRandomAccount(t, accPrefix)
+	defer deleteTestingAccount(ctx, accPrefix)
+
+	_, err := testQueries.AddAccountMember(ctx, AddAccountMemberParams{
+		AccountID: shared.ID,
+		Username:  own.Owner,
+		Role:      util.AccountViewerRole,
+		InvitedBy: shared.Owner,
+	})
+	assert.NoError(t, err)
+
+	accounts, err := testQueries.ListAccounts(ctx, ListAccountsParams{
+		Owner:  own.Owner,
+		Limit:  5,
+		Offset: 0,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 2)
+
+	ids := []int64{accounts[0].ID, accounts[1].ID}
+	assert.ElementsMatch(t, []int64{shared.ID, own.ID}, ids)
+}
@@ -16,9 +16,10 @@ func createRandomAccount(t *testing.T, prefix string) Account {
 	user := createRandomUser(t, accPrefix)
 
 	arg := CreateAccountParams{
-		Owner:    user.Username,
-		Balance:  util.RandomInt(0, 1000),
-		Currency: util.RandomCurrency(),
+		Owner:       user.Username,
+		Balance:     util.RandomInt(0, 1000),
+		Currency:    util.RandomCurrency(),
+		AccountType: util.CheckingAccount,
 	}
 
 	account, err := testQueries.CreateAccount(ctx, arg)
@@ -27,10 +28,13 @@ func createRandomAccount(t *testing.T, prefix string) Account {
 
 	assert.Equal(t, arg.Owner, account.Owner)
 	assert.Equal(t, arg.Balance, account.Balance)
+	assert.Equal(t, arg.Balance, account.AvailableBalance)
 	assert.Equal(t, arg.Currency, account.Currency)
+	assert.Equal(t, arg.AccountType, account.AccountType)
 
 	assert.NotZero(t, account.ID)
 	assert.NotZero(t, account.CreatedAt)
+	assert.True(t, util.IsValidAccountNumber(account.AccountNumber))
 
 	return account
 }
@@ -60,6 +64,20 @@ func TestGetAccount(t *testing.T) {
 	assert.Equal(t, newAccount.Currency, account.Currency)
 }
 
+func TestGetAccountByNumber(t *testing.T) {
+	ctx := context.Background()
+	newAccount := createRandomAccount(t, accPrefix)
+	defer deleteTestingAccount(ctx, accPrefix)
+
+	account, err := testQueries.GetAccountByNumber(ctx, newAccount.AccountNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, newAccount.ID, account.ID)
+	assert.Equal(t, newAccount.AccountNumber, account.AccountNumber)
+
+	_, err = testQueries.GetAccountByNumber(ctx, util.RandomAccountNumber())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
 func TestUpdateAccount(t *testing.T) {
 	ctx := context.Background()
 	newAccount := createRandomAccount(t, accPrefix)
@@ -117,3 +135,60 @@ func TestListAccounts(t *testing.T) {
 		assert.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestUpdateAccountOverdraft(t *testing.T) {
+	ctx := context.Background()
+	newAccount := createRandomAccount(t, accPrefix)
+	defer deleteTestingAccount(ctx, accPrefix)
+
+	arg := UpdateAccountOverdraftParams{
+		ID:               newAccount.ID,
+		OverdraftLimit:   util.RandomInt(1, 1000),
+		OverdraftRateBps: util.RandomInt(1, 2000),
+	}
+
+	account, err := testQueries.UpdateAccountOverdraft(ctx, arg)
+	assert.NoError(t, err)
+	assert.Equal(t, arg.OverdraftLimit, account.OverdraftLimit)
+	assert.Equal(t, arg.OverdraftRateBps, account.OverdraftRateBps)
+	assert.Equal(t, newAccount.Balance, account.Balance)
+}
+
+func TestListOverdrawnAccounts(t *testing.T) {
+	ctx := context.Background()
+	newAccount := createRandomAccount(t, accPrefix)
+	defer deleteTestingAccount(ctx, accPrefix)
+
+	_, err := testQueries.UpdateAccount(ctx, UpdateAccountParams{
+		ID:      newAccount.ID,
+		Balance: -1,
+	})
+	assert.NoError(t, err)
+
+	accounts, err := testQueries.ListOverdrawnAccounts(ctx, ListOverdrawnAccountsParams{
+		Limit:  1000,
+		Offset: 0,
+	})
+	assert.NoError(t, err)
+
+	var found bool
+	for _, account := range accounts {
+		assert.Negative(t, account.Balance)
+		if account.ID == newAccount.ID {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestGetAccountsByIDs(t *testing.T) {
+	ctx := context.Background()
+	account1 := createRandomAccount(t, accPrefix)
+	account2 := createRandomAccount(t, accPrefix)
+	defer deleteTestingAccount(ctx, accPrefix)
+
+	// ids without an account are left out
+	accounts, err := testQueries.GetAccountsByIDs(ctx, []int64{account2.ID, account1.ID, -1})
+	assert.NoError(t, err)
+	assert.Equal(t, []Account{account1, account2}, accounts)
+}
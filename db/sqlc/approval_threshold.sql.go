@@ -0,0 +1,54 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: approval_threshold.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteApprovalThreshold = `-- name: DeleteApprovalThreshold :exec
+DELETE FROM approval_thresholds
+WHERE currency = $1
+`
+
+func (q *Queries) DeleteApprovalThreshold(ctx context.Context, currency string) error {
+	_, err := q.db.ExecContext(ctx, deleteApprovalThreshold, currency)
+	return err
+}
+
+const getApprovalThreshold = `-- name: GetApprovalThreshold :one
+SELECT currency, min_amount, created_at FROM approval_thresholds
+WHERE currency = $1 LIMIT 1
+`
+
+func (q *Queries) GetApprovalThreshold(ctx context.Context, currency string) (ApprovalThreshold, error) {
+	row := q.db.QueryRowContext(ctx, getApprovalThreshold, currency)
+	var i ApprovalThreshold
+	err := row.Scan(&i.Currency, &i.MinAmount, &i.CreatedAt)
+	return i, err
+}
+
+const setApprovalThreshold = `-- name: SetApprovalThreshold :one
+INSERT INTO approval_thresholds (
+  currency, min_amount
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (currency) DO UPDATE SET min_amount = EXCLUDED.min_amount
+RETURNING currency, min_amount, created_at
+`
+
+type SetApprovalThresholdParams struct {
+	Currency  string `json:"currency"`
+	MinAmount int64  `json:"min_amount"`
+}
+
+func (q *Queries) SetApprovalThreshold(ctx context.Context, arg SetApprovalThresholdParams) (ApprovalThreshold, error) {
+	row := q.db.QueryRowContext(ctx, setApprovalThreshold, arg.Currency, arg.MinAmount)
+	var i ApprovalThreshold
+	err := row.Scan(&i.Currency, &i.MinAmount, &i.CreatedAt)
+	return i, err
+}
@@ -0,0 +1,234 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: audit_event.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const createAuditEvent = `-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  actor,
+  action,
+  entity,
+  entity_id,
+  before,
+  after,
+  client_ip,
+  request_id,
+  prev_hash,
+  hash,
+  created_at,
+  chain
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+RETURNING id, actor, action, entity, entity_id, before, after, client_ip, request_id, prev_hash, hash, created_at, chain
+`
+
+type CreateAuditEventParams struct {
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	ClientIp  string          `json:"client_ip"`
+	RequestID string          `json:"request_id"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+	CreatedAt time.Time       `json:"created_at"`
+	Chain     int32           `json:"chain"`
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	row := q.db.QueryRowContext(ctx, createAuditEvent,
+		arg.Actor,
+		arg.Action,
+		arg.Entity,
+		arg.EntityID,
+		arg.Before,
+		arg.After,
+		arg.ClientIp,
+		arg.RequestID,
+		arg.PrevHash,
+		arg.Hash,
+		arg.CreatedAt,
+		arg.Chain,
+	)
+	var i AuditEvent
+	err := row.Scan(
+		&i.ID,
+		&i.Actor,
+		&i.Action,
+		&i.Entity,
+		&i.EntityID,
+		&i.Before,
+		&i.After,
+		&i.ClientIp,
+		&i.RequestID,
+		&i.PrevHash,
+		&i.Hash,
+		&i.CreatedAt,
+		&i.Chain,
+	)
+	return i, err
+}
+
+const getLastAuditEvent = `-- name: GetLastAuditEvent :one
+SELECT id, actor, action, entity, entity_id, before, after, client_ip, request_id, prev_hash, hash, created_at, chain FROM audit_events
+WHERE chain = $1
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastAuditEvent(ctx context.Context, chain int32) (AuditEvent, error) {
+	row := q.db.QueryRowContext(ctx, getLastAuditEvent, chain)
+	var i AuditEvent
+	err := row.Scan(
+		&i.ID,
+		&i.Actor,
+		&i.Action,
+		&i.Entity,
+		&i.EntityID,
+		&i.Before,
+		&i.After,
+		&i.ClientIp,
+		&i.RequestID,
+		&i.PrevHash,
+		&i.Hash,
+		&i.CreatedAt,
+		&i.Chain,
+	)
+	return i, err
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT id, actor, action, entity, entity_id, before, after, client_ip, request_id, prev_hash, hash, created_at, chain FROM audit_events
+WHERE ($1::varchar IS NULL OR actor = $1)
+  AND ($2::varchar IS NULL OR action = $2)
+  AND ($3::varchar IS NULL OR entity = $3)
+  AND ($4::varchar IS NULL OR entity_id = $4)
+ORDER BY id DESC
+LIMIT $6
+OFFSET $5
+`
+
+type ListAuditEventsParams struct {
+	Actor    sql.NullString `json:"actor"`
+	Action   sql.NullString `json:"action"`
+	Entity   sql.NullString `json:"entity"`
+	EntityID sql.NullString `json:"entity_id"`
+	Offset   int32          `json:"offset"`
+	Limit    int32          `json:"limit"`
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEvents,
+		arg.Actor,
+		arg.Action,
+		arg.Entity,
+		arg.EntityID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditEvent{}
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.Entity,
+			&i.EntityID,
+			&i.Before,
+			&i.After,
+			&i.ClientIp,
+			&i.RequestID,
+			&i.PrevHash,
+			&i.Hash,
+			&i.CreatedAt,
+			&i.Chain,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEventsAfter = `-- name: ListAuditEventsAfter :many
+SELECT id, actor, action, entity, entity_id, before, after, client_ip, request_id, prev_hash, hash, created_at, chain FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListAuditEventsAfterParams struct {
+	ID    int64 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+func (q *Queries) ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEventsAfter, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditEvent{}
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.Entity,
+			&i.EntityID,
+			&i.Before,
+			&i.After,
+			&i.ClientIp,
+			&i.RequestID,
+			&i.PrevHash,
+			&i.Hash,
+			&i.CreatedAt,
+			&i.Chain,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAuditChain = `-- name: LockAuditChain :exec
+SELECT pg_advisory_xact_lock(hashtext('audit_events'), $1::int)
+`
+
+// held until the end of the transaction, only one transaction at a time
+// may append to a chain
+func (q *Queries) LockAuditChain(ctx context.Context, chain int32) error {
+	_, err := q.db.ExecContext(ctx, lockAuditChain, chain)
+	return err
+}
@@ -7,30 +7,78 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id, description
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4
 )
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, transfer_id, description, pocket_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID   int64  `json:"account_id"`
+	Amount      int64  `json:"amount"`
+	TransferID  *int64 `json:"transfer_id"`
+	Description string `json:"description"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.Description,
+	)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.Description,
+		&i.PocketID,
+	)
+	return i, err
+}
+
+const createPocketEntry = `-- name: CreatePocketEntry :one
+INSERT INTO entries (
+  account_id, amount, pocket_id, description
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, account_id, amount, created_at, transfer_id, description, pocket_id
+`
+
+type CreatePocketEntryParams struct {
+	AccountID   int64  `json:"account_id"`
+	Amount      int64  `json:"amount"`
+	PocketID    *int64 `json:"pocket_id"`
+	Description string `json:"description"`
+}
+
+func (q *Queries) CreatePocketEntry(ctx context.Context, arg CreatePocketEntryParams) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, createPocketEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.PocketID,
+		arg.Description,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Description,
+		&i.PocketID,
 	)
 	return i, err
 }
@@ -47,7 +95,7 @@ func (q *Queries) DeleteEntryByAccountID(ctx context.Context, accountID int64) e
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, description, pocket_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -59,12 +107,15 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Description,
+		&i.PocketID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, description, pocket_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -91,6 +142,96 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
+			&i.PocketID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesAfterOfAccounts = `-- name: ListEntriesAfterOfAccounts :many
+SELECT entries.id, entries.account_id, entries.amount, entries.created_at, entries.transfer_id, entries.description, entries.pocket_id FROM unnest($1::bigint[]) AS a(account_id)
+JOIN LATERAL (
+  SELECT id FROM entries e
+  WHERE e.account_id = a.account_id AND e.id > $2
+  ORDER BY e.id
+  LIMIT $3
+) page ON true
+JOIN entries ON entries.id = page.id
+ORDER BY entries.account_id, entries.id
+`
+
+type ListEntriesAfterOfAccountsParams struct {
+	AccountIds []int64 `json:"account_ids"`
+	AfterID    int64   `json:"after_id"`
+	PageLimit  int32   `json:"page_limit"`
+}
+
+// a page of entries for every account, the graphql loader batches the connections with it
+func (q *Queries) ListEntriesAfterOfAccounts(ctx context.Context, arg ListEntriesAfterOfAccountsParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesAfterOfAccounts, pq.Array(arg.AccountIds), arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
+			&i.PocketID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesByTransfer = `-- name: ListEntriesByTransfer :many
+SELECT id, account_id, amount, created_at, transfer_id, description, pocket_id FROM entries
+WHERE transfer_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListEntriesByTransfer(ctx context.Context, transferID *int64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesByTransfer, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
+			&i.PocketID,
 		); err != nil {
 			return nil, err
 		}
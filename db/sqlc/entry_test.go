@@ -83,3 +83,28 @@ func TestListEntries(t *testing.T) {
 		assert.NotEmpty(t, entry)
 	}
 }
+
+func TestListEntriesAfterOfAccounts(t *testing.T) {
+	ctx := context.Background()
+	account1 := createRandomAccount(t, accEntryPrefix)
+	account2 := createRandomAccount(t, accEntryPrefix)
+
+	var entries1, entries2 []Entry
+	for i := 0; i < 10; i++ {
+		entries1 = append(entries1, createRandomEntry(t, account1))
+		entries2 = append(entries2, createRandomEntry(t, account2))
+	}
+
+	defer deleteTestingAccount(ctx, accEntryPrefix)
+	defer deleteTestingEntry(ctx, account1.ID)
+	defer deleteTestingEntry(ctx, account2.ID)
+
+	// every account gets its own page after the id, the entries of the accounts alternate
+	page, err := testQueries.ListEntriesAfterOfAccounts(ctx, ListEntriesAfterOfAccountsParams{
+		AccountIds: []int64{account2.ID, account1.ID},
+		AfterID:    entries1[4].ID,
+		PageLimit:  3,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, append(entries1[5:8:8], entries2[4:7]...), page)
+}
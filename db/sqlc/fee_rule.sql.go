@@ -0,0 +1,134 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: fee_rule.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createFeeRule = `-- name: CreateFeeRule :one
+INSERT INTO fee_rules (
+  currency, account_type, min_amount, max_amount, flat_fee, basis_points
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING id, currency, account_type, min_amount, max_amount, flat_fee, basis_points, created_at
+`
+
+type CreateFeeRuleParams struct {
+	Currency    string         `json:"currency"`
+	AccountType sql.NullString `json:"account_type"`
+	MinAmount   int64          `json:"min_amount"`
+	MaxAmount   sql.NullInt64  `json:"max_amount"`
+	FlatFee     int64          `json:"flat_fee"`
+	BasisPoints int64          `json:"basis_points"`
+}
+
+func (q *Queries) CreateFeeRule(ctx context.Context, arg CreateFeeRuleParams) (FeeRule, error) {
+	row := q.db.QueryRowContext(ctx, createFeeRule,
+		arg.Currency,
+		arg.AccountType,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FlatFee,
+		arg.BasisPoints,
+	)
+	var i FeeRule
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.AccountType,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.FlatFee,
+		&i.BasisPoints,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteFeeRule = `-- name: DeleteFeeRule :exec
+DELETE FROM fee_rules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeeRule(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFeeRule, id)
+	return err
+}
+
+const getMatchingFeeRule = `-- name: GetMatchingFeeRule :one
+SELECT id, currency, account_type, min_amount, max_amount, flat_fee, basis_points, created_at FROM fee_rules
+WHERE currency = $1
+  AND (account_type IS NULL OR account_type = $2::varchar)
+  AND min_amount <= $3::bigint
+  AND (max_amount IS NULL OR $3::bigint < max_amount)
+ORDER BY account_type NULLS LAST, id DESC
+LIMIT 1
+`
+
+type GetMatchingFeeRuleParams struct {
+	Currency    string `json:"currency"`
+	AccountType string `json:"account_type"`
+	Amount      int64  `json:"amount"`
+}
+
+// rules for a specific account type win over rules for every account type,
+// then the newest rule wins
+func (q *Queries) GetMatchingFeeRule(ctx context.Context, arg GetMatchingFeeRuleParams) (FeeRule, error) {
+	row := q.db.QueryRowContext(ctx, getMatchingFeeRule, arg.Currency, arg.AccountType, arg.Amount)
+	var i FeeRule
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.AccountType,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.FlatFee,
+		&i.BasisPoints,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listFeeRules = `-- name: ListFeeRules :many
+SELECT id, currency, account_type, min_amount, max_amount, flat_fee, basis_points, created_at FROM fee_rules
+WHERE currency = $1
+ORDER BY id
+`
+
+func (q *Queries) ListFeeRules(ctx context.Context, currency string) ([]FeeRule, error) {
+	rows, err := q.db.QueryContext(ctx, listFeeRules, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeeRule{}
+	for rows.Next() {
+		var i FeeRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.AccountType,
+			&i.MinAmount,
+			&i.MaxAmount,
+			&i.FlatFee,
+			&i.BasisPoints,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
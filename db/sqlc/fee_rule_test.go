@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fee rules use a currency that no other test touches
+const feeTestCurrency = "XTS"
+
+func createTestingFeeRule(t *testing.T, arg CreateFeeRuleParams) FeeRule {
+	arg.Currency = feeTestCurrency
+	rule, err := testQueries.CreateFeeRule(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.NotZero(t, rule.ID)
+	assert.Equal(t, arg.FlatFee, rule.FlatFee)
+	assert.Equal(t, arg.BasisPoints, rule.BasisPoints)
+	return rule
+}
+
+func deleteTestingFeeRules(ctx context.Context) {
+	rules, _ := testQueries.ListFeeRules(ctx, feeTestCurrency)
+	for _, rule := range rules {
+		testQueries.DeleteFeeRule(ctx, rule.ID)
+	}
+}
+
+func TestGetMatchingFeeRule(t *testing.T) {
+	ctx := context.Background()
+	defer deleteTestingFeeRules(ctx)
+
+	lowTier := createTestingFeeRule(t, CreateFeeRuleParams{
+		MinAmount: 0,
+		MaxAmount: sql.NullInt64{Int64: 1000, Valid: true},
+		FlatFee:   10,
+	})
+	highTier := createTestingFeeRule(t, CreateFeeRuleParams{
+		MinAmount:   1000,
+		BasisPoints: 50,
+	})
+	savings := createTestingFeeRule(t, CreateFeeRuleParams{
+		AccountType: sql.NullString{String: "savings", Valid: true},
+		MinAmount:   0,
+		FlatFee:     99,
+	})
+
+	testCases := []struct {
+		name        string
+		accountType string
+		amount      int64
+		ruleID      int64
+		fee         int64
+	}{
+		{name: "LowTier", amount: 999, ruleID: lowTier.ID, fee: 10},
+		{name: "HighTier", amount: 1000, ruleID: highTier.ID, fee: 5},
+		{name: "AccountTypeWins", accountType: "savings", amount: 5000, ruleID: savings.ID, fee: 99},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := testQueries.GetMatchingFeeRule(ctx, GetMatchingFeeRuleParams{
+				Currency:    feeTestCurrency,
+				AccountType: tc.accountType,
+				Amount:      tc.amount,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.ruleID, rule.ID)
+			assert.Equal(t, tc.fee, rule.Calculate(tc.amount))
+		})
+	}
+
+	_, err := testQueries.GetMatchingFeeRule(ctx, GetMatchingFeeRuleParams{
+		Currency: "XXX",
+		Amount:   100,
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
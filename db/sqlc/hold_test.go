@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const holdPrefix = "hold_test_"
+
+func createRandomHold(t *testing.T, account Account) Hold {
+	ctx := context.Background()
+	arg := CreateHoldParams{
+		AccountID: account.ID,
+		Amount:    util.RandomInt(1, 20),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	hold, err := testQueries.CreateHold(ctx, arg)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, hold)
+
+	assert.Equal(t, arg.AccountID, hold.AccountID)
+	assert.Equal(t, arg.Amount, hold.Amount)
+	assert.Equal(t, HoldStatusPending, hold.Status)
+	assert.False(t, hold.TransferID.Valid)
+	assert.WithinDuration(t, arg.ExpiresAt, hold.ExpiresAt, time.Second)
+
+	assert.NotZero(t, hold.ID)
+	assert.NotZero(t, hold.CreatedAt)
+	return hold
+}
+
+func deleteTestingHold(ctx context.Context, accountID int64) {
+	testQueries.DeleteHoldByAccountID(ctx, accountID)
+}
+
+func TestCreateHold(t *testing.T) {
+	ctx := context.Background()
+	account := createRandomAccount(t, holdPrefix)
+	createRandomHold(t, account)
+
+	defer deleteTestingAccount(ctx, holdPrefix)
+	defer deleteTestingHold(ctx, account.ID)
+}
+
+func TestGetHold(t *testing.T) {
+	ctx := context.Background()
+	account := createRandomAccount(t, holdPrefix)
+	newHold := createRandomHold(t, account)
+
+	defer deleteTestingAccount(ctx, holdPrefix)
+	defer deleteTestingHold(ctx, account.ID)
+
+	hold, err := testQueries.GetHold(ctx, newHold.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, newHold.ID, hold.ID)
+	assert.Equal(t, newHold.AccountID, hold.AccountID)
+	assert.Equal(t, newHold.Amount, hold.Amount)
+	assert.Equal(t, newHold.Status, hold.Status)
+}
+
+func TestUpdateHoldStatus(t *testing.T) {
+	ctx := context.Background()
+	account := createRandomAccount(t, holdPrefix)
+	newHold := createRandomHold(t, account)
+
+	defer deleteTestingAccount(ctx, holdPrefix)
+	defer deleteTestingHold(ctx, account.ID)
+
+	hold, err := testQueries.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		ID:     newHold.ID,
+		Status: HoldStatusReleased,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, HoldStatusReleased, hold.Status)
+	assert.False(t, hold.TransferID.Valid)
+}
+
+func TestListHolds(t *testing.T) {
+	ctx := context.Background()
+	account := createRandomAccount(t, holdPrefix)
+
+	for i := 0; i < 10; i++ {
+		createRandomHold(t, account)
+	}
+
+	defer deleteTestingAccount(ctx, holdPrefix)
+	defer deleteTestingHold(ctx, account.ID)
+
+	holds, err := testQueries.ListHolds(ctx, ListHoldsParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    5,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, holds, 5)
+
+	for _, hold := range holds {
+		assert.Equal(t, account.ID, hold.AccountID)
+	}
+}
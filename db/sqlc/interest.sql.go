@@ -0,0 +1,423 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: interest.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createInterestAccrual = `-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+  account_id, kind, accrual_date, balance, annual_rate_bps, amount, remainder
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (account_id, accrual_date, kind) DO NOTHING
+RETURNING id, account_id, accrual_date, balance, annual_rate_bps, amount, remainder, entry_id, posted_at, created_at, kind
+`
+
+type CreateInterestAccrualParams struct {
+	AccountID     int64     `json:"account_id"`
+	Kind          string    `json:"kind"`
+	AccrualDate   time.Time `json:"accrual_date"`
+	Balance       int64     `json:"balance"`
+	AnnualRateBps int64     `json:"annual_rate_bps"`
+	Amount        int64     `json:"amount"`
+	Remainder     int64     `json:"remainder"`
+}
+
+func (q *Queries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error) {
+	row := q.db.QueryRowContext(ctx, createInterestAccrual,
+		arg.AccountID,
+		arg.Kind,
+		arg.AccrualDate,
+		arg.Balance,
+		arg.AnnualRateBps,
+		arg.Amount,
+		arg.Remainder,
+	)
+	var i InterestAccrual
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.AccrualDate,
+		&i.Balance,
+		&i.AnnualRateBps,
+		&i.Amount,
+		&i.Remainder,
+		&i.EntryID,
+		&i.PostedAt,
+		&i.CreatedAt,
+		&i.Kind,
+	)
+	return i, err
+}
+
+const createInterestRate = `-- name: CreateInterestRate :one
+INSERT INTO interest_rates (
+  account_type, currency, annual_rate_bps, effective_from
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, account_type, currency, annual_rate_bps, effective_from, created_at
+`
+
+type CreateInterestRateParams struct {
+	AccountType   string    `json:"account_type"`
+	Currency      string    `json:"currency"`
+	AnnualRateBps int64     `json:"annual_rate_bps"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+func (q *Queries) CreateInterestRate(ctx context.Context, arg CreateInterestRateParams) (InterestRate, error) {
+	row := q.db.QueryRowContext(ctx, createInterestRate,
+		arg.AccountType,
+		arg.Currency,
+		arg.AnnualRateBps,
+		arg.EffectiveFrom,
+	)
+	var i InterestRate
+	err := row.Scan(
+		&i.ID,
+		&i.AccountType,
+		&i.Currency,
+		&i.AnnualRateBps,
+		&i.EffectiveFrom,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteInterestAccrualsByAccountID = `-- name: DeleteInterestAccrualsByAccountID :exec
+DELETE FROM interest_accruals
+WHERE account_id = $1
+`
+
+// for testing purpose
+func (q *Queries) DeleteInterestAccrualsByAccountID(ctx context.Context, accountID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteInterestAccrualsByAccountID, accountID)
+	return err
+}
+
+const deleteInterestRate = `-- name: DeleteInterestRate :exec
+DELETE FROM interest_rates
+WHERE id = $1
+`
+
+func (q *Queries) DeleteInterestRate(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteInterestRate, id)
+	return err
+}
+
+const getLastInterestAccrual = `-- name: GetLastInterestAccrual :one
+SELECT id, account_id, accrual_date, balance, annual_rate_bps, amount, remainder, entry_id, posted_at, created_at, kind FROM interest_accruals
+WHERE account_id = $1 AND kind = $2 AND accrual_date < $3
+ORDER BY accrual_date DESC
+LIMIT 1
+`
+
+type GetLastInterestAccrualParams struct {
+	AccountID   int64     `json:"account_id"`
+	Kind        string    `json:"kind"`
+	AccrualDate time.Time `json:"accrual_date"`
+}
+
+func (q *Queries) GetLastInterestAccrual(ctx context.Context, arg GetLastInterestAccrualParams) (InterestAccrual, error) {
+	row := q.db.QueryRowContext(ctx, getLastInterestAccrual, arg.AccountID, arg.Kind, arg.AccrualDate)
+	var i InterestAccrual
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.AccrualDate,
+		&i.Balance,
+		&i.AnnualRateBps,
+		&i.Amount,
+		&i.Remainder,
+		&i.EntryID,
+		&i.PostedAt,
+		&i.CreatedAt,
+		&i.Kind,
+	)
+	return i, err
+}
+
+const listAccountsWithUnpostedInterest = `-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posted_at IS NULL AND accrual_date < $1
+ORDER BY account_id
+`
+
+func (q *Queries) ListAccountsWithUnpostedInterest(ctx context.Context, accrualDate time.Time) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsWithUnpostedInterest, accrualDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var account_id int64
+		if err := rows.Scan(&account_id); err != nil {
+			return nil, err
+		}
+		items = append(items, account_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestAccruals = `-- name: ListInterestAccruals :many
+SELECT id, account_id, accrual_date, balance, annual_rate_bps, amount, remainder, entry_id, posted_at, created_at, kind FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date, kind
+LIMIT $2
+OFFSET $3
+`
+
+type ListInterestAccrualsParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestAccruals, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestAccrual{}
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.AccrualDate,
+			&i.Balance,
+			&i.AnnualRateBps,
+			&i.Amount,
+			&i.Remainder,
+			&i.EntryID,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.Kind,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestBearingAccounts = `-- name: ListInterestBearingAccounts :many
+SELECT
+  a.id,
+  a.currency,
+  (a.balance - COALESCE((
+    SELECT SUM(e.amount) FROM entries e
+    WHERE e.account_id = a.id AND e.created_at >= $1::timestamptz
+  ), 0))::bigint AS balance,
+  r.annual_rate_bps
+FROM accounts a
+JOIN LATERAL (
+  SELECT ir.annual_rate_bps FROM interest_rates ir
+  WHERE ir.account_type = a.account_type
+    AND ir.currency = a.currency
+    AND ir.effective_from <= $2::date
+  ORDER BY ir.effective_from DESC
+  LIMIT 1
+) r ON true
+WHERE a.account_type = 'savings'
+  AND a.created_at < $1::timestamptz
+  AND NOT EXISTS (
+    SELECT 1 FROM interest_accruals ia
+    WHERE ia.account_id = a.id AND ia.accrual_date = $2::date AND ia.kind = 'interest'
+  )
+ORDER BY a.id
+`
+
+type ListInterestBearingAccountsParams struct {
+	DayEnd      time.Time `json:"day_end"`
+	AccrualDate time.Time `json:"accrual_date"`
+}
+
+type ListInterestBearingAccountsRow struct {
+	ID            int64  `json:"id"`
+	Currency      string `json:"currency"`
+	Balance       int64  `json:"balance"`
+	AnnualRateBps int64  `json:"annual_rate_bps"`
+}
+
+// savings accounts not accrued yet on accrual_date, with the rate effective on that day
+// and the balance at the end of that day rebuilt from the entries made after it
+func (q *Queries) ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestBearingAccounts, arg.DayEnd, arg.AccrualDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListInterestBearingAccountsRow{}
+	for rows.Next() {
+		var i ListInterestBearingAccountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.Balance,
+			&i.AnnualRateBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdraftChargeableAccounts = `-- name: ListOverdraftChargeableAccounts :many
+SELECT id, currency, balance, overdraft_rate_bps FROM (
+  SELECT
+    a.id,
+    a.currency,
+    (a.balance - COALESCE((
+      SELECT SUM(e.amount) FROM entries e
+      WHERE e.account_id = a.id AND e.created_at >= $1::timestamptz
+    ), 0))::bigint AS balance,
+    a.overdraft_rate_bps
+  FROM accounts a
+  WHERE a.overdraft_rate_bps > 0
+    AND a.created_at < $1::timestamptz
+    AND NOT EXISTS (
+      SELECT 1 FROM interest_accruals ia
+      WHERE ia.account_id = a.id AND ia.accrual_date = $2::date AND ia.kind = 'overdraft'
+    )
+) day_balances
+WHERE balance < 0
+ORDER BY id
+`
+
+type ListOverdraftChargeableAccountsParams struct {
+	DayEnd      time.Time `json:"day_end"`
+	AccrualDate time.Time `json:"accrual_date"`
+}
+
+type ListOverdraftChargeableAccountsRow struct {
+	ID               int64  `json:"id"`
+	Currency         string `json:"currency"`
+	Balance          int64  `json:"balance"`
+	OverdraftRateBps int64  `json:"overdraft_rate_bps"`
+}
+
+// accounts with an overdraft rate not charged yet on accrual_date,
+// with the balance at the end of that day rebuilt from the entries made after it
+func (q *Queries) ListOverdraftChargeableAccounts(ctx context.Context, arg ListOverdraftChargeableAccountsParams) ([]ListOverdraftChargeableAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOverdraftChargeableAccounts, arg.DayEnd, arg.AccrualDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOverdraftChargeableAccountsRow{}
+	for rows.Next() {
+		var i ListOverdraftChargeableAccountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.Balance,
+			&i.OverdraftRateBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnpostedInterestAccrualsForUpdate = `-- name: ListUnpostedInterestAccrualsForUpdate :many
+SELECT id, account_id, accrual_date, balance, annual_rate_bps, amount, remainder, entry_id, posted_at, created_at, kind FROM interest_accruals
+WHERE account_id = $1 AND posted_at IS NULL AND accrual_date < $2
+ORDER BY accrual_date, kind
+FOR NO KEY UPDATE
+`
+
+type ListUnpostedInterestAccrualsForUpdateParams struct {
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+}
+
+func (q *Queries) ListUnpostedInterestAccrualsForUpdate(ctx context.Context, arg ListUnpostedInterestAccrualsForUpdateParams) ([]InterestAccrual, error) {
+	rows, err := q.db.QueryContext(ctx, listUnpostedInterestAccrualsForUpdate, arg.AccountID, arg.AccrualDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestAccrual{}
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.AccrualDate,
+			&i.Balance,
+			&i.AnnualRateBps,
+			&i.Amount,
+			&i.Remainder,
+			&i.EntryID,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.Kind,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markInterestAccrualsPosted = `-- name: MarkInterestAccrualsPosted :exec
+UPDATE interest_accruals
+  set entry_id = $2,
+  posted_at = now()
+WHERE account_id = $1 AND posted_at IS NULL AND accrual_date < $3
+`
+
+type MarkInterestAccrualsPostedParams struct {
+	AccountID   int64         `json:"account_id"`
+	EntryID     sql.NullInt64 `json:"entry_id"`
+	AccrualDate time.Time     `json:"accrual_date"`
+}
+
+func (q *Queries) MarkInterestAccrualsPosted(ctx context.Context, arg MarkInterestAccrualsPostedParams) error {
+	_, err := q.db.ExecContext(ctx, markInterestAccrualsPosted, arg.AccountID, arg.EntryID, arg.AccrualDate)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 var (
 	testQueries *Queries
 	testDB      *sql.DB
+	testLogger  = slog.Default()
 )
 
 func TestMain(m *testing.M) {
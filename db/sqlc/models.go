@@ -5,32 +5,203 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
+	ID               int64     `json:"id"`
+	Owner            string    `json:"owner"`
+	Balance          int64     `json:"balance"`
+	Currency         string    `json:"currency"`
+	CreatedAt        time.Time `json:"created_at"`
+	AvailableBalance int64     `json:"available_balance"`
+	AccountType      string    `json:"account_type"`
+	OverdraftLimit   int64     `json:"overdraft_limit"`
+	OverdraftRateBps int64     `json:"overdraft_rate_bps"`
+	OrganizationID   *int64    `json:"organization_id"`
+	AccountNumber    string    `json:"account_number"`
+}
+
+type AccountMember struct {
+	AccountID int64     `json:"account_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	InvitedBy string    `json:"invited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ApprovalThreshold struct {
 	Currency  string    `json:"currency"`
+	MinAmount int64     `json:"min_amount"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type AuditEvent struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	ClientIp  string          `json:"client_ip"`
+	RequestID string          `json:"request_id"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+	CreatedAt time.Time       `json:"created_at"`
+	Chain     int32           `json:"chain"`
+}
+
 type Entry struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	Amount      int64     `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+	TransferID  *int64    `json:"transfer_id"`
+	Description string    `json:"description"`
+	PocketID    *int64    `json:"pocket_id"`
+}
+
+type FeeRule struct {
+	ID          int64          `json:"id"`
+	Currency    string         `json:"currency"`
+	AccountType sql.NullString `json:"account_type"`
+	MinAmount   int64          `json:"min_amount"`
+	MaxAmount   sql.NullInt64  `json:"max_amount"`
+	FlatFee     int64          `json:"flat_fee"`
+	BasisPoints int64          `json:"basis_points"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type Hold struct {
+	ID         int64         `json:"id"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+type InterestAccrual struct {
+	ID            int64         `json:"id"`
+	AccountID     int64         `json:"account_id"`
+	AccrualDate   time.Time     `json:"accrual_date"`
+	Balance       int64         `json:"balance"`
+	AnnualRateBps int64         `json:"annual_rate_bps"`
+	Amount        int64         `json:"amount"`
+	Remainder     int64         `json:"remainder"`
+	EntryID       sql.NullInt64 `json:"entry_id"`
+	PostedAt      sql.NullTime  `json:"posted_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Kind          string        `json:"kind"`
+}
+
+type InterestRate struct {
+	ID            int64     `json:"id"`
+	AccountType   string    `json:"account_type"`
+	Currency      string    `json:"currency"`
+	AnnualRateBps int64     `json:"annual_rate_bps"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type Organization struct {
 	ID        int64     `json:"id"`
-	AccountID int64     `json:"account_id"`
-	Amount    int64     `json:"amount"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type OrganizationMember struct {
+	OrganizationID int64     `json:"organization_id"`
+	Username       string    `json:"username"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	EventType     string          `json:"event_type"`
+	SchemaVersion int32           `json:"schema_version"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int32           `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	PublishedAt   *time.Time      `json:"published_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type Pocket struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	Name       string    `json:"name"`
+	Balance    int64     `json:"balance"`
+	GoalAmount *int64    `json:"goal_amount"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SpendingPolicy struct {
+	ID                int64     `json:"id"`
+	OrganizationID    int64     `json:"organization_id"`
+	Currency          string    `json:"currency"`
+	MinAmount         int64     `json:"min_amount"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
 type Transfer struct {
+	ID                int64           `json:"id"`
+	FromAccountID     int64           `json:"from_account_id"`
+	ToAccountID       int64           `json:"to_account_id"`
+	Amount            int64           `json:"amount"`
+	CreatedAt         time.Time       `json:"created_at"`
+	Fee               int64           `json:"fee"`
+	Description       string          `json:"description"`
+	Reference         string          `json:"reference"`
+	Metadata          json.RawMessage `json:"metadata"`
+	Status            string          `json:"status"`
+	ExpiresAt         *time.Time      `json:"expires_at"`
+	DecidedBy         *string         `json:"decided_by"`
+	DecidedAt         *time.Time      `json:"decided_at"`
+	InitiatedBy       *string         `json:"initiated_by"`
+	RequiredApprovals int32           `json:"required_approvals"`
+}
+
+type TransferApproval struct {
+	TransferID int64     `json:"transfer_id"`
+	Username   string    `json:"username"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type TransferBatch struct {
 	ID            int64     `json:"id"`
 	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	Amount        int64     `json:"amount"`
+	CreatedBy     string    `json:"created_by"`
+	Mode          string    `json:"mode"`
+	Status        string    `json:"status"`
+	TotalRows     int32     `json:"total_rows"`
+	SucceededRows int32     `json:"succeeded_rows"`
 	CreatedAt     time.Time `json:"created_at"`
 }
 
+type TransferBatchRow struct {
+	ID          int64  `json:"id"`
+	BatchID     int64  `json:"batch_id"`
+	RowNumber   int32  `json:"row_number"`
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Description string `json:"description"`
+	Reference   string `json:"reference"`
+	Status      string `json:"status"`
+	TransferID  *int64 `json:"transfer_id"`
+	Error       string `json:"error"`
+}
+
 type User struct {
 	Username          string    `json:"username"`
 	HashedPassword    string    `json:"hashed_password"`
@@ -38,4 +209,36 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+}
+
+type WebhookAttempt struct {
+	ID         int64     `json:"id"`
+	DeliveryID int64     `json:"delivery_id"`
+	StatusCode int32     `json:"status_code"`
+	Error      string    `json:"error"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID            int64           `json:"id"`
+	EndpointID    int64           `json:"endpoint_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int32           `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type WebhookEndpoint struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	Url        string    `json:"url"`
+	Secret     string    `json:"secret"`
+	EventTypes []string  `json:"event_types"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
 }
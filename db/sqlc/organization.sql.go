@@ -0,0 +1,331 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: organization.sql
+
+package db
+
+import (
+	"context"
+)
+
+const addOrganizationMember = `-- name: AddOrganizationMember :one
+INSERT INTO organization_members (
+  organization_id, username, role
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (organization_id, username) DO UPDATE SET role = EXCLUDED.role
+RETURNING organization_id, username, role, created_at
+`
+
+type AddOrganizationMemberParams struct {
+	OrganizationID int64  `json:"organization_id"`
+	Username       string `json:"username"`
+	Role           string `json:"role"`
+}
+
+// adding an existing member changes their role
+func (q *Queries) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, addOrganizationMember, arg.OrganizationID, arg.Username, arg.Role)
+	var i OrganizationMember
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.Username,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOrganization = `-- name: CreateOrganization :one
+INSERT INTO organizations (
+  name, created_by
+) VALUES (
+  $1, $2
+)
+RETURNING id, name, created_by, created_at
+`
+
+type CreateOrganizationParams struct {
+	Name      string `json:"name"`
+	CreatedBy string `json:"created_by"`
+}
+
+func (q *Queries) CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, createOrganization, arg.Name, arg.CreatedBy)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteOrganization = `-- name: DeleteOrganization :exec
+DELETE FROM organizations
+WHERE id = $1
+`
+
+func (q *Queries) DeleteOrganization(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganization, id)
+	return err
+}
+
+const deleteSpendingPolicy = `-- name: DeleteSpendingPolicy :one
+DELETE FROM spending_policies
+WHERE id = $1 AND organization_id = $2
+RETURNING id, organization_id, currency, min_amount, required_approvals, created_at
+`
+
+type DeleteSpendingPolicyParams struct {
+	ID             int64 `json:"id"`
+	OrganizationID int64 `json:"organization_id"`
+}
+
+func (q *Queries) DeleteSpendingPolicy(ctx context.Context, arg DeleteSpendingPolicyParams) (SpendingPolicy, error) {
+	row := q.db.QueryRowContext(ctx, deleteSpendingPolicy, arg.ID, arg.OrganizationID)
+	var i SpendingPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Currency,
+		&i.MinAmount,
+		&i.RequiredApprovals,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrganization = `-- name: GetOrganization :one
+SELECT id, name, created_by, created_at FROM organizations
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOrganization(ctx context.Context, id int64) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganization, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationMember = `-- name: GetOrganizationMember :one
+SELECT organization_id, username, role, created_at FROM organization_members
+WHERE organization_id = $1 AND username = $2 LIMIT 1
+`
+
+type GetOrganizationMemberParams struct {
+	OrganizationID int64  `json:"organization_id"`
+	Username       string `json:"username"`
+}
+
+func (q *Queries) GetOrganizationMember(ctx context.Context, arg GetOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationMember, arg.OrganizationID, arg.Username)
+	var i OrganizationMember
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.Username,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSpendingPolicy = `-- name: GetSpendingPolicy :one
+SELECT id, organization_id, currency, min_amount, required_approvals, created_at FROM spending_policies
+WHERE organization_id = $1 AND currency = $2 AND min_amount <= $3
+ORDER BY min_amount DESC
+LIMIT 1
+`
+
+type GetSpendingPolicyParams struct {
+	OrganizationID int64  `json:"organization_id"`
+	Currency       string `json:"currency"`
+	Amount         int64  `json:"amount"`
+}
+
+// the policy that applies to a transfer of the amount, the one with the highest min_amount
+func (q *Queries) GetSpendingPolicy(ctx context.Context, arg GetSpendingPolicyParams) (SpendingPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getSpendingPolicy, arg.OrganizationID, arg.Currency, arg.Amount)
+	var i SpendingPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Currency,
+		&i.MinAmount,
+		&i.RequiredApprovals,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listOrganizationMembers = `-- name: ListOrganizationMembers :many
+SELECT organization_id, username, role, created_at FROM organization_members
+WHERE organization_id = $1
+ORDER BY created_at, username
+`
+
+func (q *Queries) ListOrganizationMembers(ctx context.Context, organizationID int64) ([]OrganizationMember, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganizationMembers, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OrganizationMember{}
+	for rows.Next() {
+		var i OrganizationMember
+		if err := rows.Scan(
+			&i.OrganizationID,
+			&i.Username,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationsByMember = `-- name: ListOrganizationsByMember :many
+SELECT organizations.id, organizations.name, organizations.created_by, organizations.created_at FROM organizations
+JOIN organization_members ON organization_members.organization_id = organizations.id
+WHERE organization_members.username = $1
+ORDER BY organizations.id
+`
+
+func (q *Queries) ListOrganizationsByMember(ctx context.Context, username string) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, listOrganizationsByMember, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Organization{}
+	for rows.Next() {
+		var i Organization
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSpendingPolicies = `-- name: ListSpendingPolicies :many
+SELECT id, organization_id, currency, min_amount, required_approvals, created_at FROM spending_policies
+WHERE organization_id = $1
+ORDER BY currency, min_amount
+`
+
+func (q *Queries) ListSpendingPolicies(ctx context.Context, organizationID int64) ([]SpendingPolicy, error) {
+	rows, err := q.db.QueryContext(ctx, listSpendingPolicies, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SpendingPolicy{}
+	for rows.Next() {
+		var i SpendingPolicy
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.Currency,
+			&i.MinAmount,
+			&i.RequiredApprovals,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeOrganizationMember = `-- name: RemoveOrganizationMember :one
+DELETE FROM organization_members
+WHERE organization_id = $1 AND username = $2
+RETURNING organization_id, username, role, created_at
+`
+
+type RemoveOrganizationMemberParams struct {
+	OrganizationID int64  `json:"organization_id"`
+	Username       string `json:"username"`
+}
+
+func (q *Queries) RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, removeOrganizationMember, arg.OrganizationID, arg.Username)
+	var i OrganizationMember
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.Username,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const setSpendingPolicy = `-- name: SetSpendingPolicy :one
+INSERT INTO spending_policies (
+  organization_id, currency, min_amount, required_approvals
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (organization_id, currency, min_amount) DO UPDATE SET required_approvals = EXCLUDED.required_approvals
+RETURNING id, organization_id, currency, min_amount, required_approvals, created_at
+`
+
+type SetSpendingPolicyParams struct {
+	OrganizationID    int64  `json:"organization_id"`
+	Currency          string `json:"currency"`
+	MinAmount         int64  `json:"min_amount"`
+	RequiredApprovals int32  `json:"required_approvals"`
+}
+
+func (q *Queries) SetSpendingPolicy(ctx context.Context, arg SetSpendingPolicyParams) (SpendingPolicy, error) {
+	row := q.db.QueryRowContext(ctx, setSpendingPolicy,
+		arg.OrganizationID,
+		arg.Currency,
+		arg.MinAmount,
+		arg.RequiredApprovals,
+	)
+	var i SpendingPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Currency,
+		&i.MinAmount,
+		&i.RequiredApprovals,
+		&i.CreatedAt,
+	)
+	return i, err
+}
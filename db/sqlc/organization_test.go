@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const organizationPrefix = "org_test_"
+
+func createRandomOrganization(t *testing.T, admin User) Organization {
+	store := NewStore(testDB, testLogger)
+
+	organization, err := store.CreateOrganizationTx(context.Background(), CreateOrganizationParams{
+		Name:      util.RandomString(8),
+		CreatedBy: admin.Username,
+	})
+	assert.NoError(t, err)
+	assert.NotZero(t, organization.ID)
+	assert.Equal(t, admin.Username, organization.CreatedBy)
+
+	return organization
+}
+
+func TestCreateOrganizationTx(t *testing.T) {
+	ctx := context.Background()
+	admin := createRandomUser(t, organizationPrefix)
+	defer deleteTestingUser(ctx, organizationPrefix)
+
+	organization := createRandomOrganization(t, admin)
+	defer testQueries.DeleteOrganization(ctx, organization.ID)
+
+	member, err := testQueries.GetOrganizationMember(ctx, GetOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		Username:       admin.Username,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, util.OrganizationAdminRole, member.Role)
+
+	organizations, err := testQueries.ListOrganizationsByMember(ctx, admin.Username)
+	assert.NoError(t, err)
+	assert.Equal(t, []Organization{organization}, organizations)
+}
+
+func TestGetSpendingPolicy(t *testing.T) {
+	ctx := context.Background()
+	admin := createRandomUser(t, organizationPrefix)
+	defer deleteTestingUser(ctx, organizationPrefix)
+
+	organization := createRandomOrganization(t, admin)
+	defer testQueries.DeleteOrganization(ctx, organization.ID)
+
+	for _, arg := range []SetSpendingPolicyParams{
+		{OrganizationID: organization.ID, Currency: util.USD, MinAmount: 1000, RequiredApprovals: 1},
+		{OrganizationID: organization.ID, Currency: util.USD, MinAmount: 10000, RequiredApprovals: 2},
+		{OrganizationID: organization.ID, Currency: util.USD, MinAmount: 10000, RequiredApprovals: 3},
+	} {
+		_, err := testQueries.SetSpendingPolicy(ctx, arg)
+		assert.NoError(t, err)
+	}
+
+	policies, err := testQueries.ListSpendingPolicies(ctx, organization.ID)
+	assert.NoError(t, err)
+	assert.Len(t, policies, 2)
+
+	_, err = testQueries.GetSpendingPolicy(ctx, GetSpendingPolicyParams{
+		OrganizationID: organization.ID,
+		Currency:       util.USD,
+		Amount:         999,
+	})
+	assert.Error(t, err)
+
+	policy, err := testQueries.GetSpendingPolicy(ctx, GetSpendingPolicyParams{
+		OrganizationID: organization.ID,
+		Currency:       util.USD,
+		Amount:         5000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), policy.RequiredApprovals)
+
+	policy, err = testQueries.GetSpendingPolicy(ctx, GetSpendingPolicyParams{
+		OrganizationID: organization.ID,
+		Currency:       util.USD,
+		Amount:         10000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), policy.RequiredApprovals)
+}
+
+func TestOrganizationAccounts(t *testing.T) {
+	ctx := context.Background()
+	admin := createRandomUser(t, organizationPrefix)
+	defer deleteTestingUser(ctx, organizationPrefix)
+
+	organization := createRandomOrganization(t, admin)
+	defer testQueries.DeleteOrganization(ctx, organization.ID)
+
+	personal, err := testQueries.CreateAccount(ctx, CreateAccountParams{
+		Owner:       admin.Username,
+		Currency:    util.USD,
+		AccountType: util.CheckingAccount,
+	})
+	assert.NoError(t, err)
+	defer testQueries.DeleteAccount(ctx, personal.ID)
+
+	// the admin opening it already has a personal account in the same currency and type
+	business, err := testQueries.CreateAccount(ctx, CreateAccountParams{
+		Owner:          admin.Username,
+		Currency:       util.USD,
+		AccountType:    util.CheckingAccount,
+		OrganizationID: &organization.ID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, organization.ID, *business.OrganizationID)
+	defer testQueries.DeleteAccount(ctx, business.ID)
+
+	accounts, err := testQueries.ListOrganizationAccounts(ctx, &organization.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []Account{business}, accounts)
+
+	accounts, err = testQueries.ListAccounts(ctx, ListAccountsParams{
+		Owner:  admin.Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Account{personal}, accounts)
+}
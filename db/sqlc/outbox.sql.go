@@ -0,0 +1,161 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: outbox.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const claimDueOutboxEvents = `-- name: ClaimDueOutboxEvents :many
+UPDATE outbox_events
+  set next_attempt_at = now() + $1::int * interval '1 second'
+WHERE id IN (
+  SELECT id FROM outbox_events
+  WHERE published_at IS NULL AND next_attempt_at <= now()
+  ORDER BY id
+  LIMIT $2
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, event_type, schema_version, aggregate_id, payload, attempts, last_error, next_attempt_at, published_at, created_at
+`
+
+type ClaimDueOutboxEventsParams struct {
+	LeaseSeconds int32 `json:"lease_seconds"`
+	RowLimit     int32 `json:"row_limit"`
+}
+
+// claims the due events for the lease, concurrent relays skip them until it ends
+// and a relay which crashes before marking them leaves them due again
+func (q *Queries) ClaimDueOutboxEvents(ctx context.Context, arg ClaimDueOutboxEventsParams) ([]OutboxEvent, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueOutboxEvents, arg.LeaseSeconds, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OutboxEvent{}
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.SchemaVersion,
+			&i.AggregateID,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.PublishedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createOutboxEvent = `-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (
+  event_type,
+  schema_version,
+  aggregate_id,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, event_type, schema_version, aggregate_id, payload, attempts, last_error, next_attempt_at, published_at, created_at
+`
+
+type CreateOutboxEventParams struct {
+	EventType     string          `json:"event_type"`
+	SchemaVersion int32           `json:"schema_version"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error) {
+	row := q.db.QueryRowContext(ctx, createOutboxEvent,
+		arg.EventType,
+		arg.SchemaVersion,
+		arg.AggregateID,
+		arg.Payload,
+	)
+	var i OutboxEvent
+	err := row.Scan(
+		&i.ID,
+		&i.EventType,
+		&i.SchemaVersion,
+		&i.AggregateID,
+		&i.Payload,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.PublishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOutboxEvent = `-- name: GetOutboxEvent :one
+SELECT id, event_type, schema_version, aggregate_id, payload, attempts, last_error, next_attempt_at, published_at, created_at FROM outbox_events
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOutboxEvent(ctx context.Context, id int64) (OutboxEvent, error) {
+	row := q.db.QueryRowContext(ctx, getOutboxEvent, id)
+	var i OutboxEvent
+	err := row.Scan(
+		&i.ID,
+		&i.EventType,
+		&i.SchemaVersion,
+		&i.AggregateID,
+		&i.Payload,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.PublishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const markOutboxEventFailed = `-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+  set attempts = attempts + 1,
+  last_error = $2,
+  next_attempt_at = now() + least(power(2, attempts), 3600) * interval '1 second'
+WHERE id = $1
+`
+
+type MarkOutboxEventFailedParams struct {
+	ID        int64  `json:"id"`
+	LastError string `json:"last_error"`
+}
+
+// the retries back off exponentially up to an hour apart
+func (q *Queries) MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventFailed, arg.ID, arg.LastError)
+	return err
+}
+
+const markOutboxEventPublished = `-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+  set published_at = now(),
+  attempts = attempts + 1
+WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxEventPublished(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventPublished, id)
+	return err
+}
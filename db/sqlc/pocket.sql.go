@@ -0,0 +1,189 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: pocket.sql
+
+package db
+
+import (
+	"context"
+)
+
+const addPocketBalance = `-- name: AddPocketBalance :one
+UPDATE pockets
+  set balance = balance + $2
+WHERE id = $1 AND balance + $2 >= 0
+RETURNING id, account_id, name, balance, goal_amount, created_at
+`
+
+type AddPocketBalanceParams struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount"`
+}
+
+// fails with no rows when more is taken out than the pocket holds
+func (q *Queries) AddPocketBalance(ctx context.Context, arg AddPocketBalanceParams) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, addPocketBalance, arg.ID, arg.Amount)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.Balance,
+		&i.GoalAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPocket = `-- name: CreatePocket :one
+INSERT INTO pockets (
+  account_id, name, goal_amount
+) VALUES (
+  $1, $2, $3
+)
+RETURNING id, account_id, name, balance, goal_amount, created_at
+`
+
+type CreatePocketParams struct {
+	AccountID  int64  `json:"account_id"`
+	Name       string `json:"name"`
+	GoalAmount *int64 `json:"goal_amount"`
+}
+
+func (q *Queries) CreatePocket(ctx context.Context, arg CreatePocketParams) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, createPocket, arg.AccountID, arg.Name, arg.GoalAmount)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.Balance,
+		&i.GoalAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteEmptyPocket = `-- name: DeleteEmptyPocket :one
+DELETE FROM pockets
+WHERE id = $1 AND balance = 0
+RETURNING id, account_id, name, balance, goal_amount, created_at
+`
+
+func (q *Queries) DeleteEmptyPocket(ctx context.Context, id int64) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, deleteEmptyPocket, id)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.Balance,
+		&i.GoalAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPocket = `-- name: GetPocket :one
+SELECT id, account_id, name, balance, goal_amount, created_at FROM pockets
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetPocket(ctx context.Context, id int64) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, getPocket, id)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.Balance,
+		&i.GoalAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPocketForUpdate = `-- name: GetPocketForUpdate :one
+SELECT id, account_id, name, balance, goal_amount, created_at FROM pockets
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetPocketForUpdate(ctx context.Context, id int64) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, getPocketForUpdate, id)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.Balance,
+		&i.GoalAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listPockets = `-- name: ListPockets :many
+SELECT id, account_id, name, balance, goal_amount, created_at FROM pockets
+WHERE account_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListPockets(ctx context.Context, accountID int64) ([]Pocket, error) {
+	rows, err := q.db.QueryContext(ctx, listPockets, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Pocket{}
+	for rows.Next() {
+		var i Pocket
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Name,
+			&i.Balance,
+			&i.GoalAmount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePocket = `-- name: UpdatePocket :one
+UPDATE pockets
+  set name = $2,
+  goal_amount = $3
+WHERE id = $1
+RETURNING id, account_id, name, balance, goal_amount, created_at
+`
+
+type UpdatePocketParams struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	GoalAmount *int64 `json:"goal_amount"`
+}
+
+func (q *Queries) UpdatePocket(ctx context.Context, arg UpdatePocketParams) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, updatePocket, arg.ID, arg.Name, arg.GoalAmount)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.Balance,
+		&i.GoalAmount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
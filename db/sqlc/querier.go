@@ -6,32 +6,178 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	// only touch the ledger balance, used when capturing a hold
+	// whose amount has already been taken from the available balance
+	AddAccountLedgerBalance(ctx context.Context, arg AddAccountLedgerBalanceParams) (Account, error)
+	// adding an existing member changes their role
+	AddAccountMember(ctx context.Context, arg AddAccountMemberParams) (AccountMember, error)
+	// adding an existing member changes their role
+	AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error)
+	// fails with no rows when more is taken out than the pocket holds
+	AddPocketBalance(ctx context.Context, arg AddPocketBalanceParams) (Pocket, error)
+	// claims the due events for the lease, concurrent relays skip them until it ends
+	// and a relay which crashes before marking them leaves them due again
+	ClaimDueOutboxEvents(ctx context.Context, arg ClaimDueOutboxEventsParams) ([]OutboxEvent, error)
+	// claims the due deliveries for the lease, concurrent dispatchers skip them until it ends
+	// and a dispatcher which crashes before recording the attempts leaves them due again
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error)
+	CountTransferApprovals(ctx context.Context, transferID int64) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFeeRule(ctx context.Context, arg CreateFeeRuleParams) (FeeRule, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestRate(ctx context.Context, arg CreateInterestRateParams) (InterestRate, error)
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error)
+	CreatePocket(ctx context.Context, arg CreatePocketParams) (Pocket, error)
+	CreatePocketEntry(ctx context.Context, arg CreatePocketEntryParams) (Entry, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	// approving twice is a no-op, zero rows are affected
+	CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (int64, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchRow(ctx context.Context, arg CreateTransferBatchRowParams) (TransferBatchRow, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookAttempt(ctx context.Context, arg CreateWebhookAttemptParams) (WebhookAttempt, error)
+	// queues the event for every endpoint of the account which subscribed to it
+	CreateWebhookDeliveries(ctx context.Context, arg CreateWebhookDeliveriesParams) (int64, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
+	// fails with no rows when the debit goes past the overdraft limit
+	DebitAccountBalance(ctx context.Context, arg DebitAccountBalanceParams) (Account, error)
 	DeleteAccount(ctx context.Context, id int64) error
 	// for testing purpose
 	DeleteAccountByOwnerLike(ctx context.Context, owner string) error
+	DeleteApprovalThreshold(ctx context.Context, currency string) error
+	DeleteEmptyPocket(ctx context.Context, id int64) (Pocket, error)
 	// for testing purpose
 	DeleteEntryByAccountID(ctx context.Context, accountID int64) error
+	DeleteFeeRule(ctx context.Context, id int64) error
+	// for testing purpose
+	DeleteHoldByAccountID(ctx context.Context, accountID int64) error
+	// for testing purpose
+	DeleteInterestAccrualsByAccountID(ctx context.Context, accountID int64) error
+	DeleteInterestRate(ctx context.Context, id int64) error
+	DeleteOrganization(ctx context.Context, id int64) error
+	DeleteSpendingPolicy(ctx context.Context, arg DeleteSpendingPolicyParams) (SpendingPolicy, error)
 	// for testing purpose
 	DeleteTransfer(ctx context.Context, arg DeleteTransferParams) error
 	// for testing purpose
+	DeleteTransferBatchesByAccountID(ctx context.Context, fromAccountID int64) error
+	// for testing purpose
 	DeleteUserByUsernameLike(ctx context.Context, username string) error
+	DeleteWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error)
+	ExpirePendingTransfers(ctx context.Context, limit int32) ([]Transfer, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error)
+	GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetAccountsByIDs(ctx context.Context, ids []int64) ([]Account, error)
+	GetApprovalThreshold(ctx context.Context, currency string) (ApprovalThreshold, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetLastAuditEvent(ctx context.Context, chain int32) (AuditEvent, error)
+	GetLastInterestAccrual(ctx context.Context, arg GetLastInterestAccrualParams) (InterestAccrual, error)
+	// rules for a specific account type win over rules for every account type,
+	// then the newest rule wins
+	GetMatchingFeeRule(ctx context.Context, arg GetMatchingFeeRuleParams) (FeeRule, error)
+	GetOrganization(ctx context.Context, id int64) (Organization, error)
+	GetOrganizationMember(ctx context.Context, arg GetOrganizationMemberParams) (OrganizationMember, error)
+	GetOutboxEvent(ctx context.Context, id int64) (OutboxEvent, error)
+	GetPocket(ctx context.Context, id int64) (Pocket, error)
+	GetPocketForUpdate(ctx context.Context, id int64) (Pocket, error)
+	// the policy that applies to a transfer of the amount, the one with the highest min_amount
+	GetSpendingPolicy(ctx context.Context, arg GetSpendingPolicyParams) (SpendingPolicy, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransfersByIDs(ctx context.Context, ids []int64) ([]Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	// resolves a transfer recipient, system users can't receive transfers
+	GetUserByUsernameOrEmail(ctx context.Context, identifier string) (User, error)
+	GetUsersByUsernames(ctx context.Context, usernames []string) ([]User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
+	ListAccountMembershipsOfUser(ctx context.Context, arg ListAccountMembershipsOfUserParams) ([]AccountMember, error)
+	// personal accounts owned by the user or shared with them
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	// ListAccounts paginated by a cursor, the page starts after the account with after_id
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListAccountsWithUnpostedInterest(ctx context.Context, accrualDate time.Time) ([]int64, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	// a page of entries for every account, the graphql loader batches the connections with it
+	ListEntriesAfterOfAccounts(ctx context.Context, arg ListEntriesAfterOfAccountsParams) ([]Entry, error)
+	ListEntriesByTransfer(ctx context.Context, transferID *int64) ([]Entry, error)
+	ListExpiredHolds(ctx context.Context, limit int32) ([]Hold, error)
+	ListFeeRules(ctx context.Context, currency string) ([]FeeRule, error)
+	ListHolds(ctx context.Context, arg ListHoldsParams) ([]Hold, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	// savings accounts not accrued yet on accrual_date, with the rate effective on that day
+	// and the balance at the end of that day rebuilt from the entries made after it
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error)
+	ListOrganizationAccounts(ctx context.Context, organizationID *int64) ([]Account, error)
+	ListOrganizationMembers(ctx context.Context, organizationID int64) ([]OrganizationMember, error)
+	ListOrganizationsByMember(ctx context.Context, username string) ([]Organization, error)
+	// accounts with an overdraft rate not charged yet on accrual_date,
+	// with the balance at the end of that day rebuilt from the entries made after it
+	ListOverdraftChargeableAccounts(ctx context.Context, arg ListOverdraftChargeableAccountsParams) ([]ListOverdraftChargeableAccountsRow, error)
+	ListOverdrawnAccounts(ctx context.Context, arg ListOverdrawnAccountsParams) ([]Account, error)
+	ListPockets(ctx context.Context, accountID int64) ([]Pocket, error)
+	ListSpendingPolicies(ctx context.Context, organizationID int64) ([]SpendingPolicy, error)
+	ListTransferApprovals(ctx context.Context, transferID int64) ([]TransferApproval, error)
+	ListTransferBatchRows(ctx context.Context, batchID int64) ([]TransferBatchRow, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	// a page of transfers for every account, the graphql loader batches the connections with it.
+	// A transfer between two of the accounts is in both pages.
+	ListTransfersAfterOfAccounts(ctx context.Context, arg ListTransfersAfterOfAccountsParams) ([]ListTransfersAfterOfAccountsRow, error)
+	ListUnpostedInterestAccrualsForUpdate(ctx context.Context, arg ListUnpostedInterestAccrualsForUpdateParams) ([]InterestAccrual, error)
+	ListWebhookAttempts(ctx context.Context, deliveryID int64) ([]WebhookAttempt, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookEndpoints(ctx context.Context, accountID int64) ([]WebhookEndpoint, error)
+	// held until the end of the transaction, only one transaction at a time
+	// may append to a chain
+	LockAuditChain(ctx context.Context, chain int32) error
+	MarkInterestAccrualsPosted(ctx context.Context, arg MarkInterestAccrualsPostedParams) error
+	// the retries back off exponentially up to an hour apart
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	MarkTransferCompleted(ctx context.Context, arg MarkTransferCompletedParams) (Transfer, error)
+	MarkTransferRejected(ctx context.Context, arg MarkTransferRejectedParams) (Transfer, error)
+	// the retries back off exponentially up to an hour apart,
+	// the delivery fails for good after max_attempts
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error
+	MarkWebhookDeliverySucceeded(ctx context.Context, id int64) error
+	// queues the delivery again whatever its status, with all of its retries
+	RedeliverWebhook(ctx context.Context, id int64) (WebhookDelivery, error)
+	ReleaseAccountBalance(ctx context.Context, arg ReleaseAccountBalanceParams) (Account, error)
+	RemoveAccountMember(ctx context.Context, arg RemoveAccountMemberParams) (AccountMember, error)
+	RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (OrganizationMember, error)
+	ReserveAccountBalance(ctx context.Context, arg ReserveAccountBalanceParams) (Account, error)
+	// transfers in and out of an account, optionally filtered by a text query on
+	// description and reference, an exact reference and metadata containment.
+	// strpos matches the query literally, ILIKE would take % and _ in it as wildcards.
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error)
+	SetApprovalThreshold(ctx context.Context, arg SetApprovalThresholdParams) (ApprovalThreshold, error)
+	SetSpendingPolicy(ctx context.Context, arg SetSpendingPolicyParams) (SpendingPolicy, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraft(ctx context.Context, arg UpdateAccountOverdraftParams) (Account, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdatePocket(ctx context.Context, arg UpdatePocketParams) (Pocket, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	// like DebitAccountBalance but never dips into the overdraft,
+	// fails with no rows when the available balance is short
+	WithdrawAccountBalance(ctx context.Context, arg WithdrawAccountBalanceParams) (Account, error)
 }
 
 var _ Querier = (*Queries)(nil)
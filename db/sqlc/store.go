@@ -3,50 +3,99 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/metrics"
+	"github.com/novalyezu/simplebank-backend/util"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
 	DeleteTransferTx(ctx context.Context, arg DeleteTransferTxParams) error
+	PlaceHold(ctx context.Context, arg PlaceHoldParams) (PlaceHoldResult, error)
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error)
+	ReleaseHold(ctx context.Context, holdID int64) (ReleaseHoldResult, error)
+	ExpireHolds(ctx context.Context, limit int32) ([]Hold, error)
+	CalculateFee(ctx context.Context, arg CalculateFeeParams) (int64, error)
+	AccrueInterest(ctx context.Context, date time.Time) ([]InterestAccrual, error)
+	PostInterest(ctx context.Context, before time.Time) ([]Entry, error)
+	ApproveTransfer(ctx context.Context, arg ApproveTransferParams) (TransferTxResult, error)
+	RejectTransfer(ctx context.Context, arg RejectTransferParams) (Transfer, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	CreateOrganizationTx(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	MovePocketTx(ctx context.Context, arg MovePocketTxParams) (MovePocketTxResult, error)
+	RecordAuditEvent(ctx context.Context, record AuditRecord) error
+	VerifyAuditChain(ctx context.Context) (AuditChainResult, error)
+	RelayOutbox(ctx context.Context, limit int32, publish func(OutboxEvent) error) (int, error)
+	DeliverWebhooks(ctx context.Context, limit int32, deliver func(ClaimDueWebhookDeliveriesRow) WebhookAttemptResult) (int, error)
+	Ping(ctx context.Context) error
+	GetSchemaVersion(ctx context.Context) (SchemaVersion, error)
 }
 
 type SQLStore struct {
 	*Queries
-	db *sql.DB
+	db     *sql.DB
+	logger *slog.Logger
 }
 
-func NewStore(db *sql.DB) Store {
+// NewStore logs slow queries and rolled back transactions to logger
+// with the request ID of their context
+func NewStore(db *sql.DB, logger *slog.Logger) Store {
 	return &SQLStore{
 		db:      db,
-		Queries: New(db),
+		logger:  logger,
+		Queries: New(tracedDB{DBTX: loggedDB{DBTX: db, logger: logger}}),
 	}
 }
 
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+	txCtx, span := tracer.Start(ctx, "tx "+txName(), trace.WithAttributes(semconv.DBSystemPostgreSQL))
+	defer span.End()
+
+	tx, err := store.db.BeginTx(txCtx, nil)
 	if err != nil {
+		recordSpanError(span, err)
 		return err
 	}
 
-	q := New(tx)
+	q := New(tracedDB{DBTX: loggedDB{DBTX: tx, logger: store.logger}, txSpan: span})
 	err = fn(q)
 	if err != nil {
+		span.SetAttributes(txOutcomeKey.String("rollback"))
+		recordSpanError(span, err)
+		metrics.TransactionRollbacks.Inc()
+		logger := LoggerFrom(ctx, store.logger)
 		errRb := tx.Rollback()
 		if errRb != nil {
+			logger.ErrorContext(ctx, "transaction rollback failed", slog.Any("error", err), slog.Any("rollback_error", errRb))
 			return fmt.Errorf("tx err: %v, rb err: %v", err, errRb)
 		}
+		logger.InfoContext(ctx, "transaction rolled back", slog.Any("error", err))
 		return err
 	}
 
-	return tx.Commit()
+	span.SetAttributes(txOutcomeKey.String("commit"))
+	err = tx.Commit()
+	recordSpanError(span, err)
+	return err
 }
 
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Description   string          `json:"description"`
+	Reference     string          `json:"reference"`
+	Metadata      json.RawMessage `json:"metadata"`
 }
 
 type TransferTxResult struct {
@@ -55,95 +104,232 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	Fee         int64    `json:"fee"`
+	FeeEntry    Entry    `json:"fee_entry"`
 }
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
+	start := time.Now()
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
-
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+		result, err = transferTx(ctx, q, arg)
 		if err != nil {
 			return err
 		}
+		err = addTransferCreatedEvent(ctx, q, result.Transfer)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, result.Transfer.auditRecord())
+	})
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+	metrics.TransferTxDuration.WithLabelValues(transferOutcome(err)).Observe(time.Since(start).Seconds())
+	if err == nil {
+		metrics.ObserveTransfer(result.FromAccount.Currency, result.Transfer.Amount)
+	}
+	return result, err
+}
+
+// transferOutcome labels the metrics of a transfer transaction which ended with err
+func transferOutcome(err error) string {
+	switch {
+	case err == nil:
+		return metrics.OutcomeCommitted
+	case errors.Is(err, ErrInsufficientFunds):
+		return metrics.OutcomeInsufficientFunds
+	case errors.Is(err, sql.ErrNoRows):
+		return metrics.OutcomeNotFound
+	}
+	return metrics.OutcomeError
+}
+
+func (transfer Transfer) auditRecord() AuditRecord {
+	return AuditRecord{Action: AuditActionCreate, Entity: "transfer", EntityID: auditID(transfer.ID), After: transfer}
+}
+
+// transferTx creates a completed transfer and posts it within the transaction of q
+func transferTx(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	fee, err := calculateFee(ctx, q, CalculateFeeParams{
+		Currency:    fromAccount.Currency,
+		AccountType: fromAccount.AccountType,
+		Amount:      arg.Amount,
+	})
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Fee:           fee,
+		Description:   arg.Description,
+		Reference:     arg.Reference,
+		Metadata:      metadataOrEmpty(arg.Metadata),
+	})
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return postTransfer(ctx, q, transfer, fromAccount.Currency)
+}
+
+// postTransfer writes the entries of a completed transfer, including its fee,
+// and moves the balances
+func postTransfer(ctx context.Context, q *Queries, transfer Transfer, currency string) (TransferTxResult, error) {
+	result := TransferTxResult{
+		Transfer: transfer,
+		Fee:      transfer.Fee,
+	}
+
+	// the payer is debited amount plus fee, a sum which wraps around would credit them
+	debit, ok := util.AddAmounts(transfer.Amount, transfer.Fee)
+	if !ok {
+		return result, ErrAmountOverflow
+	}
+
+	var err error
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   transfer.FromAccountID,
+		Amount:      -transfer.Amount,
+		TransferID:  &transfer.ID,
+		Description: transfer.Description,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   transfer.ToAccountID,
+		Amount:      transfer.Amount,
+		TransferID:  &transfer.ID,
+		Description: transfer.Description,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	changes := map[int64]int64{
+		transfer.FromAccountID: -debit,
+		transfer.ToAccountID:   transfer.Amount,
+	}
+
+	if transfer.Fee > 0 {
+		feeAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+			Owner:       FeeRevenueOwner,
+			Currency:    currency,
+			AccountType: util.CheckingAccount,
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		result.FeeEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:   transfer.FromAccountID,
+			Amount:      -transfer.Fee,
+			TransferID:  &transfer.ID,
+			Description: feeEntryDescription,
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
 
-		// to prevent deadlock error because of 2 or more processes concurrently update same row on same table at same time
-		// we need to order/sort the queries update by ID ASC
-		// example case:
-		// go1 => goroutine1, go2 => goroutine2
-		// go1 transfer money from account1 to account2 with ID account1.ID=1 and account2.ID=2
-		// go2 transfer money from account2 to account1 with same ID as above
-		// 1. go1 and go2 running concurrently and lets say go1 run first
-		// 2. go1 update account1 balance first and will locked account1 row
-		// 3. go2 try to update account1 balance first also and it will be blocked by go1 and waiting until tx commit or rollback
-		// 4. go1 continue the process update account2 balance and commit, the lock is released
-		// 5. go2 can continue the process to update account1 balance and then account2 and then commit
-		// what if we don't order/sort the queries update by ID ASC? deadlock will happen, but how?
-		// see on steps 3, imagine go2 try to update account2 first instead of account1
-		// the process of go2 will not be blocked, lets see:
-		// 1. go1 and go2 running concurrently and lets say go1 run first
-		// 2. go1 update account1 balance first and will locked account1 row
-		// 3. go2 update account2 balance first and will locked account2 row
-		// 4. go1 want to continue the process to update account2 balance, but account2 is locked and blocked by go2, go1 is waiting here
-		// 5. go2 try to update account1 balance, but account1 is locked and blocked by go1, go2 is waiting here
-		// 6. go1 and go2 are waiting each other, so deadlock will happen, it is just because we don't order/sort the queries.
-		// Order Queries MATTERS!!!!
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = moveBalance(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-			if err != nil {
-				return err
-			}
-		} else {
-			result.ToAccount, result.FromAccount, err = moveBalance(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-			if err != nil {
-				return err
-			}
+		_, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:   feeAccount.ID,
+			Amount:      transfer.Fee,
+			TransferID:  &transfer.ID,
+			Description: feeEntryDescription,
+		})
+		if err != nil {
+			return result, err
 		}
 
-		return nil
-	})
+		changes[feeAccount.ID] += transfer.Fee
+	}
 
-	return result, err
+	accounts, err := addBalances(ctx, q, changes, transfer.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount = accounts[transfer.FromAccountID]
+	result.ToAccount = accounts[transfer.ToAccountID]
+	return result, nil
 }
 
-func moveBalance(
-	ctx context.Context,
-	q *Queries,
-	account1ID int64,
-	amount1 int64,
-	account2ID int64,
-	amount2 int64,
-) (account1 Account, account2 Account, err error) {
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     account1ID,
-		Amount: amount1,
-	})
-	if err != nil {
-		return
+// metadataOrEmpty keeps the metadata column a JSON object when none is given
+func metadataOrEmpty(metadata json.RawMessage) json.RawMessage {
+	if len(metadata) == 0 {
+		return json.RawMessage("{}")
 	}
+	return metadata
+}
 
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     account2ID,
-		Amount: amount2,
-	})
-	return
+// addBalances applies the balance changes keyed by account ID and returns the updated accounts.
+// Debits of the funded accounts must stay within their available balance plus overdraft limit,
+// otherwise ErrInsufficientFunds is returned and the transaction has to be rolled back.
+//
+// to prevent deadlock error because of 2 or more processes concurrently update same row on same table at same time
+// we need to order/sort the queries update by ID ASC
+// example case:
+// go1 => goroutine1, go2 => goroutine2
+// go1 transfer money from account1 to account2 with ID account1.ID=1 and account2.ID=2
+// go2 transfer money from account2 to account1 with same ID as above
+// 1. go1 and go2 running concurrently and lets say go1 run first
+// 2. go1 update account1 balance first and will locked account1 row
+// 3. go2 try to update account1 balance first also and it will be blocked by go1 and waiting until tx commit or rollback
+// 4. go1 continue the process update account2 balance and commit, the lock is released
+// 5. go2 can continue the process to update account1 balance and then account2 and then commit
+// what if we don't order/sort the queries update by ID ASC? deadlock will happen, but how?
+// see on steps 3, imagine go2 try to update account2 first instead of account1
+// the process of go2 will not be blocked, lets see:
+// 1. go1 and go2 running concurrently and lets say go1 run first
+// 2. go1 update account1 balance first and will locked account1 row
+// 3. go2 update account2 balance first and will locked account2 row
+// 4. go1 want to continue the process to update account2 balance, but account2 is locked and blocked by go2, go1 is waiting here
+// 5. go2 try to update account1 balance, but account1 is locked and blocked by go1, go2 is waiting here
+// 6. go1 and go2 are waiting each other, so deadlock will happen, it is just because we don't order/sort the queries.
+// Order Queries MATTERS!!!!
+func addBalances(ctx context.Context, q *Queries, changes map[int64]int64, funded ...int64) (map[int64]Account, error) {
+	ids := make([]int64, 0, len(changes))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		var account Account
+		var err error
+
+		if changes[id] < 0 && slices.Contains(funded, id) {
+			account, err = q.DebitAccountBalance(ctx, DebitAccountBalanceParams{
+				ID:     id,
+				Amount: -changes[id],
+			})
+			if err == sql.ErrNoRows {
+				err = ErrInsufficientFunds
+			}
+		} else {
+			account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				ID:     id,
+				Amount: changes[id],
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+	}
+
+	return accounts, nil
 }
 
 type DeleteTransferTxParams struct {
@@ -156,17 +342,18 @@ func (store *SQLStore) DeleteTransferTx(ctx context.Context, arg DeleteTransferT
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
-		err = q.DeleteTransfer(ctx, DeleteTransferParams(arg))
+		// entries reference the transfer, they go first
+		err = q.DeleteEntryByAccountID(ctx, arg.FromAccountID)
 		if err != nil {
 			return err
 		}
 
-		err = q.DeleteEntryByAccountID(ctx, arg.FromAccountID)
+		err = q.DeleteEntryByAccountID(ctx, arg.ToAccountID)
 		if err != nil {
 			return err
 		}
 
-		err = q.DeleteEntryByAccountID(ctx, arg.ToAccountID)
+		err = q.DeleteTransfer(ctx, DeleteTransferParams(arg))
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/metrics"
+)
+
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusCompleted = "completed"
+	TransferStatusRejected  = "rejected"
+	TransferStatusExpired   = "expired"
+)
+
+var (
+	ErrTransferNotPending      = errors.New("transfer is not pending")
+	ErrTransferExpired         = errors.New("transfer is expired")
+	ErrTransferAlreadyApproved = errors.New("transfer is already approved by the user")
+)
+
+type ApproveTransferParams struct {
+	TransferID int64  `json:"transfer_id"`
+	ApprovedBy string `json:"approved_by"`
+}
+
+// ApproveTransfer records the approval of a pending transfer and executes it once it has
+// as many approvals as it requires, until then only the still pending transfer is returned.
+// The fee is calculated with the rules in effect at the last approval because that is
+// when the money moves, the TransferCreated event is written then too.
+func (store *SQLStore) ApproveTransfer(ctx context.Context, arg ApproveTransferParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		transfer, err := lockPendingTransfer(ctx, q, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		if transfer.ExpiresAt != nil && !transfer.ExpiresAt.After(time.Now()) {
+			return ErrTransferExpired
+		}
+
+		added, err := q.CreateTransferApproval(ctx, CreateTransferApprovalParams{
+			TransferID: transfer.ID,
+			Username:   arg.ApprovedBy,
+		})
+		if err != nil {
+			return err
+		}
+		if added == 0 {
+			return ErrTransferAlreadyApproved
+		}
+
+		approvals, err := q.CountTransferApprovals(ctx, transfer.ID)
+		if err != nil {
+			return err
+		}
+		if approvals < int64(transfer.RequiredApprovals) {
+			result.Transfer = transfer
+			return recordAudit(ctx, q, transfer.decisionAuditRecord(AuditActionApprove, transfer))
+		}
+
+		fromAccount, err := q.GetAccount(ctx, transfer.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		fee, err := calculateFee(ctx, q, CalculateFeeParams{
+			Currency:    fromAccount.Currency,
+			AccountType: fromAccount.AccountType,
+			Amount:      transfer.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		pending := transfer
+		transfer, err = q.MarkTransferCompleted(ctx, MarkTransferCompletedParams{
+			ID:        transfer.ID,
+			Fee:       fee,
+			DecidedBy: &arg.ApprovedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = postTransfer(ctx, q, transfer, fromAccount.Currency)
+		if err != nil {
+			return err
+		}
+		err = addTransferCreatedEvent(ctx, q, result.Transfer)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, pending.decisionAuditRecord(AuditActionApprove, transfer))
+	})
+
+	if err == nil && result.Transfer.Status == TransferStatusCompleted {
+		metrics.ObserveTransfer(result.FromAccount.Currency, result.Transfer.Amount)
+	}
+	return result, err
+}
+
+type RejectTransferParams struct {
+	TransferID int64  `json:"transfer_id"`
+	RejectedBy string `json:"rejected_by"`
+}
+
+// RejectTransfer cancels a pending transfer, nothing was moved so nothing is reversed.
+func (store *SQLStore) RejectTransfer(ctx context.Context, arg RejectTransferParams) (Transfer, error) {
+	var transfer Transfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pending, err := lockPendingTransfer(ctx, q, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		transfer, err = q.MarkTransferRejected(ctx, MarkTransferRejectedParams{
+			ID:        arg.TransferID,
+			DecidedBy: &arg.RejectedBy,
+		})
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, pending.decisionAuditRecord(AuditActionReject, transfer))
+	})
+
+	return transfer, err
+}
+
+// decisionAuditRecord describes the change of the pending transfer into updated,
+// an approval which isn't the last one leaves it as it was
+func (transfer Transfer) decisionAuditRecord(action string, updated Transfer) AuditRecord {
+	return AuditRecord{Action: action, Entity: "transfer", EntityID: auditID(transfer.ID), Before: transfer, After: updated}
+}
+
+func lockPendingTransfer(ctx context.Context, q *Queries, transferID int64) (Transfer, error) {
+	transfer, err := q.GetTransferForUpdate(ctx, transferID)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if transfer.Status != TransferStatusPending {
+		return Transfer{}, ErrTransferNotPending
+	}
+	return transfer, nil
+}
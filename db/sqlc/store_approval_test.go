@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const approvalTestPrefix = "approval_test_"
+
+func createPendingTestingTransfer(t *testing.T, fromAccount Account, toAccount Account, expiresAt time.Time) Transfer {
+	transfer, err := testQueries.CreatePendingTransfer(context.Background(), CreatePendingTransferParams{
+		FromAccountID:     fromAccount.ID,
+		ToAccountID:       toAccount.ID,
+		Amount:            100,
+		Description:       "pending",
+		Metadata:          json.RawMessage("{}"),
+		ExpiresAt:         &expiresAt,
+		RequiredApprovals: 1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TransferStatusPending, transfer.Status)
+	assert.Nil(t, transfer.DecidedBy)
+	return transfer
+}
+
+func TestApproveTransfer(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account1 := fundTestingAccount(t, createRandomAccount(t, approvalTestPrefix), 1000)
+	account2 := createRandomAccount(t, approvalTestPrefix)
+
+	defer deleteTestingAccount(ctx, approvalTestPrefix)
+	defer store.DeleteTransferTx(ctx, DeleteTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+	})
+
+	transfer := createPendingTestingTransfer(t, account1, account2, time.Now().Add(time.Hour))
+
+	// nothing moves while the transfer is pending
+	entries, err := store.ListEntriesByTransfer(ctx, &transfer.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	result, err := store.ApproveTransfer(ctx, ApproveTransferParams{
+		TransferID: transfer.ID,
+		ApprovedBy: account1.Owner,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	assert.Equal(t, account1.Owner, *result.Transfer.DecidedBy)
+	assert.NotNil(t, result.Transfer.DecidedAt)
+	assert.Equal(t, transfer.ID, *result.FromEntry.TransferID)
+	assert.Equal(t, account1.Balance-transfer.Amount, result.FromAccount.Balance)
+	assert.Equal(t, account2.Balance+transfer.Amount, result.ToAccount.Balance)
+
+	// the event of the transfer is written once the money moves
+	var event *OutboxEvent
+	for {
+		handled, err := store.RelayOutbox(ctx, 100, func(published OutboxEvent) error {
+			if published.EventType == EventTransferCreated && published.AggregateID == strconv.FormatInt(transfer.ID, 10) {
+				event = &published
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		if handled < 100 {
+			break
+		}
+	}
+	if assert.NotNil(t, event) {
+		var payload Transfer
+		assert.NoError(t, json.Unmarshal(event.Payload, &payload))
+		assert.Equal(t, TransferStatusCompleted, payload.Status)
+	}
+
+	_, err = store.ApproveTransfer(ctx, ApproveTransferParams{
+		TransferID: transfer.ID,
+		ApprovedBy: account1.Owner,
+	})
+	assert.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestApproveExpiredTransfer(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account1 := fundTestingAccount(t, createRandomAccount(t, approvalTestPrefix), 1000)
+	account2 := createRandomAccount(t, approvalTestPrefix)
+
+	defer deleteTestingAccount(ctx, approvalTestPrefix)
+	defer deleteTestingTransfer(ctx, account1.ID, account2.ID)
+
+	transfer := createPendingTestingTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	_, err := store.ApproveTransfer(ctx, ApproveTransferParams{
+		TransferID: transfer.ID,
+		ApprovedBy: account1.Owner,
+	})
+	assert.ErrorIs(t, err, ErrTransferExpired)
+
+	unchanged, err := store.GetAccount(ctx, account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account1.Balance, unchanged.Balance)
+
+	expired, err := store.ExpirePendingTransfers(ctx, 100)
+	assert.NoError(t, err)
+
+	var found bool
+	for _, e := range expired {
+		if e.ID == transfer.ID {
+			found = true
+			assert.Equal(t, TransferStatusExpired, e.Status)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestRejectTransfer(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account1 := createRandomAccount(t, approvalTestPrefix)
+	account2 := createRandomAccount(t, approvalTestPrefix)
+
+	defer deleteTestingAccount(ctx, approvalTestPrefix)
+	defer deleteTestingTransfer(ctx, account1.ID, account2.ID)
+
+	transfer := createPendingTestingTransfer(t, account1, account2, time.Now().Add(time.Hour))
+
+	rejected, err := store.RejectTransfer(ctx, RejectTransferParams{
+		TransferID: transfer.ID,
+		RejectedBy: account1.Owner,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TransferStatusRejected, rejected.Status)
+	assert.Equal(t, account1.Owner, *rejected.DecidedBy)
+
+	_, err = store.ApproveTransfer(ctx, ApproveTransferParams{
+		TransferID: transfer.ID,
+		ApprovedBy: account1.Owner,
+	})
+	assert.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestApprovalThreshold(t *testing.T) {
+	ctx := context.Background()
+	currency := "XTS"
+	defer testQueries.DeleteApprovalThreshold(ctx, currency)
+
+	threshold, err := testQueries.SetApprovalThreshold(ctx, SetApprovalThresholdParams{
+		Currency:  currency,
+		MinAmount: 1000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), threshold.MinAmount)
+
+	threshold, err = testQueries.SetApprovalThreshold(ctx, SetApprovalThresholdParams{
+		Currency:  currency,
+		MinAmount: 5000,
+	})
+	assert.NoError(t, err)
+
+	found, err := testQueries.GetApprovalThreshold(ctx, currency)
+	assert.NoError(t, err)
+	assert.Equal(t, threshold, found)
+}
+
+func TestApproveTransferWithSeveralApprovals(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account1 := fundTestingAccount(t, createRandomAccount(t, approvalTestPrefix), 1000)
+	account2 := createRandomAccount(t, approvalTestPrefix)
+	approver := createRandomUser(t, approvalTestPrefix)
+
+	defer deleteTestingUser(ctx, approvalTestPrefix)
+	defer deleteTestingAccount(ctx, approvalTestPrefix)
+	defer store.DeleteTransferTx(ctx, DeleteTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+	})
+
+	expiresAt := time.Now().Add(time.Hour)
+	transfer, err := testQueries.CreatePendingTransfer(ctx, CreatePendingTransferParams{
+		FromAccountID:     account1.ID,
+		ToAccountID:       account2.ID,
+		Amount:            100,
+		Metadata:          json.RawMessage("{}"),
+		ExpiresAt:         &expiresAt,
+		InitiatedBy:       &account1.Owner,
+		RequiredApprovals: 2,
+	})
+	assert.NoError(t, err)
+
+	result, err := store.ApproveTransfer(ctx, ApproveTransferParams{
+		TransferID: transfer.ID,
+		ApprovedBy: account1.Owner,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TransferStatusPending, result.Transfer.Status)
+	assert.Empty(t, result.FromEntry)
+
+	_, err = store.ApproveTransfer(ctx, ApproveTransferParams{
+		TransferID: transfer.ID,
+		ApprovedBy: account1.Owner,
+	})
+	assert.ErrorIs(t, err, ErrTransferAlreadyApproved)
+
+	result, err = store.ApproveTransfer(ctx, ApproveTransferParams{
+		TransferID: transfer.ID,
+		ApprovedBy: approver.Username,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	assert.Equal(t, approver.Username, *result.Transfer.DecidedBy)
+	assert.Equal(t, account1.Balance-transfer.Amount, result.FromAccount.Balance)
+
+	approvals, err := store.ListTransferApprovals(ctx, transfer.ID)
+	assert.NoError(t, err)
+	assert.Len(t, approvals, 2)
+}
@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// AuditSystemActor is the actor of the changes made without a user, e.g. by the workers
+const AuditSystemActor = "system"
+
+const (
+	AuditActionCreate   = "create"
+	AuditActionUpdate   = "update"
+	AuditActionDelete   = "delete"
+	AuditActionLogin    = "login"
+	AuditActionApprove  = "approve"
+	AuditActionReject   = "reject"
+	AuditActionCapture  = "capture"
+	AuditActionRelease  = "release"
+	AuditActionExpire   = "expire"
+	AuditActionAccrue   = "accrue"
+	AuditActionPost     = "post"
+	AuditActionDeposit  = "deposit"
+	AuditActionWithdraw = "withdraw"
+)
+
+const auditVerifyBatchSize = 1000
+
+// auditChains is the number of chains the audit log is split in, the transactions
+// appending to different chains don't wait for each other
+const auditChains = 16
+
+// AuditInfo tells who is behind the changes made with a context
+type AuditInfo struct {
+	Actor     string
+	ClientIP  string
+	RequestID string
+}
+
+type auditInfoKey struct{}
+
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+// AuditInfoFrom returns the audit info of the context,
+// the actor is AuditSystemActor when there is none
+func AuditInfoFrom(ctx context.Context) AuditInfo {
+	info, _ := ctx.Value(auditInfoKey{}).(AuditInfo)
+	if info.Actor == "" {
+		info.Actor = AuditSystemActor
+	}
+	return info
+}
+
+// AuditRecord is a change to append to the audit log. Before and After are stored as JSON,
+// Before is nil for a created entity and After is nil for a removed one.
+type AuditRecord struct {
+	Action   string
+	Entity   string
+	EntityID string
+	Before   any
+	After    any
+}
+
+// RecordAuditEvent appends an event which doesn't come with a change of its own, e.g. a login.
+func (store *SQLStore) RecordAuditEvent(ctx context.Context, record AuditRecord) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		return recordAudit(ctx, q, record)
+	})
+}
+
+// recordAudit appends the records to the audit log within the transaction of q.
+// All the records go to the chain of the first one, see auditChain.
+// It must be the last statement of the transaction, the chain stays locked until the commit
+// so the transaction must not wait for any other lock once it holds it.
+func recordAudit(ctx context.Context, q *Queries, records ...AuditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	chain := auditChain(records[0])
+	err := q.LockAuditChain(ctx, chain)
+	if err != nil {
+		return err
+	}
+
+	var prevHash string
+	last, err := q.GetLastAuditEvent(ctx, chain)
+	if err == nil {
+		prevHash = last.Hash
+	} else if err != sql.ErrNoRows {
+		return err
+	}
+
+	info := AuditInfoFrom(ctx)
+	for _, record := range records {
+		before, err := json.Marshal(record.Before)
+		if err != nil {
+			return err
+		}
+		after, err := json.Marshal(record.After)
+		if err != nil {
+			return err
+		}
+
+		event := AuditEvent{
+			Actor:     info.Actor,
+			Action:    record.Action,
+			Entity:    record.Entity,
+			EntityID:  record.EntityID,
+			Before:    before,
+			After:     after,
+			ClientIp:  info.ClientIP,
+			RequestID: info.RequestID,
+			PrevHash:  prevHash,
+			// postgres keeps microseconds, the hash must survive the round trip
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		}
+		event.Hash = event.ComputeHash()
+
+		_, err = q.CreateAuditEvent(ctx, CreateAuditEventParams{
+			Actor:     event.Actor,
+			Action:    event.Action,
+			Entity:    event.Entity,
+			EntityID:  event.EntityID,
+			Before:    event.Before,
+			After:     event.After,
+			ClientIp:  event.ClientIp,
+			RequestID: event.RequestID,
+			PrevHash:  event.PrevHash,
+			Hash:      event.Hash,
+			CreatedAt: event.CreatedAt,
+			Chain:     chain,
+		})
+		if err != nil {
+			return err
+		}
+		prevHash = event.Hash
+	}
+
+	return nil
+}
+
+// auditChain picks the chain of an entity, the events of the same entity
+// are always appended to the same chain
+func auditChain(record AuditRecord) int32 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s/%s", record.Entity, record.EntityID)
+	return int32(h.Sum32() % auditChains)
+}
+
+// ComputeHash hashes the event together with the hash of the event before it.
+// Every field is prefixed with its length so moving text between fields changes the hash.
+func (event AuditEvent) ComputeHash() string {
+	fields := []string{
+		event.PrevHash,
+		event.Actor,
+		event.Action,
+		event.Entity,
+		event.EntityID,
+		string(event.Before),
+		string(event.After),
+		event.ClientIp,
+		event.RequestID,
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	h := sha256.New()
+	for _, field := range fields {
+		fmt.Fprintf(h, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type AuditChainResult struct {
+	Valid  bool  `json:"valid"`
+	Events int64 `json:"events"`
+	// BrokenAt is the first event which doesn't match its own hash or the hash of the event before it
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+	// Heads are the hashes of the last valid event of every chain, keeping a copy of them
+	// elsewhere also makes removing events from the end of the chains detectable
+	Heads map[int32]string `json:"heads"`
+}
+
+// VerifyAuditChain walks the audit log from the first event and recomputes every hash,
+// every event must follow the event before it in its own chain.
+func (store *SQLStore) VerifyAuditChain(ctx context.Context) (AuditChainResult, error) {
+	result := AuditChainResult{Valid: true, Heads: map[int32]string{}}
+
+	var lastID int64
+	for {
+		events, err := store.ListAuditEventsAfter(ctx, ListAuditEventsAfterParams{
+			ID:    lastID,
+			Limit: auditVerifyBatchSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, event := range events {
+			if event.PrevHash != result.Heads[event.Chain] || event.ComputeHash() != event.Hash {
+				result.Valid = false
+				result.BrokenAt = &event.ID
+				return result, nil
+			}
+			result.Events++
+			result.Heads[event.Chain] = event.Hash
+			lastID = event.ID
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const storeAuditPrefix = "store_audit_test_"
+
+func TestRecordAuditEvent(t *testing.T) {
+	store := NewStore(testDB, testLogger)
+	ctx := WithAuditInfo(context.Background(), AuditInfo{
+		Actor:     storeAuditPrefix + "actor",
+		ClientIP:  "10.0.0.1",
+		RequestID: "request-1",
+	})
+
+	err := store.RecordAuditEvent(ctx, AuditRecord{
+		Action:   AuditActionLogin,
+		Entity:   "user",
+		EntityID: storeAuditPrefix + "actor",
+	})
+	assert.NoError(t, err)
+
+	events, err := testQueries.ListAuditEvents(ctx, ListAuditEventsParams{
+		Actor: sql.NullString{String: storeAuditPrefix + "actor", Valid: true},
+		Limit: 2,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, events)
+
+	event := events[0]
+	assert.Equal(t, AuditActionLogin, event.Action)
+	assert.Equal(t, "10.0.0.1", event.ClientIp)
+	assert.Equal(t, "request-1", event.RequestID)
+	assert.JSONEq(t, "null", string(event.After))
+	assert.Equal(t, event.ComputeHash(), event.Hash)
+	if len(events) > 1 {
+		assert.Equal(t, events[1].Hash, event.PrevHash)
+	}
+}
+
+func TestCreateAccountIsAudited(t *testing.T) {
+	store := NewStore(testDB, testLogger)
+	ctx := context.Background()
+	user := createRandomUser(t, storeAuditPrefix)
+	defer deleteTestingAccount(ctx, storeAuditPrefix)
+
+	account, err := store.CreateAccount(WithAuditInfo(ctx, AuditInfo{Actor: user.Username}), CreateAccountParams{
+		Owner:       user.Username,
+		Currency:    util.IDR,
+		AccountType: util.CheckingAccount,
+	})
+	assert.NoError(t, err)
+
+	events, err := testQueries.ListAuditEvents(ctx, ListAuditEventsParams{
+		Entity:   sql.NullString{String: "account", Valid: true},
+		EntityID: sql.NullString{String: strconv.FormatInt(account.ID, 10), Valid: true},
+		Limit:    10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, user.Username, events[0].Actor)
+	assert.Equal(t, AuditActionCreate, events[0].Action)
+
+	var after Account
+	err = json.Unmarshal(events[0].After, &after)
+	assert.NoError(t, err)
+	assert.Equal(t, account.ID, after.ID)
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	store := NewStore(testDB, testLogger)
+	ctx := context.Background()
+
+	record := AuditRecord{Action: AuditActionLogin, Entity: "user", EntityID: storeAuditPrefix}
+	err := store.RecordAuditEvent(ctx, record)
+	assert.NoError(t, err)
+
+	result, err := store.VerifyAuditChain(ctx)
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Nil(t, result.BrokenAt)
+	assert.NotZero(t, result.Events)
+
+	chain := auditChain(record)
+	last, err := testQueries.GetLastAuditEvent(ctx, chain)
+	assert.NoError(t, err)
+	assert.Equal(t, chain, last.Chain)
+	assert.Equal(t, last.Hash, result.Heads[chain])
+
+	// the log can't be edited in place
+	_, err = testDB.ExecContext(ctx, "UPDATE audit_events SET actor = 'someone' WHERE id = $1", last.ID)
+	assert.Error(t, err)
+}
+
+func TestAuditEventComputeHash(t *testing.T) {
+	event := AuditEvent{
+		Actor:     "alice",
+		Action:    AuditActionCreate,
+		Entity:    "account",
+		EntityID:  "1",
+		Before:    json.RawMessage("null"),
+		After:     json.RawMessage(`{"id":1}`),
+		PrevHash:  "abc",
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC),
+	}
+	hash := event.ComputeHash()
+	assert.Len(t, hash, 64)
+
+	// the time zone of the database session doesn't matter
+	local := event
+	local.CreatedAt = event.CreatedAt.In(time.FixedZone("WIB", 7*60*60))
+	assert.Equal(t, hash, local.ComputeHash())
+
+	changed := event
+	changed.After = json.RawMessage(`{"id":2}`)
+	assert.NotEqual(t, hash, changed.ComputeHash())
+
+	// text moved from one field to the next is still a change
+	moved := event
+	moved.Actor, moved.Action = "alicecreat", "e"
+	assert.NotEqual(t, hash, moved.ComputeHash())
+}
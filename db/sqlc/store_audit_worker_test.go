@@ -0,0 +1,168 @@
+package db_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/novalyezu/simplebank-backend/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the worker imports db, this test lives in its own package to run it against the store
+
+const storeAuditWorkerPrefix = "store_audit_worker_test_"
+
+func openTestingStore(t *testing.T) db.Store {
+	// the environment is loaded by TestMain of package db
+	dbSource := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable",
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_HOST"),
+		os.Getenv("POSTGRES_PORT"),
+		os.Getenv("POSTGRES_DATABASE"),
+	)
+
+	conn, err := sql.Open("postgres", dbSource)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return db.NewStore(conn, slog.Default())
+}
+
+func createFundedAccount(t *testing.T, store db.Store, currency string, balance int64) db.Account {
+	ctx := context.Background()
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username:       storeAuditWorkerPrefix + util.RandomString(6),
+		HashedPassword: util.RandomString(6),
+		FullName:       util.RandomString(6),
+		Email:          util.RandomEmail(6),
+	})
+	require.NoError(t, err)
+
+	account, err := store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner:       user.Username,
+		Balance:     balance,
+		Currency:    currency,
+		AccountType: util.CheckingAccount,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestAuditChainWithHoldExpirer(t *testing.T) {
+	ctx := context.Background()
+	store := openTestingStore(t)
+
+	currency := util.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 1000)
+	account2 := createFundedAccount(t, store, currency, 1000)
+
+	defer store.DeleteAccountByOwnerLike(ctx, storeAuditWorkerPrefix)
+	defer store.DeleteTransferTx(ctx, db.DeleteTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID})
+	defer store.DeleteTransferTx(ctx, db.DeleteTransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID})
+	defer store.DeleteHoldByAccountID(ctx, account2.ID)
+	defer store.DeleteHoldByAccountID(ctx, account1.ID)
+
+	n := 10
+	amount := int64(10)
+
+	// expired holds for the worker and for ExpireHolds to race over
+	for i := 0; i < n; i++ {
+		for _, account := range []db.Account{account1, account2} {
+			_, err := store.PlaceHold(ctx, db.PlaceHoldParams{
+				AccountID: account.ID,
+				Amount:    amount,
+				ExpiresAt: time.Now().Add(-time.Minute),
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	var logs bytes.Buffer
+	workerCtx, stop := context.WithCancel(ctx)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		worker.NewHoldExpirer(store, time.Millisecond, slog.New(slog.NewTextHandler(&logs, nil))).Run(workerCtx)
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3*n)
+	for i := 0; i < n; i++ {
+		fromAccount, toAccount := account1, account2
+		if i%2 == 1 {
+			fromAccount, toAccount = account2, account1
+		}
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := store.TransferTx(ctx, db.TransferTxParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := store.ExpireHolds(ctx, 2)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	// whatever is left is expired by the worker
+	assert.Eventually(t, func() bool {
+		for _, account := range []db.Account{account1, account2} {
+			holds, err := store.ListHolds(ctx, db.ListHoldsParams{AccountID: account.ID, Limit: int32(n)})
+			if err != nil {
+				return false
+			}
+			for _, hold := range holds {
+				if hold.Status == db.HoldStatusPending {
+					return false
+				}
+			}
+		}
+		return true
+	}, 10*time.Second, 10*time.Millisecond)
+
+	stop()
+	<-workerDone
+	// only a run interrupted by the stop may have failed
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if line != "" {
+			assert.Contains(t, line, context.Canceled.Error())
+		}
+	}
+
+	for _, account := range []db.Account{account1, account2} {
+		updated, err := store.GetAccount(ctx, account.ID)
+		require.NoError(t, err)
+		// every hold was released exactly once
+		assert.Equal(t, updated.Balance, updated.AvailableBalance)
+	}
+
+	result, err := store.VerifyAuditChain(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Nil(t, result.BrokenAt)
+}
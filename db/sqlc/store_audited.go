@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// The queries below change state on their own, the store runs each of them in a
+// transaction with its audit event and its outbox event if it has one. Within the
+// transactions of the store the queries are called on Queries and are audited by
+// the transaction instead.
+
+// auditedTx runs fn and appends the record made of its result in the same transaction
+func auditedTx[T any](store *SQLStore, ctx context.Context, fn func(q *Queries) (T, error), record func(T) AuditRecord) (T, error) {
+	var result T
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = fn(q)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, record(result))
+	})
+
+	return result, err
+}
+
+func auditID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+func (store *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	return auditedTx(store, ctx, func(q *Queries) (User, error) {
+		user, err := q.CreateUser(ctx, arg)
+		if err != nil {
+			return User{}, err
+		}
+		return user, addOutboxEvent(ctx, q, EventUserCreated, user.Username, UserCreatedPayload{
+			Username:  user.Username,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			Role:      user.Role,
+			CreatedAt: user.CreatedAt,
+		})
+	}, func(user User) AuditRecord {
+		// the password hash stays out of the log
+		user.HashedPassword = ""
+		return AuditRecord{Action: AuditActionCreate, Entity: "user", EntityID: user.Username, After: user}
+	})
+}
+
+func (store *SQLStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	return auditedTx(store, ctx, func(q *Queries) (Account, error) {
+		account, err := q.CreateAccount(ctx, arg)
+		if err != nil {
+			return Account{}, err
+		}
+		return account, addOutboxEvent(ctx, q, EventAccountCreated, auditID(account.ID), account)
+	}, func(account Account) AuditRecord {
+		return AuditRecord{Action: AuditActionCreate, Entity: "account", EntityID: auditID(account.ID), After: account}
+	})
+}
+
+func (store *SQLStore) UpdateAccountOverdraft(ctx context.Context, arg UpdateAccountOverdraftParams) (Account, error) {
+	var before Account
+	return auditedTx(store, ctx, func(q *Queries) (Account, error) {
+		var err error
+		before, err = q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return Account{}, err
+		}
+		return q.UpdateAccountOverdraft(ctx, arg)
+	}, func(account Account) AuditRecord {
+		return AuditRecord{Action: AuditActionUpdate, Entity: "account", EntityID: auditID(account.ID), Before: before, After: account}
+	})
+}
+
+func (store *SQLStore) AddAccountMember(ctx context.Context, arg AddAccountMemberParams) (AccountMember, error) {
+	return auditedTx(store, ctx, func(q *Queries) (AccountMember, error) {
+		return q.AddAccountMember(ctx, arg)
+	}, func(member AccountMember) AuditRecord {
+		return AuditRecord{Action: AuditActionCreate, Entity: "account_member", EntityID: member.auditID(), After: member}
+	})
+}
+
+func (store *SQLStore) RemoveAccountMember(ctx context.Context, arg RemoveAccountMemberParams) (AccountMember, error) {
+	return auditedTx(store, ctx, func(q *Queries) (AccountMember, error) {
+		return q.RemoveAccountMember(ctx, arg)
+	}, func(member AccountMember) AuditRecord {
+		return AuditRecord{Action: AuditActionDelete, Entity: "account_member", EntityID: member.auditID(), Before: member}
+	})
+}
+
+func (member AccountMember) auditID() string {
+	return fmt.Sprintf("%d/%s", member.AccountID, member.Username)
+}
+
+func (store *SQLStore) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error) {
+	return auditedTx(store, ctx, func(q *Queries) (OrganizationMember, error) {
+		return q.AddOrganizationMember(ctx, arg)
+	}, func(member OrganizationMember) AuditRecord {
+		return AuditRecord{Action: AuditActionCreate, Entity: "organization_member", EntityID: member.auditID(), After: member}
+	})
+}
+
+func (store *SQLStore) RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (OrganizationMember, error) {
+	return auditedTx(store, ctx, func(q *Queries) (OrganizationMember, error) {
+		return q.RemoveOrganizationMember(ctx, arg)
+	}, func(member OrganizationMember) AuditRecord {
+		return AuditRecord{Action: AuditActionDelete, Entity: "organization_member", EntityID: member.auditID(), Before: member}
+	})
+}
+
+func (member OrganizationMember) auditID() string {
+	return fmt.Sprintf("%d/%s", member.OrganizationID, member.Username)
+}
+
+func (store *SQLStore) SetSpendingPolicy(ctx context.Context, arg SetSpendingPolicyParams) (SpendingPolicy, error) {
+	return auditedTx(store, ctx, func(q *Queries) (SpendingPolicy, error) {
+		return q.SetSpendingPolicy(ctx, arg)
+	}, func(policy SpendingPolicy) AuditRecord {
+		return AuditRecord{Action: AuditActionUpdate, Entity: "spending_policy", EntityID: auditID(policy.ID), After: policy}
+	})
+}
+
+func (store *SQLStore) DeleteSpendingPolicy(ctx context.Context, arg DeleteSpendingPolicyParams) (SpendingPolicy, error) {
+	return auditedTx(store, ctx, func(q *Queries) (SpendingPolicy, error) {
+		return q.DeleteSpendingPolicy(ctx, arg)
+	}, func(policy SpendingPolicy) AuditRecord {
+		return AuditRecord{Action: AuditActionDelete, Entity: "spending_policy", EntityID: auditID(policy.ID), Before: policy}
+	})
+}
+
+func (store *SQLStore) CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error) {
+	// the TransferCreated event waits for the approval which moves the money,
+	// a pending transfer may still be rejected or expire
+	return auditedTx(store, ctx, func(q *Queries) (Transfer, error) {
+		return q.CreatePendingTransfer(ctx, arg)
+	}, func(transfer Transfer) AuditRecord {
+		return AuditRecord{Action: AuditActionCreate, Entity: "transfer", EntityID: auditID(transfer.ID), After: transfer}
+	})
+}
+
+func (store *SQLStore) CreatePocket(ctx context.Context, arg CreatePocketParams) (Pocket, error) {
+	return auditedTx(store, ctx, func(q *Queries) (Pocket, error) {
+		return q.CreatePocket(ctx, arg)
+	}, func(pocket Pocket) AuditRecord {
+		return AuditRecord{Action: AuditActionCreate, Entity: "pocket", EntityID: auditID(pocket.ID), After: pocket}
+	})
+}
+
+func (store *SQLStore) UpdatePocket(ctx context.Context, arg UpdatePocketParams) (Pocket, error) {
+	var before Pocket
+	return auditedTx(store, ctx, func(q *Queries) (Pocket, error) {
+		var err error
+		before, err = q.GetPocketForUpdate(ctx, arg.ID)
+		if err != nil {
+			return Pocket{}, err
+		}
+		return q.UpdatePocket(ctx, arg)
+	}, func(pocket Pocket) AuditRecord {
+		return AuditRecord{Action: AuditActionUpdate, Entity: "pocket", EntityID: auditID(pocket.ID), Before: before, After: pocket}
+	})
+}
+
+func (store *SQLStore) DeleteEmptyPocket(ctx context.Context, id int64) (Pocket, error) {
+	return auditedTx(store, ctx, func(q *Queries) (Pocket, error) {
+		return q.DeleteEmptyPocket(ctx, id)
+	}, func(pocket Pocket) AuditRecord {
+		return AuditRecord{Action: AuditActionDelete, Entity: "pocket", EntityID: auditID(pocket.ID), Before: pocket}
+	})
+}
+
+func (store *SQLStore) CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error) {
+	return auditedTx(store, ctx, func(q *Queries) (WebhookEndpoint, error) {
+		return q.CreateWebhookEndpoint(ctx, arg)
+	}, func(endpoint WebhookEndpoint) AuditRecord {
+		return AuditRecord{Action: AuditActionCreate, Entity: "webhook_endpoint", EntityID: auditID(endpoint.ID), After: endpoint.withoutSecret()}
+	})
+}
+
+func (store *SQLStore) DeleteWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error) {
+	return auditedTx(store, ctx, func(q *Queries) (WebhookEndpoint, error) {
+		return q.DeleteWebhookEndpoint(ctx, id)
+	}, func(endpoint WebhookEndpoint) AuditRecord {
+		return AuditRecord{Action: AuditActionDelete, Entity: "webhook_endpoint", EntityID: auditID(endpoint.ID), Before: endpoint.withoutSecret()}
+	})
+}
+
+// withoutSecret keeps the signing key of the endpoint out of the log
+func (endpoint WebhookEndpoint) withoutSecret() WebhookEndpoint {
+	endpoint.Secret = ""
+	return endpoint
+}
+
+func (store *SQLStore) RedeliverWebhook(ctx context.Context, id int64) (WebhookDelivery, error) {
+	var before WebhookDelivery
+	return auditedTx(store, ctx, func(q *Queries) (WebhookDelivery, error) {
+		var err error
+		before, err = q.GetWebhookDelivery(ctx, id)
+		if err != nil {
+			return WebhookDelivery{}, err
+		}
+		return q.RedeliverWebhook(ctx, id)
+	}, func(delivery WebhookDelivery) AuditRecord {
+		return AuditRecord{Action: AuditActionUpdate, Entity: "webhook_delivery", EntityID: auditID(delivery.ID), Before: before, After: delivery}
+	})
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/novalyezu/simplebank-backend/metrics"
+)
+
+const (
+	BatchModeAllOrNothing = "all_or_nothing"
+	BatchModeBestEffort   = "best_effort"
+)
+
+const (
+	BatchStatusCompleted          = "completed"
+	BatchStatusPartiallyCompleted = "partially_completed"
+	BatchStatusFailed             = "failed"
+)
+
+const (
+	BatchRowStatusSucceeded = "succeeded"
+	BatchRowStatusFailed    = "failed"
+	BatchRowStatusSkipped   = "skipped"
+)
+
+type BatchTransferRow struct {
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Description string `json:"description"`
+	Reference   string `json:"reference"`
+}
+
+type BatchTransferTxParams struct {
+	FromAccountID int64              `json:"from_account_id"`
+	CreatedBy     string             `json:"created_by"`
+	Mode          string             `json:"mode"`
+	Rows          []BatchTransferRow `json:"rows"`
+}
+
+type BatchTransferTxResult struct {
+	Batch TransferBatch      `json:"batch"`
+	Rows  []TransferBatchRow `json:"rows"`
+}
+
+// BatchTransferTx executes the rows as transfers out of one account and records the outcome
+// of every row. In all-or-nothing mode the first failing row rolls back the whole batch,
+// in best-effort mode every row is committed or rejected on its own.
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	if arg.Mode == BatchModeBestEffort {
+		return store.bestEffortBatch(ctx, arg)
+	}
+	return store.allOrNothingBatch(ctx, arg)
+}
+
+func (store *SQLStore) allOrNothingBatch(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	failedRow := -1
+	var failure error
+	var transfers []TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		transfers = transfers[:0]
+		outcomes := make([]CreateTransferBatchRowParams, len(arg.Rows))
+		records := make([]AuditRecord, 0, len(arg.Rows)+1)
+		for i, row := range arg.Rows {
+			transfer, err := transferTx(ctx, q, row.transferTxParams(arg.FromAccountID))
+			if err != nil {
+				failedRow, failure = i, err
+				return err
+			}
+			err = addTransferCreatedEvent(ctx, q, transfer.Transfer)
+			if err != nil {
+				return err
+			}
+			outcomes[i] = row.outcome(i, BatchRowStatusSucceeded, &transfer.Transfer.ID, "")
+			records = append(records, transfer.Transfer.auditRecord())
+			transfers = append(transfers, transfer)
+		}
+
+		var err error
+		result, err = createBatch(ctx, q, arg, outcomes)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, append(records, result.Batch.auditRecord())...)
+	})
+	if failure == nil {
+		if err == nil {
+			for _, transfer := range transfers {
+				metrics.ObserveTransfer(transfer.FromAccount.Currency, transfer.Transfer.Amount)
+			}
+		}
+		return result, err
+	}
+
+	// nothing was committed, record the row which broke the batch
+	outcomes := make([]CreateTransferBatchRowParams, len(arg.Rows))
+	for i, row := range arg.Rows {
+		if i == failedRow {
+			outcomes[i] = row.outcome(i, BatchRowStatusFailed, nil, batchRowError(failure))
+			continue
+		}
+		outcomes[i] = row.outcome(i, BatchRowStatusSkipped, nil, "")
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = createBatch(ctx, q, arg, outcomes)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, result.Batch.auditRecord())
+	})
+
+	return result, err
+}
+
+func (store *SQLStore) bestEffortBatch(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	outcomes := make([]CreateTransferBatchRowParams, len(arg.Rows))
+	for i, row := range arg.Rows {
+		transfer, err := store.TransferTx(ctx, row.transferTxParams(arg.FromAccountID))
+		if err != nil {
+			outcomes[i] = row.outcome(i, BatchRowStatusFailed, nil, batchRowError(err))
+			continue
+		}
+		outcomes[i] = row.outcome(i, BatchRowStatusSucceeded, &transfer.Transfer.ID, "")
+	}
+
+	// the transfers are audited by TransferTx
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = createBatch(ctx, q, arg, outcomes)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, result.Batch.auditRecord())
+	})
+
+	return result, err
+}
+
+func createBatch(ctx context.Context, q *Queries, arg BatchTransferTxParams, outcomes []CreateTransferBatchRowParams) (BatchTransferTxResult, error) {
+	var succeeded int32
+	for _, outcome := range outcomes {
+		if outcome.Status == BatchRowStatusSucceeded {
+			succeeded++
+		}
+	}
+
+	status := BatchStatusPartiallyCompleted
+	switch succeeded {
+	case int32(len(outcomes)):
+		status = BatchStatusCompleted
+	case 0:
+		status = BatchStatusFailed
+	}
+
+	batch, err := q.CreateTransferBatch(ctx, CreateTransferBatchParams{
+		FromAccountID: arg.FromAccountID,
+		CreatedBy:     arg.CreatedBy,
+		Mode:          arg.Mode,
+		Status:        status,
+		TotalRows:     int32(len(outcomes)),
+		SucceededRows: succeeded,
+	})
+	if err != nil {
+		return BatchTransferTxResult{}, err
+	}
+
+	result := BatchTransferTxResult{
+		Batch: batch,
+		Rows:  make([]TransferBatchRow, 0, len(outcomes)),
+	}
+	for _, outcome := range outcomes {
+		outcome.BatchID = batch.ID
+		row, err := q.CreateTransferBatchRow(ctx, outcome)
+		if err != nil {
+			return BatchTransferTxResult{}, err
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+func (batch TransferBatch) auditRecord() AuditRecord {
+	return AuditRecord{Action: AuditActionCreate, Entity: "transfer_batch", EntityID: auditID(batch.ID), After: batch}
+}
+
+func (row BatchTransferRow) transferTxParams(fromAccountID int64) TransferTxParams {
+	return TransferTxParams{
+		FromAccountID: fromAccountID,
+		ToAccountID:   row.ToAccountID,
+		Amount:        row.Amount,
+		Description:   row.Description,
+		Reference:     row.Reference,
+	}
+}
+
+// outcome describes the row at index i, row numbers start at 1
+func (row BatchTransferRow) outcome(i int, status string, transferID *int64, message string) CreateTransferBatchRowParams {
+	return CreateTransferBatchRowParams{
+		RowNumber:   int32(i + 1),
+		ToAccountID: row.ToAccountID,
+		Amount:      row.Amount,
+		Description: row.Description,
+		Reference:   row.Reference,
+		Status:      status,
+		TransferID:  transferID,
+		Error:       message,
+	}
+}
+
+// batchRowError keeps database details out of the report
+func batchRowError(err error) string {
+	if errors.Is(err, ErrInsufficientFunds) {
+		return err.Error()
+	}
+	return "transfer failed"
+}
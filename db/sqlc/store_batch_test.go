@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const batchTestPrefix = "batch_test_"
+
+func TestBatchTransferTxAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	fromAccount := fundTestingAccount(t, createRandomAccount(t, batchTestPrefix), 300)
+	toAccount1 := createRandomAccount(t, batchTestPrefix)
+	toAccount2 := createRandomAccount(t, batchTestPrefix)
+
+	defer deleteTestingAccount(ctx, batchTestPrefix)
+	defer deleteTestingTransfer(ctx, fromAccount.ID, toAccount2.ID)
+	defer store.DeleteTransferTx(ctx, DeleteTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount1.ID,
+	})
+	defer store.DeleteEntryByAccountID(ctx, toAccount2.ID)
+	defer store.DeleteTransferBatchesByAccountID(ctx, fromAccount.ID)
+
+	arg := BatchTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		CreatedBy:     fromAccount.Owner,
+		Mode:          BatchModeAllOrNothing,
+		Rows: []BatchTransferRow{
+			{ToAccountID: toAccount1.ID, Amount: 100, Description: "salary"},
+			{ToAccountID: toAccount2.ID, Amount: 150, Description: "salary"},
+		},
+	}
+
+	result, err := store.BatchTransferTx(ctx, arg)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchStatusCompleted, result.Batch.Status)
+	assert.Equal(t, int32(2), result.Batch.SucceededRows)
+	assert.Len(t, result.Rows, 2)
+	for i, row := range result.Rows {
+		assert.Equal(t, int32(i+1), row.RowNumber)
+		assert.Equal(t, BatchRowStatusSucceeded, row.Status)
+		assert.NotNil(t, row.TransferID)
+	}
+
+	// 50 left, the second row can't be paid so nothing is
+	arg.Rows = []BatchTransferRow{
+		{ToAccountID: toAccount1.ID, Amount: 10},
+		{ToAccountID: toAccount2.ID, Amount: 100},
+	}
+	result, err = store.BatchTransferTx(ctx, arg)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchStatusFailed, result.Batch.Status)
+	assert.Equal(t, int32(0), result.Batch.SucceededRows)
+	assert.Equal(t, BatchRowStatusSkipped, result.Rows[0].Status)
+	assert.Nil(t, result.Rows[0].TransferID)
+	assert.Equal(t, BatchRowStatusFailed, result.Rows[1].Status)
+	assert.Equal(t, ErrInsufficientFunds.Error(), result.Rows[1].Error)
+
+	account, err := store.GetAccount(ctx, fromAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), account.Balance)
+
+	batch, err := store.GetTransferBatch(ctx, result.Batch.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Batch, batch)
+
+	rows, err := store.ListTransferBatchRows(ctx, batch.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Rows, rows)
+}
+
+func TestBatchTransferTxBestEffort(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	fromAccount := fundTestingAccount(t, createRandomAccount(t, batchTestPrefix), 100)
+	toAccount := createRandomAccount(t, batchTestPrefix)
+
+	defer deleteTestingAccount(ctx, batchTestPrefix)
+	defer store.DeleteTransferTx(ctx, DeleteTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+	})
+	defer store.DeleteTransferBatchesByAccountID(ctx, fromAccount.ID)
+
+	result, err := store.BatchTransferTx(ctx, BatchTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		CreatedBy:     fromAccount.Owner,
+		Mode:          BatchModeBestEffort,
+		Rows: []BatchTransferRow{
+			{ToAccountID: toAccount.ID, Amount: 60},
+			{ToAccountID: toAccount.ID, Amount: 60},
+			{ToAccountID: toAccount.ID, Amount: 40},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, BatchStatusPartiallyCompleted, result.Batch.Status)
+	assert.Equal(t, int32(3), result.Batch.TotalRows)
+	assert.Equal(t, int32(2), result.Batch.SucceededRows)
+	assert.Equal(t, BatchRowStatusSucceeded, result.Rows[0].Status)
+	assert.Equal(t, BatchRowStatusFailed, result.Rows[1].Status)
+	assert.Equal(t, BatchRowStatusSucceeded, result.Rows[2].Status)
+
+	account, err := store.GetAccount(ctx, fromAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), account.Balance)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+// FeeRevenueOwner owns one fee revenue account per currency, see migration 000004
+const FeeRevenueOwner = "system_fee"
+
+const feeEntryDescription = "transfer fee"
+
+type CalculateFeeParams struct {
+	Currency    string `json:"currency"`
+	AccountType string `json:"account_type"`
+	Amount      int64  `json:"amount"`
+}
+
+// Calculate returns the fee charged by the rule for amount.
+func (rule FeeRule) Calculate(amount int64) int64 {
+	return util.CalculateFee(amount, rule.FlatFee, rule.BasisPoints)
+}
+
+// CalculateFee evaluates the fee schedule, it is zero when no rule matches.
+func (store *SQLStore) CalculateFee(ctx context.Context, arg CalculateFeeParams) (int64, error) {
+	return calculateFee(ctx, store.Queries, arg)
+}
+
+func calculateFee(ctx context.Context, q *Queries, arg CalculateFeeParams) (int64, error) {
+	rule, err := q.GetMatchingFeeRule(ctx, GetMatchingFeeRuleParams(arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return rule.Calculate(arg.Amount), nil
+}
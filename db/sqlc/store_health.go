@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+)
+
+// SchemaVersion is the state of the migrations, golang-migrate keeps it in schema_migrations.
+// A dirty version is a migration which failed halfway.
+type SchemaVersion struct {
+	Version int64 `json:"version"`
+	Dirty   bool  `json:"dirty"`
+}
+
+// Ping checks the database can be reached
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// GetSchemaVersion reads the version golang-migrate applied, sqlc doesn't know the table
+// because it isn't created by the migrations
+func (store *SQLStore) GetSchemaVersion(ctx context.Context) (SchemaVersion, error) {
+	var version SchemaVersion
+	err := store.Queries.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").
+		Scan(&version.Version, &version.Dirty)
+	return version, err
+}
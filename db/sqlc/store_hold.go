@@ -0,0 +1,275 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/metrics"
+)
+
+const (
+	HoldStatusPending  = "pending"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrAmountOverflow    = errors.New("amount plus fee is too large")
+	ErrHoldNotPending    = errors.New("hold is not pending")
+	ErrHoldExpired       = errors.New("hold is expired")
+)
+
+type PlaceHoldParams struct {
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PlaceHoldResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+// PlaceHold reserves amount on the account without moving it,
+// only the available balance is reduced.
+func (store *SQLStore) PlaceHold(ctx context.Context, arg PlaceHoldParams) (PlaceHoldResult, error) {
+	var result PlaceHoldResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Account, err = q.ReserveAccountBalance(ctx, ReserveAccountBalanceParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return reserveError(ctx, q, arg.AccountID)
+			}
+			return err
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams(arg))
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, AuditRecord{
+			Action:   AuditActionCreate,
+			Entity:   "hold",
+			EntityID: auditID(result.Hold.ID),
+			After:    result.Hold,
+		})
+	})
+
+	return result, err
+}
+
+// reserveError tells apart a missing account from an account
+// that doesn't have enough available balance
+func reserveError(ctx context.Context, q *Queries, accountID int64) error {
+	_, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	return ErrInsufficientFunds
+}
+
+type CaptureHoldParams struct {
+	HoldID      int64 `json:"hold_id"`
+	ToAccountID int64 `json:"to_account_id"`
+}
+
+type CaptureHoldResult struct {
+	Hold Hold `json:"hold"`
+	TransferTxResult
+}
+
+// CaptureHold moves the held amount to another account as a transfer.
+func (store *SQLStore) CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error) {
+	var result CaptureHoldResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := lockPendingHold(ctx, q, arg.HoldID)
+		if err != nil {
+			return err
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		description := fmt.Sprintf("capture of hold %d", hold.ID)
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        hold.Amount,
+			Description:   description,
+			Metadata:      metadataOrEmpty(nil),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:   hold.AccountID,
+			Amount:      -hold.Amount,
+			TransferID:  &result.Transfer.ID,
+			Description: description,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:   arg.ToAccountID,
+			Amount:      hold.Amount,
+			TransferID:  &result.Transfer.ID,
+			Description: description,
+		})
+		if err != nil {
+			return err
+		}
+
+		// the held amount is already gone from the available balance of the
+		// source account, so only its ledger balance is debited here.
+		// updates are still ordered by ID to avoid deadlock, see addBalances
+		debit := func() error {
+			result.FromAccount, err = q.AddAccountLedgerBalance(ctx, AddAccountLedgerBalanceParams{
+				ID:     hold.AccountID,
+				Amount: -hold.Amount,
+			})
+			return err
+		}
+		credit := func() error {
+			result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				ID:     arg.ToAccountID,
+				Amount: hold.Amount,
+			})
+			return err
+		}
+
+		first, second := debit, credit
+		if arg.ToAccountID < hold.AccountID {
+			first, second = credit, debit
+		}
+		if err = first(); err != nil {
+			return err
+		}
+		if err = second(); err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:         hold.ID,
+			Status:     HoldStatusCaptured,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		err = addTransferCreatedEvent(ctx, q, result.Transfer)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, result.Transfer.auditRecord(), hold.auditRecord(AuditActionCapture, result.Hold))
+	})
+
+	if err == nil {
+		metrics.ObserveTransfer(result.FromAccount.Currency, result.Transfer.Amount)
+	}
+	return result, err
+}
+
+type ReleaseHoldResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+// ReleaseHold gives the held amount back to the available balance.
+func (store *SQLStore) ReleaseHold(ctx context.Context, holdID int64) (ReleaseHoldResult, error) {
+	var result ReleaseHoldResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := lockPendingHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, result.Account, err = releaseHold(ctx, q, hold, HoldStatusReleased)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, hold.auditRecord(AuditActionRelease, result.Hold))
+	})
+
+	return result, err
+}
+
+// ExpireHolds releases up to limit pending holds which are past their expiry time
+// and returns them.
+func (store *SQLStore) ExpireHolds(ctx context.Context, limit int32) ([]Hold, error) {
+	var expired []Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		holds, err := q.ListExpiredHolds(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		// release by account ID, same lock order as TransferTx
+		sort.SliceStable(holds, func(i, j int) bool {
+			return holds[i].AccountID < holds[j].AccountID
+		})
+
+		records := make([]AuditRecord, 0, len(holds))
+		for _, hold := range holds {
+			updated, _, err := releaseHold(ctx, q, hold, HoldStatusExpired)
+			if err != nil {
+				return err
+			}
+			expired = append(expired, updated)
+			records = append(records, hold.auditRecord(AuditActionExpire, updated))
+		}
+		return recordAudit(ctx, q, records...)
+	})
+
+	return expired, err
+}
+
+// auditRecord describes the change of the hold into updated
+func (hold Hold) auditRecord(action string, updated Hold) AuditRecord {
+	return AuditRecord{Action: action, Entity: "hold", EntityID: auditID(hold.ID), Before: hold, After: updated}
+}
+
+func lockPendingHold(ctx context.Context, q *Queries, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return Hold{}, err
+	}
+	if hold.Status != HoldStatusPending {
+		return Hold{}, ErrHoldNotPending
+	}
+	return hold, nil
+}
+
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (Hold, Account, error) {
+	account, err := q.ReleaseAccountBalance(ctx, ReleaseAccountBalanceParams{
+		ID:     hold.AccountID,
+		Amount: hold.Amount,
+	})
+	if err != nil {
+		return Hold{}, Account{}, err
+	}
+
+	hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	return hold, account, err
+}
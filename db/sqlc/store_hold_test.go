@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const storeHoldPrefix = "store_hold_test_"
+
+func TestPlaceAndReleaseHold(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account := fundTestingAccount(t, createRandomAccount(t, storeHoldPrefix), 100)
+
+	defer deleteTestingAccount(ctx, storeHoldPrefix)
+	defer deleteTestingHold(ctx, account.ID)
+
+	amount := account.AvailableBalance
+
+	placed, err := store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID: account.ID,
+		Amount:    amount,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, HoldStatusPending, placed.Hold.Status)
+	assert.Equal(t, account.Balance, placed.Account.Balance)
+	assert.Equal(t, int64(0), placed.Account.AvailableBalance)
+
+	// nothing left to reserve
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID: account.ID,
+		Amount:    1,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	released, err := store.ReleaseHold(ctx, placed.Hold.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, HoldStatusReleased, released.Hold.Status)
+	assert.Equal(t, account.Balance, released.Account.Balance)
+	assert.Equal(t, account.AvailableBalance, released.Account.AvailableBalance)
+
+	_, err = store.ReleaseHold(ctx, placed.Hold.ID)
+	assert.ErrorIs(t, err, ErrHoldNotPending)
+}
+
+func TestCaptureHold(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account1 := fundTestingAccount(t, createRandomAccount(t, storeHoldPrefix), 100)
+	account2 := createRandomAccount(t, storeHoldPrefix)
+
+	defer deleteTestingAccount(ctx, storeHoldPrefix)
+	defer store.DeleteTransferTx(ctx, DeleteTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+	})
+	defer deleteTestingHold(ctx, account1.ID)
+
+	amount := int64(1)
+
+	placed, err := store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID: account1.ID,
+		Amount:    amount,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+
+	transfers := metrics.Transfers.WithLabelValues(account1.Currency)
+	before := testutil.ToFloat64(transfers)
+
+	result, err := store.CaptureHold(ctx, CaptureHoldParams{
+		HoldID:      placed.Hold.ID,
+		ToAccountID: account2.ID,
+	})
+	assert.NoError(t, err)
+	// the capture is a completed transfer in the business metrics
+	assert.Equal(t, before+1, testutil.ToFloat64(transfers))
+
+	assert.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	assert.True(t, result.Hold.TransferID.Valid)
+	assert.Equal(t, result.Transfer.ID, result.Hold.TransferID.Int64)
+	assert.Equal(t, amount, result.Transfer.Amount)
+	assert.Equal(t, -amount, result.FromEntry.Amount)
+	assert.Equal(t, amount, result.ToEntry.Amount)
+
+	assert.Equal(t, account1.Balance-amount, result.FromAccount.Balance)
+	assert.Equal(t, account1.AvailableBalance-amount, result.FromAccount.AvailableBalance)
+	assert.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+	assert.Equal(t, account2.AvailableBalance+amount, result.ToAccount.AvailableBalance)
+}
+
+func TestExpireHolds(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account := fundTestingAccount(t, createRandomAccount(t, storeHoldPrefix), 100)
+
+	defer deleteTestingAccount(ctx, storeHoldPrefix)
+	defer deleteTestingHold(ctx, account.ID)
+
+	placed, err := store.PlaceHold(ctx, PlaceHoldParams{
+		AccountID: account.ID,
+		Amount:    1,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	assert.NoError(t, err)
+
+	_, err = store.CaptureHold(ctx, CaptureHoldParams{HoldID: placed.Hold.ID, ToAccountID: account.ID})
+	assert.ErrorIs(t, err, ErrHoldExpired)
+
+	holds, err := store.ExpireHolds(ctx, 100)
+	assert.NoError(t, err)
+
+	var found bool
+	for _, hold := range holds {
+		if hold.ID == placed.Hold.ID {
+			found = true
+			assert.Equal(t, HoldStatusExpired, hold.Status)
+		}
+	}
+	assert.True(t, found)
+
+	updated, err := store.GetAccount(ctx, account.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account.AvailableBalance, updated.AvailableBalance)
+}
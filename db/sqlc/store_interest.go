@@ -0,0 +1,247 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+// InterestExpenseOwner owns one account per currency interest is paid from
+// and overdraft interest is paid to, see migration 000005
+const InterestExpenseOwner = "system_interest"
+
+const (
+	AccrualKindInterest  = "interest"
+	AccrualKindOverdraft = "overdraft"
+)
+
+const interestEntryDescription = "interest"
+
+// AccrueInterest computes one day of interest for every savings account and one day
+// of overdraft interest for every overdrawn account not accrued on that day yet,
+// it is safe to run more than once for the same day.
+// Missed days must be accrued from the oldest one so the remainders carry over in order.
+func (store *SQLStore) AccrueInterest(ctx context.Context, date time.Time) ([]InterestAccrual, error) {
+	var accruals []InterestAccrual
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	dayEnd := day.AddDate(0, 0, 1)
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var records []AuditRecord
+
+		savings, err := q.ListInterestBearingAccounts(ctx, ListInterestBearingAccountsParams{
+			DayEnd:      dayEnd,
+			AccrualDate: day,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, account := range savings {
+			// an empty or overdrawn savings account earns nothing but keeps its carry
+			balance := account.Balance
+			if balance < 0 {
+				balance = 0
+			}
+
+			accrual, err := accrueDay(ctx, q, accrueDayParams{
+				AccountID:     account.ID,
+				Kind:          AccrualKindInterest,
+				Day:           day,
+				Balance:       account.Balance,
+				Principal:     balance,
+				AnnualRateBps: account.AnnualRateBps,
+			})
+			if err != nil {
+				return err
+			}
+			if accrual.ID != 0 {
+				accruals = append(accruals, accrual)
+				records = append(records, accrual.auditRecord())
+			}
+		}
+
+		overdrawn, err := q.ListOverdraftChargeableAccounts(ctx, ListOverdraftChargeableAccountsParams{
+			DayEnd:      dayEnd,
+			AccrualDate: day,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, account := range overdrawn {
+			accrual, err := accrueDay(ctx, q, accrueDayParams{
+				AccountID:     account.ID,
+				Kind:          AccrualKindOverdraft,
+				Day:           day,
+				Balance:       account.Balance,
+				Principal:     -account.Balance,
+				AnnualRateBps: account.OverdraftRateBps,
+				Charge:        true,
+			})
+			if err != nil {
+				return err
+			}
+			if accrual.ID != 0 {
+				accruals = append(accruals, accrual)
+				records = append(records, accrual.auditRecord())
+			}
+		}
+		return recordAudit(ctx, q, records...)
+	})
+
+	return accruals, err
+}
+
+func (accrual InterestAccrual) auditRecord() AuditRecord {
+	return AuditRecord{Action: AuditActionAccrue, Entity: "interest_accrual", EntityID: auditID(accrual.ID), After: accrual}
+}
+
+type accrueDayParams struct {
+	AccountID     int64
+	Kind          string
+	Day           time.Time
+	Balance       int64
+	Principal     int64
+	AnnualRateBps int64
+	// Charge accrues a negative amount, paid by the account instead of to it
+	Charge bool
+}
+
+// accrueDay returns an empty accrual when the day was accrued concurrently by another run
+func accrueDay(ctx context.Context, q *Queries, arg accrueDayParams) (InterestAccrual, error) {
+	var carry int64
+	last, err := q.GetLastInterestAccrual(ctx, GetLastInterestAccrualParams{
+		AccountID:   arg.AccountID,
+		Kind:        arg.Kind,
+		AccrualDate: arg.Day,
+	})
+	if err == nil {
+		carry = last.Remainder
+	} else if err != sql.ErrNoRows {
+		return InterestAccrual{}, err
+	}
+
+	amount, remainder := util.DailyInterest(arg.Principal, arg.AnnualRateBps, carry)
+	if arg.Charge {
+		amount = -amount
+	}
+
+	accrual, err := q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+		AccountID:     arg.AccountID,
+		Kind:          arg.Kind,
+		AccrualDate:   arg.Day,
+		Balance:       arg.Balance,
+		AnnualRateBps: arg.AnnualRateBps,
+		Amount:        amount,
+		Remainder:     remainder,
+	})
+	if err == sql.ErrNoRows {
+		return InterestAccrual{}, nil
+	}
+	return accrual, err
+}
+
+// PostInterest pays out the interest accrued before the given day as journal entries,
+// one transaction per account, savings interest and overdraft interest are netted.
+// It returns the entries made on the accounts.
+func (store *SQLStore) PostInterest(ctx context.Context, before time.Time) ([]Entry, error) {
+	accountIDs, err := store.ListAccountsWithUnpostedInterest(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, accountID := range accountIDs {
+		entry, err := store.postAccountInterest(ctx, accountID, before)
+		if err != nil {
+			return entries, err
+		}
+		if entry.ID != 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (store *SQLStore) postAccountInterest(ctx context.Context, accountID int64, before time.Time) (Entry, error) {
+	var entry Entry
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		accruals, err := q.ListUnpostedInterestAccrualsForUpdate(ctx, ListUnpostedInterestAccrualsForUpdateParams{
+			AccountID:   accountID,
+			AccrualDate: before,
+		})
+		if err != nil {
+			return err
+		}
+
+		var total int64
+		for _, accrual := range accruals {
+			total += accrual.Amount
+		}
+
+		if total != 0 {
+			account, err := q.GetAccount(ctx, accountID)
+			if err != nil {
+				return err
+			}
+
+			expenseAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+				Owner:       InterestExpenseOwner,
+				Currency:    account.Currency,
+				AccountType: util.CheckingAccount,
+			})
+			if err != nil {
+				return err
+			}
+
+			entry, err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID:   accountID,
+				Amount:      total,
+				Description: interestEntryDescription,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID:   expenseAccount.ID,
+				Amount:      -total,
+				Description: interestEntryDescription,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = addBalances(ctx, q, map[int64]int64{
+				accountID:         total,
+				expenseAccount.ID: -total,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = q.MarkInterestAccrualsPosted(ctx, MarkInterestAccrualsPostedParams{
+			AccountID:   accountID,
+			EntryID:     sql.NullInt64{Int64: entry.ID, Valid: entry.ID != 0},
+			AccrualDate: before,
+		})
+		if err != nil || entry.ID == 0 {
+			return err
+		}
+		return recordAudit(ctx, q, AuditRecord{
+			Action:   AuditActionPost,
+			Entity:   "entry",
+			EntityID: auditID(entry.ID),
+			After:    entry,
+		})
+	})
+
+	return entry, err
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const storeInterestPrefix = "store_interest_test_"
+
+func createRandomSavingsAccount(t *testing.T, prefix string, balance int64) Account {
+	user := createRandomUser(t, prefix)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:       user.Username,
+		Balance:     balance,
+		Currency:    util.RandomCurrency(),
+		AccountType: util.SavingsAccount,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, util.SavingsAccount, account.AccountType)
+	return account
+}
+
+func TestAccrueAndPostInterest(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+
+	account := createRandomSavingsAccount(t, storeInterestPrefix, 1_000_000)
+	rate, err := testQueries.CreateInterestRate(ctx, CreateInterestRateParams{
+		AccountType:   util.SavingsAccount,
+		Currency:      account.Currency,
+		AnnualRateBps: 365,
+		EffectiveFrom: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	defer deleteTestingUser(ctx, storeInterestPrefix)
+	defer deleteTestingAccount(ctx, storeInterestPrefix)
+	defer testQueries.DeleteEntryByAccountID(ctx, account.ID)
+	defer testQueries.DeleteInterestAccrualsByAccountID(ctx, account.ID)
+	defer testQueries.DeleteInterestRate(ctx, rate.ID)
+
+	// accruals only start once the account exists
+	today := time.Now().UTC()
+	day1 := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	for _, day := range []time.Time{day1, day2, day2} {
+		_, err := store.AccrueInterest(ctx, day)
+		assert.NoError(t, err)
+	}
+
+	accruals, err := testQueries.ListInterestAccruals(ctx, ListInterestAccrualsParams{
+		AccountID: account.ID,
+		Limit:     10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, accruals, 2)
+	for _, accrual := range accruals {
+		assert.Equal(t, int64(100), accrual.Amount)
+		assert.Equal(t, int64(0), accrual.Remainder)
+		assert.False(t, accrual.PostedAt.Valid)
+	}
+
+	entries, err := store.PostInterest(ctx, day2.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+
+	var posted Entry
+	for _, entry := range entries {
+		if entry.AccountID == account.ID {
+			posted = entry
+		}
+	}
+	assert.Equal(t, int64(200), posted.Amount)
+
+	updated, err := store.GetAccount(ctx, account.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Balance+200, updated.Balance)
+	assert.Equal(t, account.AvailableBalance+200, updated.AvailableBalance)
+
+	expenseAccount, err := testQueries.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:       InterestExpenseOwner,
+		Currency:    account.Currency,
+		AccountType: util.CheckingAccount,
+	})
+	assert.NoError(t, err)
+	defer testQueries.AddAccountBalance(ctx, AddAccountBalanceParams{ID: expenseAccount.ID, Amount: 200})
+	defer testQueries.DeleteEntryByAccountID(ctx, expenseAccount.ID)
+
+	// nothing left to post
+	entries, err = store.PostInterest(ctx, day2.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotEqual(t, account.ID, entry.AccountID)
+	}
+}
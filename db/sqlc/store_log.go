@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SlowQueryThreshold is how long a query may take before the store logs it
+const SlowQueryThreshold = 200 * time.Millisecond
+
+// LoggerFrom adds the request ID and the trace ID of the context to logger, so the logs
+// of the store can be matched with the request and the trace which caused them
+func LoggerFrom(ctx context.Context, logger *slog.Logger) *slog.Logger {
+	if info := AuditInfoFrom(ctx); info.RequestID != "" {
+		logger = logger.With(slog.String("request_id", info.RequestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		logger = logger.With(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	return logger
+}
+
+// loggedDB logs the queries which take longer than SlowQueryThreshold. The time of
+// QueryContext and QueryRowContext is the time until the first row is available.
+type loggedDB struct {
+	DBTX
+	logger *slog.Logger
+}
+
+func (db loggedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer db.logSlowQuery(ctx, query, time.Now())
+	return db.DBTX.ExecContext(ctx, query, args...)
+}
+
+func (db loggedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer db.logSlowQuery(ctx, query, time.Now())
+	return db.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (db loggedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer db.logSlowQuery(ctx, query, time.Now())
+	return db.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func (db loggedDB) logSlowQuery(ctx context.Context, query string, start time.Time) {
+	latency := time.Since(start)
+	if latency < SlowQueryThreshold {
+		return
+	}
+	LoggerFrom(ctx, db.logger).WarnContext(ctx, "slow query",
+		slog.String("query", queryName(query)),
+		slog.Duration("latency", latency),
+	)
+}
+
+// queryName returns the name sqlc puts into the comment at the start of its queries,
+// the whole query for the others
+func queryName(query string) string {
+	var name, kind string
+	if _, err := fmt.Sscanf(query, "-- name: %s %s", &name, &kind); err == nil {
+		return name
+	}
+	return query
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+// CreateOrganizationTx creates the organization with its creator as the first admin.
+func (store *SQLStore) CreateOrganizationTx(ctx context.Context, arg CreateOrganizationParams) (Organization, error) {
+	var organization Organization
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		organization, err = q.CreateOrganization(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		member, err := q.AddOrganizationMember(ctx, AddOrganizationMemberParams{
+			OrganizationID: organization.ID,
+			Username:       arg.CreatedBy,
+			Role:           util.OrganizationAdminRole,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q,
+			AuditRecord{Action: AuditActionCreate, Entity: "organization", EntityID: auditID(organization.ID), After: organization},
+			AuditRecord{Action: AuditActionCreate, Entity: "organization_member", EntityID: member.auditID(), After: member},
+		)
+	})
+
+	return organization, err
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+const (
+	EventTransferCreated = "TransferCreated"
+	EventAccountCreated  = "AccountCreated"
+	EventUserCreated     = "UserCreated"
+)
+
+// EventSchemaVersion is bumped with every change of the payloads
+// which isn't backward compatible for consumers
+const EventSchemaVersion = 1
+
+// UserCreatedPayload is the payload of EventUserCreated, the password hash never leaves the database
+type UserCreatedPayload struct {
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// addOutboxEvent writes the event within the transaction of q,
+// it is only published when the transaction commits
+func addOutboxEvent(ctx context.Context, q *Queries, eventType string, aggregateID string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType:     eventType,
+		SchemaVersion: EventSchemaVersion,
+		AggregateID:   aggregateID,
+		Payload:       data,
+	})
+	return err
+}
+
+// addTransferCreatedEvent writes the outbox event of a new transfer
+// and queues the webhooks of both of its accounts
+func addTransferCreatedEvent(ctx context.Context, q *Queries, transfer Transfer) error {
+	err := addOutboxEvent(ctx, q, EventTransferCreated, auditID(transfer.ID), transfer)
+	if err != nil {
+		return err
+	}
+	return addTransferWebhooks(ctx, q, transfer)
+}
+
+// outboxClaimLease is how long claimed events are skipped by other relays,
+// it covers publishing a whole batch to a slow sink
+const outboxClaimLease = 5 * time.Minute
+
+// RelayOutbox hands up to limit due outbox events to publish in the order they were written
+// and returns how many it handled. A published event is marked as such, a failed one is retried
+// later with a backoff. The events are claimed for outboxClaimLease and published outside of a
+// transaction, a crash before they are marked publishes them again once the lease ends.
+func (store *SQLStore) RelayOutbox(ctx context.Context, limit int32, publish func(OutboxEvent) error) (int, error) {
+	events, err := store.ClaimDueOutboxEvents(ctx, ClaimDueOutboxEventsParams{
+		LeaseSeconds: int32(outboxClaimLease / time.Second),
+		RowLimit:     limit,
+	})
+	if err != nil {
+		return 0, err
+	}
+	slices.SortFunc(events, func(a, b OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	publishErrs := make([]error, len(events))
+	for i, event := range events {
+		publishErrs[i] = publish(event)
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		for i, event := range events {
+			var err error
+			if publishErrs[i] != nil {
+				err = q.MarkOutboxEventFailed(ctx, MarkOutboxEventFailedParams{
+					ID:        event.ID,
+					LastError: publishErrs[i].Error(),
+				})
+			} else {
+				err = q.MarkOutboxEventPublished(ctx, event.ID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const storeOutboxPrefix = "store_outbox_test_"
+
+func TestRelayOutbox(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	user := createRandomUser(t, storeOutboxPrefix)
+	defer deleteTestingAccount(ctx, storeOutboxPrefix)
+
+	account, err := store.CreateAccount(ctx, CreateAccountParams{
+		Owner:       user.Username,
+		Currency:    util.IDR,
+		AccountType: util.CheckingAccount,
+	})
+	assert.NoError(t, err)
+
+	// the outbox may hold events of other tests, only the one of the account fails
+	var published *OutboxEvent
+	for {
+		handled, err := store.RelayOutbox(ctx, 100, func(event OutboxEvent) error {
+			if event.EventType == EventAccountCreated && event.AggregateID == strconv.FormatInt(account.ID, 10) {
+				published = &event
+				return errors.New("sink is down")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		if handled < 100 {
+			break
+		}
+	}
+	assert.NotNil(t, published)
+	assert.Equal(t, int32(EventSchemaVersion), published.SchemaVersion)
+
+	failed, err := testQueries.GetOutboxEvent(ctx, published.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, failed.PublishedAt)
+	assert.Equal(t, int32(1), failed.Attempts)
+	assert.Equal(t, "sink is down", failed.LastError)
+	assert.True(t, failed.NextAttemptAt.After(time.Now()))
+
+	// it isn't due yet
+	_, err = store.RelayOutbox(ctx, 100, func(event OutboxEvent) error {
+		assert.NotEqual(t, published.ID, event.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestCreateUserWritesOutboxEvent(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	assert.NoError(t, err)
+
+	user, err := store.CreateUser(ctx, CreateUserParams{
+		Username:       storeOutboxPrefix + util.RandomString(6),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandomString(6),
+		Email:          util.RandomEmail(6),
+	})
+	assert.NoError(t, err)
+
+	var found bool
+	for {
+		handled, err := store.RelayOutbox(ctx, 100, func(event OutboxEvent) error {
+			if event.EventType == EventUserCreated && event.AggregateID == user.Username {
+				found = true
+				assert.NotContains(t, string(event.Payload), "hashed_password")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		if handled < 100 {
+			break
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestRelayOutboxClaimsEvents(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	user := createRandomUser(t, storeOutboxPrefix)
+	defer deleteTestingAccount(ctx, storeOutboxPrefix)
+
+	account, err := store.CreateAccount(ctx, CreateAccountParams{
+		Owner:       user.Username,
+		Currency:    util.IDR,
+		AccountType: util.CheckingAccount,
+	})
+	assert.NoError(t, err)
+	aggregateID := strconv.FormatInt(account.ID, 10)
+
+	// the events are published outside of a transaction, a relay running meanwhile
+	// skips the claimed events
+	var published bool
+	for {
+		handled, err := store.RelayOutbox(ctx, 100, func(event OutboxEvent) error {
+			if event.EventType != EventAccountCreated || event.AggregateID != aggregateID {
+				return nil
+			}
+			published = true
+
+			_, err := store.RelayOutbox(ctx, 100, func(other OutboxEvent) error {
+				assert.NotEqual(t, event.ID, other.ID)
+				return nil
+			})
+			assert.NoError(t, err)
+			return nil
+		})
+		assert.NoError(t, err)
+		if handled < 100 {
+			break
+		}
+	}
+	assert.True(t, published)
+}
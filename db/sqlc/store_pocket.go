@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type MovePocketTxParams struct {
+	PocketID int64 `json:"pocket_id"`
+	// Amount moves from the account into the pocket, a negative amount moves it back
+	Amount int64 `json:"amount"`
+}
+
+type MovePocketTxResult struct {
+	Pocket  Pocket  `json:"pocket"`
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// MovePocketTx moves money between an account and one of its pockets. The money never
+// leaves the owner so unlike TransferTx there is no fee and only the account gets an entry.
+// Moving into a pocket doesn't dip into the overdraft.
+func (store *SQLStore) MovePocketTx(ctx context.Context, arg MovePocketTxParams) (MovePocketTxResult, error) {
+	var result MovePocketTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pocket, err := q.GetPocket(ctx, arg.PocketID)
+		if err != nil {
+			return err
+		}
+
+		// the account is locked before the pocket, moves never lock two accounts
+		description := fmt.Sprintf("move to pocket %s", pocket.Name)
+		action := AuditActionDeposit
+		if arg.Amount > 0 {
+			result.Account, err = q.WithdrawAccountBalance(ctx, WithdrawAccountBalanceParams{
+				ID:     pocket.AccountID,
+				Amount: arg.Amount,
+			})
+		} else {
+			description = fmt.Sprintf("move from pocket %s", pocket.Name)
+			action = AuditActionWithdraw
+			result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				ID:     pocket.AccountID,
+				Amount: -arg.Amount,
+			})
+		}
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInsufficientFunds
+			}
+			return err
+		}
+
+		result.Pocket, err = q.AddPocketBalance(ctx, AddPocketBalanceParams{
+			ID:     pocket.ID,
+			Amount: arg.Amount,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInsufficientFunds
+			}
+			return err
+		}
+
+		result.Entry, err = q.CreatePocketEntry(ctx, CreatePocketEntryParams{
+			AccountID:   pocket.AccountID,
+			Amount:      -arg.Amount,
+			PocketID:    &pocket.ID,
+			Description: description,
+		})
+		if err != nil {
+			return err
+		}
+		// the pocket was read before it was locked, its balance may have changed since
+		before := result.Pocket
+		before.Balance -= arg.Amount
+		return recordAudit(ctx, q, AuditRecord{
+			Action:   action,
+			Entity:   "pocket",
+			EntityID: auditID(pocket.ID),
+			Before:   before,
+			After:    result.Pocket,
+		})
+	})
+
+	return result, err
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const storePocketPrefix = "store_pocket_test_"
+
+func TestMovePocketTx(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account := fundTestingAccount(t, createRandomAccount(t, storePocketPrefix), 100)
+
+	defer deleteTestingAccount(ctx, storePocketPrefix)
+	defer deleteTestingEntry(ctx, account.ID)
+
+	goal := int64(500)
+	pocket, err := store.CreatePocket(ctx, CreatePocketParams{
+		AccountID:  account.ID,
+		Name:       "holiday",
+		GoalAmount: &goal,
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, pocket.Balance)
+
+	result, err := store.MovePocketTx(ctx, MovePocketTxParams{PocketID: pocket.ID, Amount: 60})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60), result.Pocket.Balance)
+	assert.Equal(t, int64(40), result.Account.Balance)
+	assert.Equal(t, int64(40), result.Account.AvailableBalance)
+	assert.Equal(t, int64(-60), result.Entry.Amount)
+	assert.Equal(t, pocket.ID, *result.Entry.PocketID)
+
+	// the overdraft is never used to fill a pocket
+	_, err = store.UpdateAccountOverdraft(ctx, UpdateAccountOverdraftParams{
+		ID:             account.ID,
+		OverdraftLimit: 1000,
+	})
+	assert.NoError(t, err)
+
+	_, err = store.MovePocketTx(ctx, MovePocketTxParams{PocketID: pocket.ID, Amount: 41})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// nor can a pocket give back more than it holds
+	_, err = store.MovePocketTx(ctx, MovePocketTxParams{PocketID: pocket.ID, Amount: -61})
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err = store.MovePocketTx(ctx, MovePocketTxParams{PocketID: pocket.ID, Amount: -20})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(40), result.Pocket.Balance)
+	assert.Equal(t, int64(60), result.Account.Balance)
+	assert.Equal(t, int64(20), result.Entry.Amount)
+
+	_, err = store.DeleteEmptyPocket(ctx, pocket.ID)
+	assert.Error(t, err)
+
+	_, err = store.MovePocketTx(ctx, MovePocketTxParams{PocketID: pocket.ID, Amount: -40})
+	assert.NoError(t, err)
+
+	deleted, err := store.DeleteEmptyPocket(ctx, pocket.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, pocket.ID, deleted.ID)
+}
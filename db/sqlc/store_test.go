@@ -2,17 +2,30 @@ package db
 
 import (
 	"context"
+	"math"
 	"testing"
 
+	"github.com/novalyezu/simplebank-backend/util"
 	"github.com/stretchr/testify/assert"
 )
 
 const storeTestPrefix = "store_test_"
 
+// fundTestingAccount sets the balance so transfers out of the account can't run out of funds
+func fundTestingAccount(t *testing.T, account Account, balance int64) Account {
+	account, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: balance,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, balance, account.AvailableBalance)
+	return account
+}
+
 func TestTransferTx(t *testing.T) {
 	ctx := context.Background()
-	store := NewStore(testDB)
-	account1 := createRandomAccount(t, storeTestPrefix)
+	store := NewStore(testDB, testLogger)
+	account1 := fundTestingAccount(t, createRandomAccount(t, storeTestPrefix), 1000)
 	account2 := createRandomAccount(t, storeTestPrefix)
 
 	n := 5
@@ -110,9 +123,9 @@ func TestTransferTx(t *testing.T) {
 
 func TestTransferTxDeadlock(t *testing.T) {
 	ctx := context.Background()
-	store := NewStore(testDB)
-	account1 := createRandomAccount(t, storeTestPrefix)
-	account2 := createRandomAccount(t, storeTestPrefix)
+	store := NewStore(testDB, testLogger)
+	account1 := fundTestingAccount(t, createRandomAccount(t, storeTestPrefix), 1000)
+	account2 := fundTestingAccount(t, createRandomAccount(t, storeTestPrefix), 1000)
 
 	n := 10
 	amount := int64(10)
@@ -163,3 +176,108 @@ func TestTransferTxDeadlock(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxWithFee(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account1 := fundTestingAccount(t, createRandomAccount(t, storeTestPrefix), 1000)
+	account2 := createRandomAccount(t, storeTestPrefix)
+
+	rule, err := testQueries.CreateFeeRule(ctx, CreateFeeRuleParams{
+		Currency:    account1.Currency,
+		FlatFee:     1,
+		BasisPoints: 100,
+	})
+	assert.NoError(t, err)
+
+	feeAccount, err := testQueries.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:       FeeRevenueOwner,
+		Currency:    account1.Currency,
+		AccountType: util.CheckingAccount,
+	})
+	assert.NoError(t, err)
+
+	defer deleteTestingAccount(ctx, storeTestPrefix)
+	defer store.DeleteTransferTx(ctx, DeleteTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+	})
+	defer testQueries.DeleteFeeRule(ctx, rule.ID)
+
+	amount := int64(200)
+	result, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	})
+	assert.NoError(t, err)
+
+	fee := int64(3)
+	assert.Equal(t, fee, result.Fee)
+	assert.Equal(t, fee, result.Transfer.Fee)
+	assert.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	assert.Equal(t, -fee, result.FeeEntry.Amount)
+	assert.Equal(t, account1.Balance-amount-fee, result.FromAccount.Balance)
+	assert.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+
+	updatedFeeAccount, err := store.GetAccount(ctx, feeAccount.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, feeAccount.Balance+fee, updatedFeeAccount.Balance)
+
+	err = testQueries.DeleteEntryByAccountID(ctx, feeAccount.ID)
+	assert.NoError(t, err)
+	_, err = testQueries.AddAccountBalance(ctx, AddAccountBalanceParams{ID: feeAccount.ID, Amount: -fee})
+	assert.NoError(t, err)
+}
+
+func TestTransferTxOverdraft(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	account1 := createRandomAccount(t, storeTestPrefix)
+	account2 := createRandomAccount(t, storeTestPrefix)
+
+	defer deleteTestingAccount(ctx, storeTestPrefix)
+	defer store.DeleteTransferTx(ctx, DeleteTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+	})
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.AvailableBalance + 100,
+	}
+
+	// no overdraft yet, nothing is written
+	_, err := store.TransferTx(ctx, arg)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	unchanged, err := store.GetAccount(ctx, account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account1.Balance, unchanged.Balance)
+
+	_, err = store.UpdateAccountOverdraft(ctx, UpdateAccountOverdraftParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	assert.NoError(t, err)
+
+	result, err := store.TransferTx(ctx, arg)
+	assert.NoError(t, err)
+	assert.Equal(t, account1.Balance-arg.Amount, result.FromAccount.Balance)
+	assert.Equal(t, int64(-100), result.FromAccount.AvailableBalance)
+
+	// the overdraft is used up
+	arg.Amount = 1
+	_, err = store.TransferTx(ctx, arg)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+// an amount plus fee which wraps around would credit the payer instead of debiting them
+func TestPostTransferAmountOverflow(t *testing.T) {
+	transfer := Transfer{ID: 1, FromAccountID: 1, ToAccountID: 2, Amount: math.MaxInt64, Fee: 2}
+
+	// it fails before the first query, so no queries are needed
+	_, err := postTransfer(context.Background(), nil, transfer, util.USD)
+	assert.ErrorIs(t, err, ErrAmountOverflow)
+}
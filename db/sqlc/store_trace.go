@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"runtime"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/novalyezu/simplebank-backend/db")
+
+// txOutcomeKey tells whether a transaction of execTx was committed or rolled back
+const txOutcomeKey = attribute.Key("db.transaction.outcome")
+
+// tracedDB makes a span of every query named after the sqlc query. Within a transaction
+// the queries are children of the span of the transaction, even though the functions run
+// by execTx query with the context of their caller.
+type tracedDB struct {
+	DBTX
+	// txSpan is the span of the transaction, nil outside of one
+	txSpan trace.Span
+}
+
+func (db tracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := db.startQuerySpan(ctx, query)
+	defer span.End()
+
+	result, err := db.DBTX.ExecContext(ctx, query, args...)
+	recordSpanError(span, err)
+	return result, err
+}
+
+func (db tracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := db.startQuerySpan(ctx, query)
+	defer span.End()
+
+	rows, err := db.DBTX.QueryContext(ctx, query, args...)
+	recordSpanError(span, err)
+	return rows, err
+}
+
+func (db tracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := db.startQuerySpan(ctx, query)
+	defer span.End()
+
+	row := db.DBTX.QueryRowContext(ctx, query, args...)
+	recordSpanError(span, row.Err())
+	return row
+}
+
+func (db tracedDB) startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	if db.txSpan != nil {
+		ctx = trace.ContextWithSpan(ctx, db.txSpan)
+	}
+
+	name := queryName(query)
+	if name == query {
+		name = "query"
+	}
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(name),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+// recordSpanError marks the span as failed, a missing row is an answer and not a failure
+func recordSpanError(span trace.Span, err error) {
+	if err == nil || err == sql.ErrNoRows {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// txName names a transaction after the method of the store which runs it
+func txName() string {
+	pcs := make([]uintptr, 8)
+	// skip runtime.Callers, txName and execTx
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+
+		name := frame.Function
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		name = name[strings.LastIndex(name, ".")+1:]
+		if name != "auditedTx" && name != "execTx" {
+			return name
+		}
+
+		if !more {
+			return "execTx"
+		}
+	}
+}
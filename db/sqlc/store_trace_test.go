@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// execOnlyDB answers every statement without a database
+type execOnlyDB struct {
+	DBTX
+}
+
+func (execOnlyDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
+func TestTracedDBQuerySpans(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	_, txSpan := provider.Tracer("test").Start(context.Background(), "tx")
+	q := New(tracedDB{DBTX: execOnlyDB{}, txSpan: txSpan})
+
+	// the query runs with a context which doesn't hold the span of the transaction
+	require.NoError(t, q.DeleteAccount(context.Background(), 1))
+	txSpan.End()
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	require.Equal(t, "DeleteAccount", ended[0].Name())
+	require.Equal(t, txSpan.SpanContext().SpanID(), ended[0].Parent().SpanID())
+	require.Equal(t, txSpan.SpanContext().TraceID(), ended[0].SpanContext().TraceID())
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookMaxAttempts is how often a delivery is tried before it fails for good,
+// with the backoff the last try is about 20 minutes after the first
+const WebhookMaxAttempts = 8
+
+// WebhookPayload is the body posted to the endpoints
+type WebhookPayload struct {
+	Type      string   `json:"type"`
+	AccountID int64    `json:"account_id"`
+	Transfer  Transfer `json:"transfer"`
+}
+
+// addTransferWebhooks queues the transfer for the endpoints of both of its accounts
+func addTransferWebhooks(ctx context.Context, q *Queries, transfer Transfer) error {
+	events := []WebhookPayload{
+		{Type: util.WebhookEventTransferOutgoing, AccountID: transfer.FromAccountID, Transfer: transfer},
+		{Type: util.WebhookEventTransferIncoming, AccountID: transfer.ToAccountID, Transfer: transfer},
+	}
+
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateWebhookDeliveries(ctx, CreateWebhookDeliveriesParams{
+			EventType: event.Type,
+			Payload:   payload,
+			AccountID: event.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WebhookAttemptResult is the outcome of posting a delivery once,
+// StatusCode is 0 when the endpoint didn't respond
+type WebhookAttemptResult struct {
+	StatusCode int32
+	Error      string
+	Duration   time.Duration
+}
+
+func (result WebhookAttemptResult) Succeeded() bool {
+	return result.Error == "" && result.StatusCode >= 200 && result.StatusCode < 300
+}
+
+// webhookClaimLease is how long claimed deliveries are skipped by other dispatchers,
+// it covers posting a whole batch to endpoints which time out
+const webhookClaimLease = 15 * time.Minute
+
+// DeliverWebhooks hands up to limit due deliveries to deliver, records every attempt and
+// returns how many it handled. A failed delivery is retried later with a backoff until
+// WebhookMaxAttempts. Like RelayOutbox the deliveries are claimed for a lease and delivered
+// outside of a transaction, the attempts are recorded in a short one afterwards.
+func (store *SQLStore) DeliverWebhooks(ctx context.Context, limit int32, deliver func(ClaimDueWebhookDeliveriesRow) WebhookAttemptResult) (int, error) {
+	deliveries, err := store.ClaimDueWebhookDeliveries(ctx, ClaimDueWebhookDeliveriesParams{
+		LeaseSeconds: int32(webhookClaimLease / time.Second),
+		RowLimit:     limit,
+	})
+	if err != nil {
+		return 0, err
+	}
+	slices.SortFunc(deliveries, func(a, b ClaimDueWebhookDeliveriesRow) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	results := make([]WebhookAttemptResult, len(deliveries))
+	for i, delivery := range deliveries {
+		results[i] = deliver(delivery)
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		for i, delivery := range deliveries {
+			result := results[i]
+
+			_, err := q.CreateWebhookAttempt(ctx, CreateWebhookAttemptParams{
+				DeliveryID: delivery.ID,
+				StatusCode: result.StatusCode,
+				Error:      result.Error,
+				DurationMs: result.Duration.Milliseconds(),
+			})
+			if err != nil {
+				return err
+			}
+
+			if result.Succeeded() {
+				err = q.MarkWebhookDeliverySucceeded(ctx, delivery.ID)
+			} else {
+				err = q.MarkWebhookDeliveryFailed(ctx, MarkWebhookDeliveryFailedParams{
+					ID:          delivery.ID,
+					MaxAttempts: WebhookMaxAttempts,
+				})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(deliveries), nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const storeWebhookPrefix = "store_webhook_test_"
+
+func TestTransferQueuesWebhooks(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB, testLogger)
+	defer deleteTestingAccount(ctx, storeWebhookPrefix)
+
+	var accounts []Account
+	for i := 0; i < 2; i++ {
+		user := createRandomUser(t, storeWebhookPrefix)
+		account, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner:       user.Username,
+			Balance:     100,
+			Currency:    util.IDR,
+			AccountType: util.CheckingAccount,
+		})
+		assert.NoError(t, err)
+		accounts = append(accounts, account)
+	}
+
+	secret, err := util.NewWebhookSecret()
+	assert.NoError(t, err)
+
+	// the endpoint of the receiver only wants incoming transfers
+	endpoint, err := store.CreateWebhookEndpoint(ctx, CreateWebhookEndpointParams{
+		AccountID:  accounts[1].ID,
+		Url:        "https://example.com/hooks",
+		Secret:     secret,
+		EventTypes: []string{util.WebhookEventTransferIncoming},
+		CreatedBy:  accounts[1].Owner,
+	})
+	assert.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[1].ID,
+		Amount:        10,
+	})
+	assert.NoError(t, err)
+	defer store.DeleteTransferTx(ctx, DeleteTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[1].ID,
+	})
+
+	deliveries, err := store.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{
+		EndpointID: endpoint.ID,
+		Limit:      10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, util.WebhookEventTransferIncoming, deliveries[0].EventType)
+	assert.Equal(t, WebhookDeliveryPending, deliveries[0].Status)
+	assert.Contains(t, string(deliveries[0].Payload), `"account_id":`)
+
+	// other tests may have queued deliveries too, only the one of the endpoint fails
+	for {
+		handled, err := store.DeliverWebhooks(ctx, 100, func(delivery ClaimDueWebhookDeliveriesRow) WebhookAttemptResult {
+			if delivery.EndpointID == endpoint.ID {
+				assert.Equal(t, secret, delivery.Secret)
+				return WebhookAttemptResult{StatusCode: 500}
+			}
+			return WebhookAttemptResult{StatusCode: 204}
+		})
+		assert.NoError(t, err)
+		if handled < 100 {
+			break
+		}
+	}
+
+	delivery, err := store.GetWebhookDelivery(ctx, deliveries[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, int32(1), delivery.Attempts)
+
+	attempts, err := store.ListWebhookAttempts(ctx, delivery.ID)
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 1)
+	assert.Equal(t, int32(500), attempts[0].StatusCode)
+
+	redelivered, err := store.RedeliverWebhook(ctx, delivery.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), redelivered.Attempts)
+}
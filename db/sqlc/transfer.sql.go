@@ -7,25 +7,96 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const createPendingTransfer = `-- name: CreatePendingTransfer :one
+INSERT INTO transfers (
+  from_account_id, to_account_id, amount, description, reference, metadata, status, expires_at,
+  initiated_by, required_approvals
+) VALUES (
+  $1, $2, $3, $4, $5, $6, 'pending', $7, $8, $9
 )
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals
+`
+
+type CreatePendingTransferParams struct {
+	FromAccountID     int64           `json:"from_account_id"`
+	ToAccountID       int64           `json:"to_account_id"`
+	Amount            int64           `json:"amount"`
+	Description       string          `json:"description"`
+	Reference         string          `json:"reference"`
+	Metadata          json.RawMessage `json:"metadata"`
+	ExpiresAt         *time.Time      `json:"expires_at"`
+	InitiatedBy       *string         `json:"initiated_by"`
+	RequiredApprovals int32           `json:"required_approvals"`
+}
+
+func (q *Queries) CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createPendingTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Description,
+		arg.Reference,
+		arg.Metadata,
+		arg.ExpiresAt,
+		arg.InitiatedBy,
+		arg.RequiredApprovals,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
+	)
+	return i, err
+}
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, fee, description, reference, metadata
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Fee           int64           `json:"fee"`
+	Description   string          `json:"description"`
+	Reference     string          `json:"reference"`
+	Metadata      json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.Description,
+		arg.Reference,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -33,6 +104,16 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
 	)
 	return i, err
 }
@@ -53,8 +134,61 @@ func (q *Queries) DeleteTransfer(ctx context.Context, arg DeleteTransferParams)
 	return err
 }
 
+const expirePendingTransfers = `-- name: ExpirePendingTransfers :many
+UPDATE transfers
+  set status = 'expired',
+  decided_at = now()
+WHERE id IN (
+  SELECT id FROM transfers
+  WHERE status = 'pending' AND expires_at <= now()
+  ORDER BY id
+  LIMIT $1
+  FOR NO KEY UPDATE SKIP LOCKED
+)
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals
+`
+
+func (q *Queries) ExpirePendingTransfers(ctx context.Context, limit int32) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, expirePendingTransfers, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.DecidedBy,
+			&i.DecidedAt,
+			&i.InitiatedBy,
+			&i.RequiredApprovals,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -67,12 +201,96 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
 	)
 	return i, err
 }
 
+const getTransfersByIDs = `-- name: GetTransfersByIDs :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals FROM transfers
+WHERE id = ANY($1::bigint[])
+ORDER BY id
+`
+
+func (q *Queries) GetTransfersByIDs(ctx context.Context, ids []int64) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, getTransfersByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.DecidedBy,
+			&i.DecidedAt,
+			&i.InitiatedBy,
+			&i.RequiredApprovals,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals FROM transfers
 WHERE 
   from_account_id = $1 OR
   to_account_id = $2
@@ -108,6 +326,227 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.DecidedBy,
+			&i.DecidedAt,
+			&i.InitiatedBy,
+			&i.RequiredApprovals,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersAfterOfAccounts = `-- name: ListTransfersAfterOfAccounts :many
+SELECT a.account_id::bigint AS account_id, transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.fee, transfers.description, transfers.reference, transfers.metadata, transfers.status, transfers.expires_at, transfers.decided_by, transfers.decided_at, transfers.initiated_by, transfers.required_approvals FROM unnest($1::bigint[]) AS a(account_id)
+JOIN LATERAL (
+  SELECT id FROM transfers t
+  WHERE (t.from_account_id = a.account_id OR t.to_account_id = a.account_id) AND t.id > $2
+  ORDER BY t.id
+  LIMIT $3
+) page ON true
+JOIN transfers ON transfers.id = page.id
+ORDER BY a.account_id, transfers.id
+`
+
+type ListTransfersAfterOfAccountsParams struct {
+	AccountIds []int64 `json:"account_ids"`
+	AfterID    int64   `json:"after_id"`
+	PageLimit  int32   `json:"page_limit"`
+}
+
+type ListTransfersAfterOfAccountsRow struct {
+	AccountID int64    `json:"account_id"`
+	Transfer  Transfer `json:"transfer"`
+}
+
+// a page of transfers for every account, the graphql loader batches the connections with it.
+// A transfer between two of the accounts is in both pages.
+func (q *Queries) ListTransfersAfterOfAccounts(ctx context.Context, arg ListTransfersAfterOfAccountsParams) ([]ListTransfersAfterOfAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersAfterOfAccounts, pq.Array(arg.AccountIds), arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersAfterOfAccountsRow{}
+	for rows.Next() {
+		var i ListTransfersAfterOfAccountsRow
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Transfer.ID,
+			&i.Transfer.FromAccountID,
+			&i.Transfer.ToAccountID,
+			&i.Transfer.Amount,
+			&i.Transfer.CreatedAt,
+			&i.Transfer.Fee,
+			&i.Transfer.Description,
+			&i.Transfer.Reference,
+			&i.Transfer.Metadata,
+			&i.Transfer.Status,
+			&i.Transfer.ExpiresAt,
+			&i.Transfer.DecidedBy,
+			&i.Transfer.DecidedAt,
+			&i.Transfer.InitiatedBy,
+			&i.Transfer.RequiredApprovals,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markTransferCompleted = `-- name: MarkTransferCompleted :one
+UPDATE transfers
+  set status = 'completed',
+  fee = $2,
+  decided_by = $3,
+  decided_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals
+`
+
+type MarkTransferCompletedParams struct {
+	ID        int64   `json:"id"`
+	Fee       int64   `json:"fee"`
+	DecidedBy *string `json:"decided_by"`
+}
+
+func (q *Queries) MarkTransferCompleted(ctx context.Context, arg MarkTransferCompletedParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, markTransferCompleted, arg.ID, arg.Fee, arg.DecidedBy)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
+	)
+	return i, err
+}
+
+const markTransferRejected = `-- name: MarkTransferRejected :one
+UPDATE transfers
+  set status = 'rejected',
+  decided_by = $2,
+  decided_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals
+`
+
+type MarkTransferRejectedParams struct {
+	ID        int64   `json:"id"`
+	DecidedBy *string `json:"decided_by"`
+}
+
+func (q *Queries) MarkTransferRejected(ctx context.Context, arg MarkTransferRejectedParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, markTransferRejected, arg.ID, arg.DecidedBy)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
+	)
+	return i, err
+}
+
+const searchTransfers = `-- name: SearchTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, status, expires_at, decided_by, decided_at, initiated_by, required_approvals FROM transfers
+WHERE
+  (from_account_id = $1 OR to_account_id = $1) AND
+  ($2::text = '' OR strpos(lower(description), lower($2::text)) > 0 OR strpos(lower(reference), lower($2::text)) > 0) AND
+  ($3::text = '' OR reference = $3::text) AND
+  metadata @> $4::jsonb
+ORDER BY id DESC
+LIMIT $6
+OFFSET $5
+`
+
+type SearchTransfersParams struct {
+	AccountID  int64           `json:"account_id"`
+	Query      string          `json:"query"`
+	Reference  string          `json:"reference"`
+	Metadata   json.RawMessage `json:"metadata"`
+	PageOffset int32           `json:"page_offset"`
+	PageLimit  int32           `json:"page_limit"`
+}
+
+// transfers in and out of an account, optionally filtered by a text query on
+// description and reference, an exact reference and metadata containment.
+// strpos matches the query literally, ILIKE would take % and _ in it as wildcards.
+func (q *Queries) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfers,
+		arg.AccountID,
+		arg.Query,
+		arg.Reference,
+		arg.Metadata,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.DecidedBy,
+			&i.DecidedAt,
+			&i.InitiatedBy,
+			&i.RequiredApprovals,
 		); err != nil {
 			return nil, err
 		}
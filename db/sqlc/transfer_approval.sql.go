@@ -0,0 +1,74 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: transfer_approval.sql
+
+package db
+
+import (
+	"context"
+)
+
+const countTransferApprovals = `-- name: CountTransferApprovals :one
+SELECT count(*) FROM transfer_approvals
+WHERE transfer_id = $1
+`
+
+func (q *Queries) CountTransferApprovals(ctx context.Context, transferID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransferApprovals, transferID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createTransferApproval = `-- name: CreateTransferApproval :execrows
+INSERT INTO transfer_approvals (
+  transfer_id, username
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (transfer_id, username) DO NOTHING
+`
+
+type CreateTransferApprovalParams struct {
+	TransferID int64  `json:"transfer_id"`
+	Username   string `json:"username"`
+}
+
+// approving twice is a no-op, zero rows are affected
+func (q *Queries) CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createTransferApproval, arg.TransferID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listTransferApprovals = `-- name: ListTransferApprovals :many
+SELECT transfer_id, username, created_at FROM transfer_approvals
+WHERE transfer_id = $1
+ORDER BY created_at, username
+`
+
+func (q *Queries) ListTransferApprovals(ctx context.Context, transferID int64) ([]TransferApproval, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferApprovals, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferApproval{}
+	for rows.Next() {
+		var i TransferApproval
+		if err := rows.Scan(&i.TransferID, &i.Username, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,172 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: transfer_batch.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createTransferBatch = `-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  from_account_id, created_by, mode, status, total_rows, succeeded_rows
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING id, from_account_id, created_by, mode, status, total_rows, succeeded_rows, created_at
+`
+
+type CreateTransferBatchParams struct {
+	FromAccountID int64  `json:"from_account_id"`
+	CreatedBy     string `json:"created_by"`
+	Mode          string `json:"mode"`
+	Status        string `json:"status"`
+	TotalRows     int32  `json:"total_rows"`
+	SucceededRows int32  `json:"succeeded_rows"`
+}
+
+func (q *Queries) CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error) {
+	row := q.db.QueryRowContext(ctx, createTransferBatch,
+		arg.FromAccountID,
+		arg.CreatedBy,
+		arg.Mode,
+		arg.Status,
+		arg.TotalRows,
+		arg.SucceededRows,
+	)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.CreatedBy,
+		&i.Mode,
+		&i.Status,
+		&i.TotalRows,
+		&i.SucceededRows,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTransferBatchRow = `-- name: CreateTransferBatchRow :one
+INSERT INTO transfer_batch_rows (
+  batch_id, row_number, to_account_id, amount, description, reference, status, transfer_id, error
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING id, batch_id, row_number, to_account_id, amount, description, reference, status, transfer_id, error
+`
+
+type CreateTransferBatchRowParams struct {
+	BatchID     int64  `json:"batch_id"`
+	RowNumber   int32  `json:"row_number"`
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Description string `json:"description"`
+	Reference   string `json:"reference"`
+	Status      string `json:"status"`
+	TransferID  *int64 `json:"transfer_id"`
+	Error       string `json:"error"`
+}
+
+func (q *Queries) CreateTransferBatchRow(ctx context.Context, arg CreateTransferBatchRowParams) (TransferBatchRow, error) {
+	row := q.db.QueryRowContext(ctx, createTransferBatchRow,
+		arg.BatchID,
+		arg.RowNumber,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Description,
+		arg.Reference,
+		arg.Status,
+		arg.TransferID,
+		arg.Error,
+	)
+	var i TransferBatchRow
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.RowNumber,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Description,
+		&i.Reference,
+		&i.Status,
+		&i.TransferID,
+		&i.Error,
+	)
+	return i, err
+}
+
+const deleteTransferBatchesByAccountID = `-- name: DeleteTransferBatchesByAccountID :exec
+DELETE FROM transfer_batches
+WHERE from_account_id = $1
+`
+
+// for testing purpose
+func (q *Queries) DeleteTransferBatchesByAccountID(ctx context.Context, fromAccountID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteTransferBatchesByAccountID, fromAccountID)
+	return err
+}
+
+const getTransferBatch = `-- name: GetTransferBatch :one
+SELECT id, from_account_id, created_by, mode, status, total_rows, succeeded_rows, created_at FROM transfer_batches
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error) {
+	row := q.db.QueryRowContext(ctx, getTransferBatch, id)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.CreatedBy,
+		&i.Mode,
+		&i.Status,
+		&i.TotalRows,
+		&i.SucceededRows,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listTransferBatchRows = `-- name: ListTransferBatchRows :many
+SELECT id, batch_id, row_number, to_account_id, amount, description, reference, status, transfer_id, error FROM transfer_batch_rows
+WHERE batch_id = $1
+ORDER BY row_number
+`
+
+func (q *Queries) ListTransferBatchRows(ctx context.Context, batchID int64) ([]TransferBatchRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferBatchRows, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferBatchRow{}
+	for rows.Next() {
+		var i TransferBatchRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BatchID,
+			&i.RowNumber,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Description,
+			&i.Reference,
+			&i.Status,
+			&i.TransferID,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
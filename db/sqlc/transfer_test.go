@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/novalyezu/simplebank-backend/util"
@@ -16,6 +17,9 @@ func createRandomTransfer(t *testing.T, fromAccount Account, toAccount Account)
 		FromAccountID: fromAccount.ID,
 		ToAccountID:   toAccount.ID,
 		Amount:        util.RandomInt(0, 20),
+		Description:   util.RandomString(12),
+		Reference:     util.RandomString(8),
+		Metadata:      json.RawMessage(`{"source":"test"}`),
 	}
 
 	transfer, err := testQueries.CreateTransfer(ctx, arg)
@@ -25,6 +29,10 @@ func createRandomTransfer(t *testing.T, fromAccount Account, toAccount Account)
 	assert.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	assert.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	assert.Equal(t, arg.Amount, transfer.Amount)
+	assert.Equal(t, arg.Fee, transfer.Fee)
+	assert.Equal(t, arg.Description, transfer.Description)
+	assert.Equal(t, arg.Reference, transfer.Reference)
+	assert.JSONEq(t, string(arg.Metadata), string(transfer.Metadata))
 
 	assert.NotZero(t, transfer.ID)
 	assert.NotZero(t, transfer.CreatedAt)
@@ -93,3 +101,94 @@ func TestListTransfers(t *testing.T) {
 		assert.NotEmpty(t, transfer)
 	}
 }
+
+func TestSearchTransfers(t *testing.T) {
+	ctx := context.Background()
+	account1 := createRandomAccount(t, accTransferPrefix)
+	account2 := createRandomAccount(t, accTransferPrefix)
+
+	defer deleteTestingAccount(ctx, accTransferPrefix)
+	defer deleteTestingTransfer(ctx, account1.ID, account2.ID)
+
+	var last Transfer
+	for i := 0; i < 3; i++ {
+		last = createRandomTransfer(t, account1, account2)
+	}
+
+	arg := SearchTransfersParams{
+		AccountID: account2.ID,
+		Metadata:  json.RawMessage(`{}`),
+		PageLimit: 5,
+	}
+
+	transfers, err := testQueries.SearchTransfers(ctx, arg)
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 3)
+	assert.Equal(t, last.ID, transfers[0].ID)
+
+	arg.Query = last.Description[2:8]
+	transfers, err = testQueries.SearchTransfers(ctx, arg)
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 1)
+	assert.Equal(t, last.ID, transfers[0].ID)
+
+	// wildcards of LIKE are matched literally
+	arg.Query = "%"
+	transfers, err = testQueries.SearchTransfers(ctx, arg)
+	assert.NoError(t, err)
+	assert.Empty(t, transfers)
+
+	arg.Query = ""
+	arg.Reference = last.Reference
+	transfers, err = testQueries.SearchTransfers(ctx, arg)
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 1)
+
+	arg.Reference = ""
+	arg.Metadata = json.RawMessage(`{"source":"test"}`)
+	transfers, err = testQueries.SearchTransfers(ctx, arg)
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 3)
+
+	arg.Metadata = json.RawMessage(`{"source":"other"}`)
+	transfers, err = testQueries.SearchTransfers(ctx, arg)
+	assert.NoError(t, err)
+	assert.Empty(t, transfers)
+}
+
+func TestListTransfersAfterOfAccounts(t *testing.T) {
+	ctx := context.Background()
+	account1 := createRandomAccount(t, accTransferPrefix)
+	account2 := createRandomAccount(t, accTransferPrefix)
+
+	var transfers []Transfer
+	for i := 0; i < 5; i++ {
+		transfers = append(transfers, createRandomTransfer(t, account1, account2))
+		transfers = append(transfers, createRandomTransfer(t, account2, account1))
+	}
+
+	defer deleteTestingAccount(ctx, accTransferPrefix)
+	defer deleteTestingTransfer(ctx, account1.ID, account2.ID)
+	defer deleteTestingTransfer(ctx, account2.ID, account1.ID)
+
+	// transfers in and out of the accounts, a transfer between both is in both pages
+	rows, err := testQueries.ListTransfersAfterOfAccounts(ctx, ListTransfersAfterOfAccountsParams{
+		AccountIds: []int64{account1.ID, account2.ID},
+		AfterID:    transfers[1].ID,
+		PageLimit:  4,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 8)
+	for i, row := range rows {
+		if i < 4 {
+			assert.Equal(t, account1.ID, row.AccountID)
+		} else {
+			assert.Equal(t, account2.ID, row.AccountID)
+		}
+		assert.Equal(t, transfers[2+i%4], row.Transfer)
+	}
+
+	found, err := testQueries.GetTransfersByIDs(ctx, []int64{transfers[3].ID, transfers[0].ID})
+	assert.NoError(t, err)
+	assert.Equal(t, []Transfer{transfers[0], transfers[3]}, found)
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"testing"
 
 	"github.com/novalyezu/simplebank-backend/util"
@@ -30,6 +32,7 @@ func createRandomUser(t *testing.T, prefix string) User {
 	assert.Equal(t, arg.HashedPassword, user.HashedPassword)
 	assert.Equal(t, arg.FullName, user.FullName)
 	assert.Equal(t, arg.Email, user.Email)
+	assert.Equal(t, util.DepositorRole, user.Role)
 
 	assert.True(t, user.PasswordChangedAt.IsZero())
 	assert.NotZero(t, user.CreatedAt)
@@ -61,3 +64,20 @@ func TestGetUser(t *testing.T) {
 	assert.Equal(t, newUser.FullName, user.FullName)
 	assert.Equal(t, newUser.Email, user.Email)
 }
+
+func TestGetUserByUsernameOrEmail(t *testing.T) {
+	ctx := context.Background()
+	newUser := createRandomUser(t, userPrefix)
+	defer deleteTestingUser(ctx, userPrefix)
+
+	user, err := testQueries.GetUserByUsernameOrEmail(ctx, newUser.Username)
+	assert.NoError(t, err)
+	assert.Equal(t, newUser.Username, user.Username)
+
+	user, err = testQueries.GetUserByUsernameOrEmail(ctx, strings.ToUpper(newUser.Email))
+	assert.NoError(t, err)
+	assert.Equal(t, newUser.Username, user.Username)
+
+	_, err = testQueries.GetUserByUsernameOrEmail(ctx, FeeRevenueOwner)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
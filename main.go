@@ -1,51 +1,135 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/novalyezu/simplebank-backend/api"
+	"github.com/novalyezu/simplebank-backend/config"
 	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/metrics"
 	"github.com/novalyezu/simplebank-backend/token"
+	"github.com/novalyezu/simplebank-backend/tracing"
+	"github.com/novalyezu/simplebank-backend/worker"
 )
 
 func main() {
-	errEnv := godotenv.Load(".env")
-	if errEnv != nil {
-		log.Fatal("Error loading .env file")
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Invalid configuration", err)
 	}
 
-	var (
-		dbHost            = os.Getenv("POSTGRES_HOST")
-		dbPort            = os.Getenv("POSTGRES_PORT")
-		dbUser            = os.Getenv("POSTGRES_USER")
-		dbPass            = os.Getenv("POSTGRES_PASSWORD")
-		dbName            = os.Getenv("POSTGRES_DATABASE")
-		tokenSymmetricKey = os.Getenv("TOKEN_SYMMETRIC_KEY")
-	)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TraceExporter)
+	if err != nil {
+		fatal("Cannot set up tracing", err)
+	}
 
 	dbDriver := "postgres"
-	dbSource := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable", dbUser, dbPass, dbHost, dbPort, dbName)
+	dbSource := cfg.DBSource()
 
 	conn, err := sql.Open(dbDriver, dbSource)
 	if err != nil {
-		log.Fatal("Cannot connect to db: ", err)
+		fatal("Cannot connect to db", err)
+	}
+	conn.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	conn.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	conn.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	err = metrics.RegisterDB(conn, cfg.DBName)
+	if err != nil {
+		fatal("Cannot register the db metrics", err)
 	}
 
-	store := db.NewStore(conn)
-	tokenMaker, err := token.NewPasetoMaker(tokenSymmetricKey)
+	store := db.NewStore(conn, logger)
+	tokenMaker, err := token.NewPasetoMaker(cfg.TokenSymmetricKey)
 	if err != nil {
-		log.Fatal("Cannot create token maker: ", err)
+		fatal("Cannot create token maker", err)
 	}
 
-	server := api.NewServer(store, tokenMaker)
+	// SIGTERM is how Kubernetes stops a pod, the servers then drain their requests
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	run := func(w interface{ Run(context.Context) }) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			w.Run(ctx)
+		}()
+	}
+
+	run(worker.NewHoldExpirer(store, time.Minute, logger))
+	run(worker.NewInterestAccruer(store, time.Hour, logger))
+	run(worker.NewTransferExpirer(store, time.Minute, logger))
+	run(worker.NewWebhookDispatcher(store, nil, 5*time.Second, logger))
+
+	// the config is validated, without a sink the events wait in the outbox
+	switch cfg.OutboxSink {
+	case config.OutboxSinkStdout:
+		run(worker.NewOutboxRelay(store, worker.NewStdoutSink(), time.Second, logger))
+	case config.OutboxSinkHTTP:
+		run(worker.NewOutboxRelay(store, worker.NewHTTPSink(cfg.OutboxWebhookURL, nil), time.Second, logger))
+	}
 
-	err = server.Start(":3000")
+	listener := pq.NewListener(dbSource, 10*time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Error("account events listener", slog.Any("error", err))
+		}
+	})
+	err = listener.Listen(db.AccountEventsChannel)
 	if err != nil {
-		log.Fatal("Cannot start the server: ", err)
+		fatal("Cannot listen for account events", err)
 	}
+	accountEvents := worker.NewAccountEventBroker(listener.Notify, logger)
+	run(accountEvents)
+
+	server := api.NewServer(cfg, store, tokenMaker, accountEvents, logger)
+
+	servers := make(chan error, 3)
+	go func() {
+		servers <- server.StartGRPC(ctx, cfg.GRPCServerAddress, cfg.ShutdownTimeout)
+	}()
+	go func() {
+		servers <- server.Start(ctx, cfg.HTTPServerAddress, cfg.ShutdownTimeout)
+	}()
+	go func() {
+		servers <- metrics.Start(ctx, cfg.MetricsServerAddress)
+	}()
+
+	failed := false
+	for i := 0; i < cap(servers); i++ {
+		if err := <-servers; err != nil {
+			logger.Error("Cannot serve", slog.Any("error", err))
+			failed = true
+		}
+		// a server which fails takes the other ones down with it
+		stop()
+	}
+
+	workers.Wait()
+	listener.Close()
+	if err := shutdownTracing(context.Background()); err != nil {
+		logger.Error("Cannot flush the spans", slog.Any("error", err))
+	}
+	conn.Close()
+
+	if failed {
+		os.Exit(1)
+	}
+	logger.Info("Server stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
 }
@@ -0,0 +1,80 @@
+// Package metrics holds the Prometheus collectors of the service, they are registered
+// with the default registry which Serve serves on an address of its own.
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "simplebank"
+
+// The outcomes of a transfer transaction
+const (
+	OutcomeCommitted         = "committed"
+	OutcomeInsufficientFunds = "insufficient_funds"
+	OutcomeNotFound          = "not_found"
+	OutcomeError             = "error"
+)
+
+var (
+	// HTTPRequests is labeled with the route pattern, not the path, to keep the number of series bounded
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to serve HTTP requests by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	TransferTxDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transfer_tx_duration_seconds",
+		Help:      "Time of the transfer transactions by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	TransactionRollbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_transaction_rollbacks_total",
+		Help:      "Database transactions of the store which were rolled back.",
+	})
+
+	TokenVerificationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_verification_failures_total",
+		Help:      "Requests whose access token was rejected by reason.",
+	}, []string{"reason"})
+
+	// TransferVolume is the sum of the completed transfers in the smallest unit of the currency
+	TransferVolume = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_volume_total",
+		Help:      "Amount moved by completed transfers by currency.",
+	}, []string{"currency"})
+
+	Transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Completed transfers by currency.",
+	}, []string{"currency"})
+)
+
+// RegisterDB exposes the connection pool stats of db
+func RegisterDB(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveTransfer counts a completed transfer in the business metrics
+func ObserveTransfer(currency string, amount int64) {
+	Transfers.WithLabelValues(currency).Inc()
+	TransferVolume.WithLabelValues(currency).Add(float64(amount))
+}
@@ -26,8 +26,8 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateToken(username string, role string, organizationID int64, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, role, organizationID, duration)
 	if err != nil {
 		return "", err
 	}
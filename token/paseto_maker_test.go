@@ -17,7 +17,7 @@ func TestPasetoMaker(t *testing.T) {
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, err := maker.CreateToken(username, duration)
+	token, err := maker.CreateToken(username, util.DepositorRole, 0, duration)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -27,10 +27,26 @@ func TestPasetoMaker(t *testing.T) {
 
 	assert.NotZero(t, payload.ID)
 	assert.Equal(t, username, payload.Username)
+	assert.Equal(t, util.DepositorRole, payload.Role)
+	assert.Zero(t, payload.OrganizationID)
 	assert.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	assert.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
 
+func TestPasetoMakerOrganizationToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	organizationID := util.RandomInt(1, 1000)
+
+	token, err := maker.CreateToken(util.RandomString(6), util.DepositorRole, organizationID, time.Minute)
+	assert.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, organizationID, payload.OrganizationID)
+}
+
 func TestPasetoMakerExpiredToken(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	assert.NoError(t, err)
@@ -38,7 +54,7 @@ func TestPasetoMakerExpiredToken(t *testing.T) {
 	username := util.RandomString(6)
 	duration := -time.Minute
 
-	token, err := maker.CreateToken(username, duration)
+	token, err := maker.CreateToken(username, util.DepositorRole, 0, duration)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -58,7 +74,7 @@ func TestPasetoMakerInvalidToken(t *testing.T) {
 	username := util.RandomString(6)
 	duration := -time.Minute
 
-	token, err := maker1.CreateToken(username, duration)
+	token, err := maker1.CreateToken(username, util.DepositorRole, 0, duration)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
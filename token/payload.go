@@ -12,24 +12,30 @@ var (
 	ErrInvalidToken = errors.New("token is invalid")
 )
 
+// Payload is what a token proves about its holder. OrganizationID is the organization
+// the holder acts for, 0 when they act for themselves.
 type Payload struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	IssuedAt  time.Time `json:"issued_at"`
-	ExpiredAt time.Time `json:"expired_at"`
+	ID             uuid.UUID `json:"id"`
+	Username       string    `json:"username"`
+	Role           string    `json:"role"`
+	OrganizationID int64     `json:"organization_id,omitempty"`
+	IssuedAt       time.Time `json:"issued_at"`
+	ExpiredAt      time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, organizationID int64, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return &Payload{}, err
 	}
 
 	payload := &Payload{
-		ID:        tokenID,
-		Username:  username,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(duration),
+		ID:             tokenID,
+		Username:       username,
+		Role:           role,
+		OrganizationID: organizationID,
+		IssuedAt:       time.Now(),
+		ExpiredAt:      time.Now().Add(duration),
 	}
 
 	return payload, nil
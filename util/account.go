@@ -0,0 +1,29 @@
+package util
+
+const (
+	CheckingAccount = "checking"
+	SavingsAccount  = "savings"
+)
+
+func IsSupportedAccountType(accountType string) bool {
+	switch accountType {
+	case CheckingAccount, SavingsAccount:
+		return true
+	}
+	return false
+}
+
+// roles of the members of a joint account, the account owner always has the owner role
+const (
+	AccountOwnerRole   = "owner"
+	AccountCoOwnerRole = "co_owner"
+	AccountViewerRole  = "viewer"
+)
+
+func IsSupportedAccountRole(role string) bool {
+	switch role {
+	case AccountOwnerRole, AccountCoOwnerRole, AccountViewerRole:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// account numbers look like IBANs: the prefix, two check digits and a basic account number
+// of 12 digits, e.g. "SB06123456789012". The check digits are computed the way IBAN does,
+// with the prefix and check digits moved to the end the number is 1 modulo 97.
+const (
+	accountNumberPrefix = "SB"
+	accountNumberDigits = 12
+	AccountNumberLength = len(accountNumberPrefix) + 2 + accountNumberDigits
+)
+
+func IsValidAccountNumber(number string) bool {
+	if len(number) != AccountNumberLength || !strings.HasPrefix(number, accountNumberPrefix) {
+		return false
+	}
+	for _, c := range number[len(accountNumberPrefix):] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	checkDigits := number[len(accountNumberPrefix) : len(accountNumberPrefix)+2]
+	basic := number[len(accountNumberPrefix)+2:]
+	return mod97(basic+prefixDigits()+checkDigits) == 1
+}
+
+// NewAccountNumber adds the prefix and check digits to a basic account number of 12 digits
+func NewAccountNumber(basic string) string {
+	checkDigits := 98 - mod97(basic+prefixDigits()+"00")
+	return fmt.Sprintf("%s%02d%s", accountNumberPrefix, checkDigits, basic)
+}
+
+// prefixDigits replaces the letters of the prefix with numbers, A is 10 and Z is 35
+func prefixDigits() string {
+	var sb strings.Builder
+	for _, c := range accountNumberPrefix {
+		sb.WriteString(strconv.Itoa(int(c-'A') + 10))
+	}
+	return sb.String()
+}
+
+// mod97 computes the remainder digit by digit, the number is too long for an int64
+func mod97(digits string) int {
+	remainder := 0
+	for _, c := range digits {
+		remainder = (remainder*10 + int(c-'0')) % 97
+	}
+	return remainder
+}
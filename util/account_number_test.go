@@ -0,0 +1,40 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAccountNumber(t *testing.T) {
+	assert.Equal(t, "SB06123456789012", NewAccountNumber("123456789012"))
+	assert.Equal(t, "SB07000000000000", NewAccountNumber("000000000000"))
+	assert.Equal(t, "SB77000000000001", NewAccountNumber("000000000001"))
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, IsValidAccountNumber(RandomAccountNumber()))
+	}
+}
+
+func TestIsValidAccountNumber(t *testing.T) {
+	testCases := []struct {
+		name   string
+		number string
+		valid  bool
+	}{
+		{name: "Valid", number: "SB06123456789012", valid: true},
+		{name: "WrongCheckDigits", number: "SB07123456789012", valid: false},
+		{name: "SwappedDigits", number: "SB06213456789012", valid: false},
+		{name: "WrongPrefix", number: "XX06123456789012", valid: false},
+		{name: "TooShort", number: "SB0612345678901", valid: false},
+		{name: "Letters", number: "SB0612345678901A", valid: false},
+		{name: "LowerCase", number: "sb06123456789012", valid: false},
+		{name: "Empty", number: "", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.valid, IsValidAccountNumber(tc.number))
+		})
+	}
+}
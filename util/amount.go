@@ -0,0 +1,15 @@
+package util
+
+// MaxAmount is the largest amount of money a single request moves, in the currency
+// minor unit. It keeps an amount plus its fee far from overflowing int64,
+// the binding tags of the api repeat it as max=1000000000000000.
+const MaxAmount = 1_000_000_000_000_000
+
+// AddAmounts returns a plus b, ok is false when the sum overflows int64
+func AddAmounts(a int64, b int64) (sum int64, ok bool) {
+	sum = a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, false
+	}
+	return sum, true
+}
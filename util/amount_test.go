@@ -0,0 +1,33 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAmounts(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    int64
+		b    int64
+		sum  int64
+		ok   bool
+	}{
+		{name: "OK", a: 100, b: 25, sum: 125, ok: true},
+		{name: "Negative", a: -100, b: -25, sum: -125, ok: true},
+		{name: "MaxAmountAndFee", a: MaxAmount, b: MaxAmount, sum: 2 * MaxAmount, ok: true},
+		{name: "Max", a: math.MaxInt64 - 2, b: 2, sum: math.MaxInt64, ok: true},
+		{name: "Overflow", a: math.MaxInt64, b: 2, ok: false},
+		{name: "Underflow", a: math.MinInt64, b: -1, ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sum, ok := AddAmounts(tc.a, tc.b)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.sum, sum)
+		})
+	}
+}
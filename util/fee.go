@@ -0,0 +1,12 @@
+package util
+
+const basisPointsPerUnit = 10000
+
+// CalculateFee returns flatFee plus basisPoints of amount rounded down,
+// everything is in the currency minor unit.
+// amount is split before multiplying so large amounts don't overflow.
+func CalculateFee(amount int64, flatFee int64, basisPoints int64) int64 {
+	whole := amount / basisPointsPerUnit * basisPoints
+	rest := amount % basisPointsPerUnit * basisPoints / basisPointsPerUnit
+	return flatFee + whole + rest
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateFee(t *testing.T) {
+	testCases := []struct {
+		name        string
+		amount      int64
+		flatFee     int64
+		basisPoints int64
+		fee         int64
+	}{
+		{name: "Flat", amount: 12345, flatFee: 50, basisPoints: 0, fee: 50},
+		{name: "Percentage", amount: 20000, flatFee: 0, basisPoints: 150, fee: 300},
+		{name: "FlatAndPercentage", amount: 20000, flatFee: 25, basisPoints: 150, fee: 325},
+		{name: "RoundDown", amount: 999, flatFee: 0, basisPoints: 10, fee: 0},
+		{name: "NoOverflow", amount: math.MaxInt64, flatFee: 0, basisPoints: 10000, fee: math.MaxInt64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.fee, CalculateFee(tc.amount, tc.flatFee, tc.basisPoints))
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package util
+
+import "math/big"
+
+// interest is accrued daily on an actual/365 fixed basis with the annual rate in basis points
+const interestDayDivisor = basisPointsPerUnit * 365
+
+// DailyInterest returns the interest in minor units earned by balance for one day
+// and the remainder smaller than one minor unit, scaled by 3650000.
+// Passing the previous day's remainder as carry keeps the sum over many days exact.
+func DailyInterest(balance int64, annualRateBps int64, carry int64) (amount int64, remainder int64) {
+	numerator := new(big.Int).Mul(big.NewInt(balance), big.NewInt(annualRateBps))
+	numerator.Add(numerator, big.NewInt(carry))
+
+	quotient, modulus := new(big.Int).DivMod(numerator, big.NewInt(interestDayDivisor), new(big.Int))
+	return quotient.Int64(), modulus.Int64()
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyInterest(t *testing.T) {
+	// 1,000,000 minor units at 3.65% earns exactly 100 per day
+	amount, remainder := DailyInterest(1_000_000, 365, 0)
+	assert.Equal(t, int64(100), amount)
+	assert.Equal(t, int64(0), remainder)
+
+	// too small for a whole minor unit, kept as remainder
+	amount, remainder = DailyInterest(1000, 100, 0)
+	assert.Equal(t, int64(0), amount)
+	assert.Equal(t, int64(100000), remainder)
+}
+
+func TestDailyInterestCarry(t *testing.T) {
+	balance := int64(123_457)
+	rate := int64(425)
+
+	var total, carry, amount int64
+	for day := 0; day < 365; day++ {
+		amount, carry = DailyInterest(balance, rate, carry)
+		total += amount
+	}
+
+	// a full year of daily accruals adds up to the annual interest
+	assert.Equal(t, balance*rate/10000, total)
+	assert.Equal(t, balance*rate%10000*365, carry)
+}
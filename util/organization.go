@@ -0,0 +1,17 @@
+package util
+
+// roles of the members of an organization, admins manage it, initiators send transfers
+// from its accounts and approvers approve them
+const (
+	OrganizationAdminRole     = "admin"
+	OrganizationInitiatorRole = "initiator"
+	OrganizationApproverRole  = "approver"
+)
+
+func IsSupportedOrganizationRole(role string) bool {
+	switch role {
+	case OrganizationAdminRole, OrganizationInitiatorRole, OrganizationApproverRole:
+		return true
+	}
+	return false
+}
@@ -40,3 +40,7 @@ func RandomCurrency() string {
 func RandomEmail(n int) string {
 	return fmt.Sprintf("%s@email.com", RandomString(n))
 }
+
+func RandomAccountNumber() string {
+	return NewAccountNumber(fmt.Sprintf("%012d", rand.Int63n(1_000_000_000_000)))
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// webhook event types an endpoint may subscribe to
+const (
+	WebhookEventTransferOutgoing = "transfer.outgoing"
+	WebhookEventTransferIncoming = "transfer.incoming"
+)
+
+func IsSupportedWebhookEvent(eventType string) bool {
+	switch eventType {
+	case WebhookEventTransferOutgoing, WebhookEventTransferIncoming:
+		return true
+	}
+	return false
+}
+
+// NewWebhookSecret returns the random key an endpoint verifies the signatures with
+func NewWebhookSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(key), nil
+}
+
+// SignWebhook signs the body sent at the unix timestamp, the receiver recomputes it
+// from the X-Webhook-Timestamp header and the raw body and compares it to X-Webhook-Signature.
+// The timestamp is signed too so an old request can't be replayed later.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook tells whether the signature was made for the body and timestamp with the secret
+func VerifyWebhook(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, timestamp, body)), []byte(signature))
+}
+
+// nonPublicPrefixes are the ranges IsPublicIP rejects on top of the private, loopback,
+// link-local and multicast ones of the netip package
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicIP tells whether a webhook may be posted to the address, the internal network
+// of the bank and the metadata services of the cloud are not reachable from the internet
+func IsPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPublicWebhookURL rejects the URLs which name a local host or a non public address.
+// Host names are resolved when the webhook is posted, the dispatcher checks the addresses then.
+func IsPublicWebhookURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return IsPublicIP(ip)
+	}
+	return true
+}
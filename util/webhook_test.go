@@ -0,0 +1,61 @@
+package util
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhook(t *testing.T) {
+	secret, err := NewWebhookSecret()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	body := []byte(`{"type":"transfer.incoming"}`)
+	signature := SignWebhook(secret, 1700000000, body)
+	assert.True(t, strings.HasPrefix(signature, "sha256="))
+	assert.True(t, VerifyWebhook(secret, 1700000000, body, signature))
+
+	// printf '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686",
+		SignWebhook("secret", 1700000000, []byte(`{"a":1}`)),
+	)
+
+	assert.False(t, VerifyWebhook(secret, 1700000001, body, signature))
+	assert.False(t, VerifyWebhook(secret, 1700000000, []byte(`{}`), signature))
+	assert.False(t, VerifyWebhook("whsec_other", 1700000000, body, signature))
+}
+
+func TestIsSupportedWebhookEvent(t *testing.T) {
+	assert.True(t, IsSupportedWebhookEvent(WebhookEventTransferOutgoing))
+	assert.True(t, IsSupportedWebhookEvent(WebhookEventTransferIncoming))
+	assert.False(t, IsSupportedWebhookEvent("transfer.deleted"))
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for _, address := range []string{"93.184.216.34", "2606:2800:220:1::1"} {
+		assert.True(t, IsPublicIP(netip.MustParseAddr(address)), address)
+	}
+
+	for _, address := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "255.255.255.255", "224.0.0.1",
+		"::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254", "64:ff9b::a9fe:a9fe",
+	} {
+		assert.False(t, IsPublicIP(netip.MustParseAddr(address)), address)
+	}
+}
+
+func TestIsPublicWebhookURL(t *testing.T) {
+	assert.True(t, IsPublicWebhookURL("https://example.com/hooks"))
+	assert.True(t, IsPublicWebhookURL("https://93.184.216.34:8443/hooks"))
+
+	assert.False(t, IsPublicWebhookURL("http://localhost:3000/hooks"))
+	assert.False(t, IsPublicWebhookURL("http://api.localhost./hooks"))
+	assert.False(t, IsPublicWebhookURL("http://169.254.169.254/latest/meta-data"))
+	assert.False(t, IsPublicWebhookURL("http://[::1]/hooks"))
+	assert.False(t, IsPublicWebhookURL("http://10.0.0.5/hooks"))
+}
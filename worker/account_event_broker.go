@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/lib/pq"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+// accountEventBufferSize is how many events a subscriber may fall behind before it is dropped
+const accountEventBufferSize = 32
+
+// AccountEventBroker fans the notifications on db.AccountEventsChannel out to the subscribers
+// of the accounts. The notifications come from a *pq.Listener which listens on the channel.
+type AccountEventBroker struct {
+	notifications <-chan *pq.Notification
+	logger        *slog.Logger
+
+	mu            sync.Mutex
+	subscriptions map[*accountEventSubscription]struct{}
+}
+
+type accountEventSubscription struct {
+	accountIDs map[int64]bool
+	events     chan db.AccountEvent
+}
+
+func NewAccountEventBroker(notifications <-chan *pq.Notification, logger *slog.Logger) *AccountEventBroker {
+	return &AccountEventBroker{
+		notifications: notifications,
+		logger:        logger,
+		subscriptions: make(map[*accountEventSubscription]struct{}),
+	}
+}
+
+// Subscribe returns the events of the accounts until unsubscribe is called. The channel is
+// closed when the subscriber falls behind or the connection to the database was lost, events
+// may be missing then and the subscriber should load the accounts again.
+func (broker *AccountEventBroker) Subscribe(accountIDs []int64) (<-chan db.AccountEvent, func()) {
+	subscription := &accountEventSubscription{
+		accountIDs: make(map[int64]bool, len(accountIDs)),
+		events:     make(chan db.AccountEvent, accountEventBufferSize),
+	}
+	for _, id := range accountIDs {
+		subscription.accountIDs[id] = true
+	}
+
+	broker.mu.Lock()
+	broker.subscriptions[subscription] = struct{}{}
+	broker.mu.Unlock()
+
+	unsubscribe := func() {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		broker.remove(subscription)
+	}
+	return subscription.events, unsubscribe
+}
+
+// Run blocks until ctx is done or the notifications are closed, the subscriptions are closed then.
+func (broker *AccountEventBroker) Run(ctx context.Context) {
+	defer broker.closeAll()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification, ok := <-broker.notifications:
+			if !ok {
+				return
+			}
+			if notification == nil {
+				// the listener reconnected, notifications sent in between are lost
+				broker.logger.WarnContext(ctx, "Account events listener reconnected, closing the subscriptions")
+				broker.closeAll()
+				continue
+			}
+
+			var event db.AccountEvent
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				broker.logger.ErrorContext(ctx, "Cannot decode account event", slog.Any("error", err))
+				continue
+			}
+			broker.publish(event)
+		}
+	}
+}
+
+func (broker *AccountEventBroker) publish(event db.AccountEvent) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for subscription := range broker.subscriptions {
+		if !subscription.accountIDs[event.AccountID] {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			// a slow subscriber doesn't hold up the others
+			broker.remove(subscription)
+		}
+	}
+}
+
+func (broker *AccountEventBroker) closeAll() {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for subscription := range broker.subscriptions {
+		broker.remove(subscription)
+	}
+}
+
+// remove closes the subscription once, broker.mu must be held
+func (broker *AccountEventBroker) remove(subscription *accountEventSubscription) {
+	if _, ok := broker.subscriptions[subscription]; !ok {
+		return
+	}
+	delete(broker.subscriptions, subscription)
+	close(subscription.events)
+}
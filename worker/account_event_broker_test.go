@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveAccountEvent(t *testing.T, events <-chan db.AccountEvent) (db.AccountEvent, bool) {
+	select {
+	case event, ok := <-events:
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("no account event received")
+		return db.AccountEvent{}, false
+	}
+}
+
+func TestAccountEventBrokerPublishesToSubscribers(t *testing.T) {
+	notifications := make(chan *pq.Notification)
+	broker := NewAccountEventBroker(notifications, testLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		broker.Run(ctx)
+		close(done)
+	}()
+
+	events, unsubscribe := broker.Subscribe([]int64{1})
+	defer unsubscribe()
+	others, unsubscribeOthers := broker.Subscribe([]int64{2})
+	defer unsubscribeOthers()
+
+	notifications <- &pq.Notification{
+		Channel: db.AccountEventsChannel,
+		Extra:   `{"type":"balance","account_id":1,"balance":{"balance":90,"available_balance":80,"currency":"IDR"}}`,
+	}
+	notifications <- &pq.Notification{Channel: db.AccountEventsChannel, Extra: `not json`}
+	notifications <- &pq.Notification{
+		Channel: db.AccountEventsChannel,
+		Extra:   `{"type":"entry","account_id":1,"entry":{"id":7,"account_id":1,"amount":-10,"created_at":"2024-05-01T10:00:00.123456+00:00","transfer_id":3,"description":"","pocket_id":null}}`,
+	}
+
+	event, ok := receiveAccountEvent(t, events)
+	assert.True(t, ok)
+	assert.Equal(t, db.AccountEventBalance, event.Type)
+	assert.Equal(t, &db.AccountBalance{Balance: 90, AvailableBalance: 80, Currency: "IDR"}, event.Balance)
+
+	event, ok = receiveAccountEvent(t, events)
+	assert.True(t, ok)
+	assert.Equal(t, db.AccountEventEntry, event.Type)
+	assert.Equal(t, int64(7), event.Entry.ID)
+	assert.Equal(t, int64(-10), event.Entry.Amount)
+	assert.Equal(t, int64(3), *event.Entry.TransferID)
+	assert.Nil(t, event.Entry.PocketID)
+
+	assert.Len(t, others, 0)
+
+	cancel()
+	<-done
+
+	_, ok = receiveAccountEvent(t, events)
+	assert.False(t, ok)
+}
+
+func TestAccountEventBrokerClosesSubscriptionsOnReconnect(t *testing.T) {
+	notifications := make(chan *pq.Notification, 1)
+	broker := NewAccountEventBroker(notifications, testLogger)
+
+	events, unsubscribe := broker.Subscribe([]int64{1})
+
+	notifications <- nil
+	close(notifications)
+	broker.Run(context.Background())
+
+	_, ok := receiveAccountEvent(t, events)
+	assert.False(t, ok)
+
+	// unsubscribing after the broker closed the subscription is fine
+	unsubscribe()
+}
+
+func TestAccountEventBrokerDropsSlowSubscribers(t *testing.T) {
+	broker := NewAccountEventBroker(nil, testLogger)
+
+	events, unsubscribe := broker.Subscribe([]int64{1})
+	defer unsubscribe()
+
+	for i := 0; i <= accountEventBufferSize; i++ {
+		broker.publish(db.AccountEvent{Type: db.AccountEventBalance, AccountID: 1})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, accountEventBufferSize, received)
+}
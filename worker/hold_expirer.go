@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+const holdExpiryBatchSize = 100
+
+// HoldExpirer periodically releases pending holds which are past their expiry time.
+type HoldExpirer struct {
+	store    db.Store
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewHoldExpirer(store db.Store, interval time.Duration, logger *slog.Logger) *HoldExpirer {
+	return &HoldExpirer{store: store, interval: interval, logger: logger}
+}
+
+// Run blocks until ctx is done.
+func (expirer *HoldExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(expirer.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expirer.expire(ctx)
+		}
+	}
+}
+
+func (expirer *HoldExpirer) expire(ctx context.Context) {
+	for {
+		holds, err := expirer.store.ExpireHolds(ctx, holdExpiryBatchSize)
+		if err != nil {
+			expirer.logger.ErrorContext(ctx, "Cannot expire holds", slog.Any("error", err))
+			return
+		}
+		if len(holds) < holdExpiryBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHoldExpirerDrainsFullBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ExpireHolds(gomock.Any(), gomock.Eq(int32(holdExpiryBatchSize))).
+			Times(1).
+			Return(make([]db.Hold, holdExpiryBatchSize), nil),
+		store.EXPECT().
+			ExpireHolds(gomock.Any(), gomock.Eq(int32(holdExpiryBatchSize))).
+			Times(1).
+			Return(make([]db.Hold, 3), nil),
+	)
+
+	expirer := NewHoldExpirer(store, 0, testLogger)
+	expirer.expire(context.Background())
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+// InterestAccruer accrues yesterday's interest and posts everything accrued
+// before the current month. Both steps are idempotent, so it simply runs them on every tick.
+type InterestAccruer struct {
+	store    db.Store
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+func NewInterestAccruer(store db.Store, interval time.Duration, logger *slog.Logger) *InterestAccruer {
+	return &InterestAccruer{store: store, interval: interval, logger: logger, now: time.Now}
+}
+
+// Run blocks until ctx is done.
+func (accruer *InterestAccruer) Run(ctx context.Context) {
+	ticker := time.NewTicker(accruer.interval)
+	defer ticker.Stop()
+
+	for {
+		accruer.accrueAndPost(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (accruer *InterestAccruer) accrueAndPost(ctx context.Context) {
+	now := accruer.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	yesterday := today.AddDate(0, 0, -1)
+	_, err := accruer.store.AccrueInterest(ctx, yesterday)
+	if err != nil {
+		accruer.logger.ErrorContext(ctx, "Cannot accrue interest",
+			slog.String("day", yesterday.Format(time.DateOnly)), slog.Any("error", err))
+		return
+	}
+
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	_, err = accruer.store.PostInterest(ctx, monthStart)
+	if err != nil {
+		accruer.logger.ErrorContext(ctx, "Cannot post interest",
+			slog.String("before", monthStart.Format(time.DateOnly)), slog.Any("error", err))
+	}
+}
+
+// Backfill accrues every day from one to another inclusive, oldest first.
+func Backfill(ctx context.Context, store db.Store, from time.Time, to time.Time, logger *slog.Logger) error {
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		accruals, err := store.AccrueInterest(ctx, day)
+		if err != nil {
+			return err
+		}
+		logger.InfoContext(ctx, "Accrued interest",
+			slog.String("day", day.Format(time.DateOnly)), slog.Int("accounts", len(accruals)))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInterestAccruerAccruesYesterdayAndPostsLastMonth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			AccrueInterest(gomock.Any(), gomock.Eq(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))).
+			Times(1),
+		store.EXPECT().
+			PostInterest(gomock.Any(), gomock.Eq(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))).
+			Times(1),
+	)
+
+	accruer := NewInterestAccruer(store, time.Hour, testLogger)
+	accruer.now = func() time.Time { return time.Date(2024, 3, 2, 8, 30, 0, 0, time.UTC) }
+	accruer.accrueAndPost(context.Background())
+}
+
+func TestBackfill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	from := time.Date(2024, 2, 27, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var days []time.Time
+	store.EXPECT().
+		AccrueInterest(gomock.Any(), gomock.Any()).
+		Times(4).
+		DoAndReturn(func(_ context.Context, day time.Time) ([]db.InterestAccrual, error) {
+			days = append(days, day)
+			return nil, nil
+		})
+
+	err := Backfill(context.Background(), store, from, to, testLogger)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		from,
+		time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		to,
+	}, days)
+}
@@ -0,0 +1,8 @@
+package worker
+
+import (
+	"io"
+	"log/slog"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+const outboxRelayBatchSize = 100
+
+// OutboxRelay periodically publishes the events of the outbox to a sink. Delivery is at least once,
+// an event is published again when the relay stops between publishing it and marking it.
+type OutboxRelay struct {
+	store    db.Store
+	sink     Sink
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewOutboxRelay(store db.Store, sink Sink, interval time.Duration, logger *slog.Logger) *OutboxRelay {
+	return &OutboxRelay{store: store, sink: sink, interval: interval, logger: logger}
+}
+
+// Run blocks until ctx is done.
+func (relay *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			relay.relay(ctx)
+		}
+	}
+}
+
+func (relay *OutboxRelay) relay(ctx context.Context) {
+	for {
+		handled, err := relay.store.RelayOutbox(ctx, outboxRelayBatchSize, func(event db.OutboxEvent) error {
+			err := relay.sink.Publish(ctx, newEvent(event))
+			if err != nil {
+				relay.logger.ErrorContext(ctx, "Cannot publish outbox event",
+					slog.Int64("event_id", event.ID), slog.String("event_type", event.EventType), slog.Any("error", err))
+			}
+			return err
+		})
+		if err != nil {
+			relay.logger.ErrorContext(ctx, "Cannot relay outbox events", slog.Any("error", err))
+			return
+		}
+		if handled < outboxRelayBatchSize {
+			return
+		}
+	}
+}
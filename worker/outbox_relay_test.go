@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type recordingSink struct {
+	events []Event
+	err    error
+}
+
+func (sink *recordingSink) Publish(_ context.Context, event Event) error {
+	sink.events = append(sink.events, event)
+	return sink.err
+}
+
+// relayEvents stubs RelayOutbox to hand the events to the publish func of the relay
+func relayEvents(events []db.OutboxEvent, errs *[]error) func(context.Context, int32, func(db.OutboxEvent) error) (int, error) {
+	return func(_ context.Context, _ int32, publish func(db.OutboxEvent) error) (int, error) {
+		for _, event := range events {
+			*errs = append(*errs, publish(event))
+		}
+		return len(events), nil
+	}
+}
+
+func TestOutboxRelayPublishesEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	sink := &recordingSink{}
+
+	event := db.OutboxEvent{
+		ID:            7,
+		EventType:     db.EventAccountCreated,
+		SchemaVersion: db.EventSchemaVersion,
+		AggregateID:   "42",
+		Payload:       json.RawMessage(`{"id":42}`),
+	}
+
+	var errs []error
+	store.EXPECT().
+		RelayOutbox(gomock.Any(), gomock.Eq(int32(outboxRelayBatchSize)), gomock.Any()).
+		Times(1).
+		DoAndReturn(relayEvents([]db.OutboxEvent{event}, &errs))
+
+	relay := NewOutboxRelay(store, sink, 0, testLogger)
+	relay.relay(context.Background())
+
+	assert.Equal(t, []error{nil}, errs)
+	assert.Len(t, sink.events, 1)
+	assert.Equal(t, event.ID, sink.events[0].ID)
+	assert.Equal(t, db.EventAccountCreated, sink.events[0].Type)
+	assert.Equal(t, int32(db.EventSchemaVersion), sink.events[0].SchemaVersion)
+	assert.Equal(t, "42", sink.events[0].AggregateID)
+}
+
+func TestOutboxRelayReportsSinkErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	sink := &recordingSink{err: errors.New("sink is down")}
+
+	var errs []error
+	store.EXPECT().
+		RelayOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(relayEvents([]db.OutboxEvent{{ID: 1}, {ID: 2}}, &errs))
+
+	relay := NewOutboxRelay(store, sink, 0, testLogger)
+	relay.relay(context.Background())
+
+	// every event is still handed to the sink, the store retries the failed ones
+	assert.Len(t, sink.events, 2)
+	assert.Equal(t, []error{sink.err, sink.err}, errs)
+}
+
+func TestOutboxRelayDrainsFullBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			RelayOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(outboxRelayBatchSize, nil),
+		store.EXPECT().
+			RelayOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(0, nil),
+	)
+
+	relay := NewOutboxRelay(store, &recordingSink{}, 0, testLogger)
+	relay.relay(context.Background())
+}
+
+func TestOutboxRelayStopsOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		RelayOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(0, sql.ErrConnDone)
+
+	relay := NewOutboxRelay(store, &recordingSink{}, 0, testLogger)
+	relay.relay(context.Background())
+}
@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+// Event is what the sinks publish for an outbox event. ID is the same every time
+// the event is published, consumers use it to drop duplicates.
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	SchemaVersion int32           `json:"schema_version"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func newEvent(event db.OutboxEvent) Event {
+	return Event{
+		ID:            event.ID,
+		Type:          event.EventType,
+		SchemaVersion: event.SchemaVersion,
+		AggregateID:   event.AggregateID,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// Sink publishes events somewhere outside of the service,
+// an error leaves the event in the outbox to be retried.
+type Sink interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// WriterSink writes every event as a line of JSON.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+func (sink *WriterSink) Publish(_ context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	_, err = sink.w.Write(append(data, '\n'))
+	return err
+}
+
+// HTTPSink posts every event as JSON to a URL, any response other than 2xx is a failure.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string, client *http.Client) *HTTPSink {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPSink{url: url, client: client}
+}
+
+func (sink *HTTPSink) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	request.Header.Set("X-Event-Type", event.Type)
+
+	response, err := sink.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("sink responded with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// NATSPublisher is the part of a NATS connection the sink needs,
+// *nats.Conn satisfies it as well as any compatible client.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSSink publishes every event on the subject made of the prefix and the event type,
+// e.g. "simplebank.TransferCreated".
+type NATSSink struct {
+	conn   NATSPublisher
+	prefix string
+}
+
+func NewNATSSink(conn NATSPublisher, prefix string) *NATSSink {
+	return &NATSSink{conn: conn, prefix: prefix}
+}
+
+func (sink *NATSSink) Publish(_ context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return sink.conn.Publish(sink.prefix+"."+event.Type, data)
+}
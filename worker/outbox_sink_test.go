@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEvent() Event {
+	return Event{
+		ID:            3,
+		Type:          db.EventTransferCreated,
+		SchemaVersion: db.EventSchemaVersion,
+		AggregateID:   "9",
+		Payload:       json.RawMessage(`{"id":9}`),
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+
+	err := sink.Publish(context.Background(), testEvent())
+	assert.NoError(t, err)
+	err = sink.Publish(context.Background(), testEvent())
+	assert.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+
+	var event Event
+	err = json.Unmarshal(lines[0], &event)
+	assert.NoError(t, err)
+	assert.Equal(t, testEvent().ID, event.ID)
+	assert.JSONEq(t, `{"id":9}`, string(event.Payload))
+}
+
+func TestHTTPSink(t *testing.T) {
+	status := http.StatusOK
+	var received Event
+	var header http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, server.Client())
+
+	err := sink.Publish(context.Background(), testEvent())
+	assert.NoError(t, err)
+	assert.Equal(t, testEvent().ID, received.ID)
+	assert.Equal(t, "3", header.Get("X-Event-ID"))
+	assert.Equal(t, db.EventTransferCreated, header.Get("X-Event-Type"))
+
+	status = http.StatusServiceUnavailable
+	err = sink.Publish(context.Background(), testEvent())
+	assert.Error(t, err)
+}
+
+type fakeNATSConn struct {
+	subject string
+	data    []byte
+}
+
+func (conn *fakeNATSConn) Publish(subject string, data []byte) error {
+	conn.subject = subject
+	conn.data = data
+	return nil
+}
+
+func TestNATSSink(t *testing.T) {
+	conn := &fakeNATSConn{}
+	sink := NewNATSSink(conn, "simplebank")
+
+	err := sink.Publish(context.Background(), testEvent())
+	assert.NoError(t, err)
+	assert.Equal(t, "simplebank.TransferCreated", conn.subject)
+
+	var event Event
+	err = json.Unmarshal(conn.data, &event)
+	assert.NoError(t, err)
+	assert.Equal(t, testEvent().ID, event.ID)
+}
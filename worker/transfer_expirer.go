@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+)
+
+const transferExpiryBatchSize = 100
+
+// TransferExpirer periodically expires pending transfers which were not approved in time.
+type TransferExpirer struct {
+	store    db.Store
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewTransferExpirer(store db.Store, interval time.Duration, logger *slog.Logger) *TransferExpirer {
+	return &TransferExpirer{store: store, interval: interval, logger: logger}
+}
+
+// Run blocks until ctx is done.
+func (expirer *TransferExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(expirer.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expirer.expire(ctx)
+		}
+	}
+}
+
+func (expirer *TransferExpirer) expire(ctx context.Context) {
+	for {
+		transfers, err := expirer.store.ExpirePendingTransfers(ctx, transferExpiryBatchSize)
+		if err != nil {
+			expirer.logger.ErrorContext(ctx, "Cannot expire pending transfers", slog.Any("error", err))
+			return
+		}
+		if len(transfers) < transferExpiryBatchSize {
+			return
+		}
+	}
+}
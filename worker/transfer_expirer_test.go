@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTransferExpirerDrainsFullBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ExpirePendingTransfers(gomock.Any(), gomock.Eq(int32(transferExpiryBatchSize))).
+			Times(1).
+			Return(make([]db.Transfer, transferExpiryBatchSize), nil),
+		store.EXPECT().
+			ExpirePendingTransfers(gomock.Any(), gomock.Eq(int32(transferExpiryBatchSize))).
+			Times(1).
+			Return([]db.Transfer{}, nil),
+	)
+
+	expirer := NewTransferExpirer(store, 0, testLogger)
+	expirer.expire(context.Background())
+}
+
+func TestTransferExpirerStopsOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ExpirePendingTransfers(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+
+	expirer := NewTransferExpirer(store, 0, testLogger)
+	expirer.expire(context.Background())
+}
@@ -0,0 +1,136 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+)
+
+const webhookDeliveryBatchSize = 50
+
+// WebhookDispatcher periodically posts the due webhook deliveries to their endpoints,
+// signed with the secret of the endpoint, see util.SignWebhook.
+type WebhookDispatcher struct {
+	store    db.Store
+	client   *http.Client
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// NewWebhookDispatcher posts with client, without one it only connects to public addresses, see newWebhookClient
+func NewWebhookDispatcher(store db.Store, client *http.Client, interval time.Duration, logger *slog.Logger) *WebhookDispatcher {
+	if client == nil {
+		client = newWebhookClient(util.IsPublicIP)
+	}
+	return &WebhookDispatcher{store: store, client: client, interval: interval, logger: logger}
+}
+
+// errNonPublicAddress is the error of a connection to an address allow rejects
+var errNonPublicAddress = errors.New("webhook endpoint resolves to a non public address")
+
+// newWebhookClient connects only to the addresses allowed, they are checked after the name of
+// the endpoint is resolved so a name pointing into the internal network is refused too.
+// Redirects aren't followed, a 3xx response is a failed delivery.
+func newWebhookClient(allow func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allow(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errNonPublicAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			// a proxy would be dialed instead of the endpoint
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Run blocks until ctx is done.
+func (dispatcher *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(dispatcher.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			dispatcher.dispatch(ctx)
+		}
+	}
+}
+
+func (dispatcher *WebhookDispatcher) dispatch(ctx context.Context) {
+	for {
+		handled, err := dispatcher.store.DeliverWebhooks(ctx, webhookDeliveryBatchSize, func(delivery db.ClaimDueWebhookDeliveriesRow) db.WebhookAttemptResult {
+			return dispatcher.deliver(ctx, delivery)
+		})
+		if err != nil {
+			dispatcher.logger.ErrorContext(ctx, "Cannot deliver webhooks", slog.Any("error", err))
+			return
+		}
+		if handled < webhookDeliveryBatchSize {
+			return
+		}
+	}
+}
+
+func (dispatcher *WebhookDispatcher) deliver(ctx context.Context, delivery db.ClaimDueWebhookDeliveriesRow) db.WebhookAttemptResult {
+	start := time.Now()
+	failed := func(err error) db.WebhookAttemptResult {
+		return db.WebhookAttemptResult{Error: err.Error(), Duration: time.Since(start)}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return failed(err)
+	}
+
+	timestamp := start.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Webhook-ID", strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set("X-Webhook-Event", delivery.EventType)
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", util.SignWebhook(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return failed(err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	return db.WebhookAttemptResult{
+		StatusCode: int32(response.StatusCode),
+		Duration:   time.Since(start),
+	}
+}
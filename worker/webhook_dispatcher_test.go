@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+
+	mockdb "github.com/novalyezu/simplebank-backend/db/mock"
+	db "github.com/novalyezu/simplebank-backend/db/sqlc"
+	"github.com/novalyezu/simplebank-backend/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWebhookDispatcherSignsDeliveries(t *testing.T) {
+	secret, err := util.NewWebhookSecret()
+	assert.NoError(t, err)
+
+	payload := json.RawMessage(`{"type":"transfer.incoming","account_id":2}`)
+	status := http.StatusNoContent
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(payload), string(body))
+
+		timestamp, err := strconv.ParseInt(r.Header.Get("X-Webhook-Timestamp"), 10, 64)
+		assert.NoError(t, err)
+		assert.True(t, util.VerifyWebhook(secret, timestamp, body, r.Header.Get("X-Webhook-Signature")))
+		assert.Equal(t, "5", r.Header.Get("X-Webhook-ID"))
+		assert.Equal(t, util.WebhookEventTransferIncoming, r.Header.Get("X-Webhook-Event"))
+
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	delivery := db.ClaimDueWebhookDeliveriesRow{
+		ID:        5,
+		EventType: util.WebhookEventTransferIncoming,
+		Payload:   payload,
+		Url:       receiver.URL,
+		Secret:    secret,
+	}
+
+	dispatcher := NewWebhookDispatcher(nil, receiver.Client(), 0, testLogger)
+
+	result := dispatcher.deliver(context.Background(), delivery)
+	assert.True(t, result.Succeeded())
+	assert.Equal(t, int32(http.StatusNoContent), result.StatusCode)
+
+	status = http.StatusInternalServerError
+	result = dispatcher.deliver(context.Background(), delivery)
+	assert.False(t, result.Succeeded())
+	assert.Equal(t, int32(http.StatusInternalServerError), result.StatusCode)
+}
+
+func TestWebhookDispatcherUnreachableEndpoint(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close()
+
+	dispatcher := NewWebhookDispatcher(nil, nil, 0, testLogger)
+	result := dispatcher.deliver(context.Background(), db.ClaimDueWebhookDeliveriesRow{
+		ID:      1,
+		Payload: json.RawMessage(`{}`),
+		Url:     receiver.URL,
+	})
+
+	assert.False(t, result.Succeeded())
+	assert.Zero(t, result.StatusCode)
+	assert.NotEmpty(t, result.Error)
+}
+
+func TestWebhookDispatcherRefusesInternalAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the webhook reached a loopback address")
+	}))
+	defer receiver.Close()
+
+	dispatcher := NewWebhookDispatcher(nil, nil, 0, testLogger)
+	result := dispatcher.deliver(context.Background(), db.ClaimDueWebhookDeliveriesRow{
+		ID:      1,
+		Payload: json.RawMessage(`{}`),
+		Url:     receiver.URL,
+	})
+
+	assert.False(t, result.Succeeded())
+	assert.Zero(t, result.StatusCode)
+	assert.Contains(t, result.Error, errNonPublicAddress.Error())
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the webhook followed a redirect")
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer receiver.Close()
+
+	allowAll := func(netip.Addr) bool { return true }
+	dispatcher := NewWebhookDispatcher(nil, newWebhookClient(allowAll), 0, testLogger)
+	result := dispatcher.deliver(context.Background(), db.ClaimDueWebhookDeliveriesRow{
+		ID:      1,
+		Payload: json.RawMessage(`{}`),
+		Url:     receiver.URL,
+	})
+
+	assert.False(t, result.Succeeded())
+	assert.Equal(t, int32(http.StatusFound), result.StatusCode)
+}
+
+func TestWebhookDispatcherDrainsFullBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().
+			DeliverWebhooks(gomock.Any(), gomock.Eq(int32(webhookDeliveryBatchSize)), gomock.Any()).
+			Times(1).
+			Return(webhookDeliveryBatchSize, nil),
+		store.EXPECT().
+			DeliverWebhooks(gomock.Any(), gomock.Eq(int32(webhookDeliveryBatchSize)), gomock.Any()).
+			Times(1).
+			Return(0, nil),
+	)
+
+	dispatcher := NewWebhookDispatcher(store, nil, 0, testLogger)
+	dispatcher.dispatch(context.Background())
+}
+
+func TestWebhookDispatcherStopsOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		DeliverWebhooks(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(0, sql.ErrConnDone)
+
+	dispatcher := NewWebhookDispatcher(store, nil, 0, testLogger)
+	dispatcher.dispatch(context.Background())
+}